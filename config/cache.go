@@ -0,0 +1,45 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"main.go/repository"
+)
+
+// defaultCatalogCacheTTL - Lama data katalog disimpan di cache jika CATALOG_CACHE_TTL tidak diatur
+const defaultCatalogCacheTTL = 5 * time.Minute
+
+var (
+	CacheStore      repository.CacheStore
+	CatalogCacheTTL time.Duration
+)
+
+func InitCache() error {
+	// CATALOG_CACHE_TTL: durasi Go (mis. 30s, 5m), 0 mematikan cache
+	CatalogCacheTTL = defaultCatalogCacheTTL
+	if value := os.Getenv("CATALOG_CACHE_TTL"); value != "" {
+		ttl, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("CATALOG_CACHE_TTL tidak valid: %w", err)
+		}
+		CatalogCacheTTL = ttl
+	}
+
+	// CACHE_DRIVER: memory (default). Store bersama (mis. Redis) cukup mengimplementasikan repository.CacheStore
+	driver := os.Getenv("CACHE_DRIVER")
+	if driver == "" {
+		driver = "memory"
+	}
+	switch driver {
+	case "memory":
+		CacheStore = repository.NewMemoryCacheStore(10 * time.Minute)
+	default:
+		return fmt.Errorf("CACHE_DRIVER tidak dikenal: %s", driver)
+	}
+
+	log.Printf("Cache katalog %s digunakan dengan TTL %s", driver, CatalogCacheTTL)
+	return nil
+}
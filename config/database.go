@@ -28,7 +28,8 @@ func InitDB() error {
 
 	// Menginisialisasi koneksi dengan GORM
 	var err error
-	DB, err = gorm.Open(mysql.Open(databaseURI), &gorm.Config{})
+	// TranslateError memetakan error MySQL (mis. duplicate key) ke error GORM seperti gorm.ErrDuplicatedKey
+	DB, err = gorm.Open(mysql.Open(databaseURI), &gorm.Config{TranslateError: true})
 	if err != nil {
 		return fmt.Errorf("gagal menghubungkan ke database: %w", err)
 	}
@@ -45,6 +46,29 @@ func InitDB() error {
 		&entity.ActivityLog{},
 		&entity.ReportLog{},
 		&entity.RefreshToken{},
+		&entity.VoucherBatch{},
+		&entity.VoucherCode{},
+		&entity.ProductCost{},
+		&entity.MarkupRule{},
+		&entity.PriceGroup{},
+		&entity.PriceGroupPrice{},
+		&entity.PriceGroupRule{},
+		&entity.Promotion{},
+		&entity.PromotionUsage{},
+		&entity.LoyaltyRule{},
+		&entity.LoyaltyLedger{},
+		&entity.CommissionLevel{},
+		&entity.Commission{},
+		&entity.APIKey{},
+		&entity.CommandTemplate{},
+		&entity.ReplyTemplate{},
+		&entity.Cart{},
+		&entity.CartItem{},
+		&entity.Favorite{},
+		&entity.MaintenanceWindow{},
+		&entity.ProductPriceHistory{},
+		&entity.PriceSchedule{},
+		&entity.InventoryMovement{},
 	)
 	if err != nil {
 		return fmt.Errorf("gagal melakukan migrasi: %w", err)
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"main.go/repository"
+)
+
+var Storage repository.StorageRepository
+
+// Prefix URL yang dilayani aplikasi untuk storage lokal
+const (
+	LocalPublicURLPrefix = "/uploads"
+	LocalSignedURLPrefix = "/files"
+)
+
+func InitStorage() error {
+	// STORAGE_DRIVER: local (default) atau s3
+	driver := os.Getenv("STORAGE_DRIVER")
+	switch driver {
+	case "", "local":
+		dir := os.Getenv("STORAGE_LOCAL_DIR")
+		if dir == "" {
+			dir = "uploads"
+		}
+		// Kunci tanda tangan URL harus sama di semua instance
+		signingKey := os.Getenv("STORAGE_SIGNING_KEY")
+		if signingKey == "" {
+			signingKey = os.Getenv("JWT_SECRET")
+		}
+		if signingKey == "" {
+			return fmt.Errorf("STORAGE_SIGNING_KEY atau JWT_SECRET wajib diisi untuk storage lokal")
+		}
+		Storage = repository.NewLocalStorage(dir, LocalPublicURLPrefix, LocalSignedURLPrefix, []byte(signingKey))
+		log.Printf("Storage lokal digunakan di direktori %s", dir)
+	case "s3":
+		useSSL, _ := strconv.ParseBool(os.Getenv("S3_USE_SSL"))
+		pathStyle, _ := strconv.ParseBool(os.Getenv("S3_PATH_STYLE"))
+		s3Config := repository.S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			Region:    os.Getenv("S3_REGION"),
+			UseSSL:    useSSL,
+			PathStyle: pathStyle,
+			PublicURL: os.Getenv("S3_PUBLIC_URL"),
+		}
+		if s3Config.Endpoint == "" || s3Config.Bucket == "" {
+			return fmt.Errorf("S3_ENDPOINT dan S3_BUCKET wajib diisi untuk storage s3")
+		}
+		storage, err := repository.NewS3Storage(s3Config)
+		if err != nil {
+			return fmt.Errorf("gagal menghubungkan ke object storage: %w", err)
+		}
+		Storage = storage
+		log.Printf("Storage S3 digunakan di bucket %s (%s)", s3Config.Bucket, s3Config.Endpoint)
+	default:
+		return fmt.Errorf("STORAGE_DRIVER tidak dikenal: %s", driver)
+	}
+	return nil
+}
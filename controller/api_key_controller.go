@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"main.go/entity"
+	"main.go/middleware"
+	"main.go/service"
+)
+
+type APIKeyController struct {
+	service service.APIKeyService
+}
+
+func NewAPIKeyController(service service.APIKeyService) *APIKeyController {
+	return &APIKeyController{service: service}
+}
+
+// GetAPIKeys - Daftar API key milik user yang login (tanpa secret)
+func (ac *APIKeyController) GetAPIKeys(c *gin.Context) {
+	keys, err := ac.service.ListKeys(c.GetUint("user_id"))
+	if err != nil {
+		middleware.Logger.Error("Failed to fetch API keys", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch API keys"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "API keys fetched successfully", "data": keys})
+}
+
+// CreateAPIKey - Membuat API key H2H; secret hanya ditampilkan sekali
+func (ac *APIKeyController) CreateAPIKey(c *gin.Context) {
+	var request entity.APIKeyRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	key, err := ac.service.CreateKey(c.GetUint("user_id"), request)
+	if err != nil {
+		middleware.Logger.Error("Failed to create API key", zap.Error(err))
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "API key created successfully, store the secret now as it will not be shown again", "data": key})
+}
+
+// RevokeAPIKey - Mencabut API key
+func (ac *APIKeyController) RevokeAPIKey(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid API key ID"})
+		return
+	}
+
+	if err := ac.service.RevokeKey(c.GetUint("user_id"), uint(id)); err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked successfully"})
+}
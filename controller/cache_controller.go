@@ -0,0 +1,27 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"main.go/service"
+)
+
+type CacheController struct {
+	service service.CacheService
+}
+
+func NewCacheController(service service.CacheService) *CacheController {
+	return &CacheController{service: service}
+}
+
+// GetCacheStats - Statistik cache katalog (hit, miss, jumlah entri, invalidasi)
+func (cc *CacheController) GetCacheStats(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"message": "Cache stats fetched successfully", "data": cc.service.GetStats()})
+}
+
+// FlushCache - Mengosongkan cache katalog
+func (cc *CacheController) FlushCache(c *gin.Context) {
+	stats := cc.service.Flush(c.GetUint("user_id"))
+	c.JSON(http.StatusOK, gin.H{"message": "Cache flushed successfully", "data": stats})
+}
@@ -34,7 +34,7 @@ func (cc *CallbackController) CallbackTransactionStatus(c *gin.Context) {
 	}
 
 	// Update status transaksi berdasarkan callback
-	if err := cc.service.UpdateTransactionStatus(callbackRequest.TransactionID, callbackRequest.Status); err != nil {
+	if err := cc.service.UpdateTransactionStatus(callbackRequest.TransactionID, callbackRequest.Status, 0); err != nil {
 		middleware.Logger.Error("Failed to update transaction status", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update transaction status"})
 		return
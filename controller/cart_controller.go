@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"main.go/entity"
+	"main.go/middleware"
+	"main.go/service"
+)
+
+type CartController struct {
+	service service.CartService
+}
+
+func NewCartController(service service.CartService) *CartController {
+	return &CartController{service: service}
+}
+
+// GetCart - Isi keranjang dengan harga dan stok terkini
+func (cc *CartController) GetCart(c *gin.Context) {
+	cart, err := cc.service.GetCart(c.GetUint("user_id"))
+	if err != nil {
+		middleware.Logger.Error("Failed to fetch cart", zap.Error(err))
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Cart fetched successfully", "data": cart})
+}
+
+// AddCartItem - Menambahkan produk ke keranjang
+func (cc *CartController) AddCartItem(c *gin.Context) {
+	var request entity.CartItemRequest
+	if err := c.ShouldBindJSON(&request); err != nil || request.ProductID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "product_id and quantity are required"})
+		return
+	}
+
+	cart, err := cc.service.AddItem(c.GetUint("user_id"), request)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Item added to cart", "data": cart})
+}
+
+// UpdateCartItem - Mengubah jumlah produk di keranjang
+func (cc *CartController) UpdateCartItem(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("product_id"))
+	if err != nil || productID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	var request struct {
+		Quantity *int `json:"quantity" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "quantity is required"})
+		return
+	}
+
+	cart, err := cc.service.UpdateItem(c.GetUint("user_id"), entity.CartItemRequest{ProductID: uint(productID), Quantity: *request.Quantity})
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Cart updated successfully", "data": cart})
+}
+
+// RemoveCartItem - Menghapus produk dari keranjang
+func (cc *CartController) RemoveCartItem(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("product_id"))
+	if err != nil || productID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	cart, err := cc.service.RemoveItem(c.GetUint("user_id"), uint(productID))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Item removed from cart", "data": cart})
+}
+
+// ClearCart - Mengosongkan keranjang
+func (cc *CartController) ClearCart(c *gin.Context) {
+	if err := cc.service.Clear(c.GetUint("user_id")); err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Cart cleared successfully"})
+}
+
+// CheckoutCart - Mengubah isi keranjang menjadi transaksi
+func (cc *CartController) CheckoutCart(c *gin.Context) {
+	var request entity.CartCheckoutRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	transaction, err := cc.service.Checkout(c.GetUint("user_id"), request)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	response := service.ConvertToTransactionResponse(transaction)
+	c.JSON(http.StatusOK, gin.H{"message": "Transaction created successfully", "data": response})
+}
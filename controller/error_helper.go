@@ -0,0 +1,16 @@
+package controller
+
+import (
+	"errors"
+
+	"main.go/middleware"
+)
+
+// errorStatus - Mengambil kode HTTP dari AppError, atau fallback jika error biasa
+func errorStatus(err error, fallback int) int {
+	var appErr *middleware.AppError
+	if errors.As(err, &appErr) {
+		return appErr.Code
+	}
+	return fallback
+}
@@ -0,0 +1,30 @@
+package controller
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"main.go/middleware"
+)
+
+// setETag - ETag berisi version resource, dipakai klien sebagai If-Match saat update/hapus
+func setETag(c *gin.Context, version uint) {
+	c.Header("ETag", `"`+strconv.FormatUint(uint64(version), 10)+`"`)
+}
+
+// ifMatchVersion - Version dari header If-Match. Header kosong atau "*" berarti tanpa pengecekan (0),
+// nilai yang bukan ETag version yang valid ditolak dengan 412.
+func ifMatchVersion(c *gin.Context) (uint, error) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+
+	value := strings.TrimSuffix(strings.TrimPrefix(header, `"`), `"`)
+	version, err := strconv.ParseUint(value, 10, 32)
+	if err != nil || version == 0 || len(value)+2 != len(header) {
+		return 0, middleware.NewAppError(412, "If-Match must be the ETag returned by GET", err)
+	}
+	return uint(version), nil
+}
@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"main.go/entity"
+	"main.go/middleware"
+	"main.go/service"
+)
+
+type FavoriteController struct {
+	service service.FavoriteService
+}
+
+func NewFavoriteController(service service.FavoriteService) *FavoriteController {
+	return &FavoriteController{service: service}
+}
+
+// GetFavorites - Daftar nomor favorit milik user
+func (fc *FavoriteController) GetFavorites(c *gin.Context) {
+	favorites, err := fc.service.GetFavorites(c.GetUint("user_id"))
+	if err != nil {
+		middleware.Logger.Error("Failed to fetch favorites", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch favorites"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Favorites fetched successfully", "data": favorites})
+}
+
+// GetFavoriteSuggestions - Saran nomor favorit dari riwayat transaksi
+func (fc *FavoriteController) GetFavoriteSuggestions(c *gin.Context) {
+	suggestions, err := fc.service.GetSuggestions(c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Favorite suggestions fetched successfully", "data": suggestions})
+}
+
+// CreateFavorite - Menyimpan nomor favorit
+func (fc *FavoriteController) CreateFavorite(c *gin.Context) {
+	var request entity.FavoriteRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "label and destination_number are required"})
+		return
+	}
+
+	favorite, err := fc.service.CreateFavorite(c.GetUint("user_id"), request)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Favorite saved successfully", "data": favorite})
+}
+
+// UpdateFavorite - Mengubah label atau nomor favorit
+func (fc *FavoriteController) UpdateFavorite(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid favorite ID"})
+		return
+	}
+
+	var request entity.FavoriteRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "label and destination_number are required"})
+		return
+	}
+
+	favorite, err := fc.service.UpdateFavorite(c.GetUint("user_id"), uint(id), request)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Favorite updated successfully", "data": favorite})
+}
+
+// DeleteFavorite - Menghapus nomor favorit
+func (fc *FavoriteController) DeleteFavorite(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid favorite ID"})
+		return
+	}
+
+	if err := fc.service.DeleteFavorite(c.GetUint("user_id"), uint(id)); err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Favorite deleted successfully"})
+}
@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"main.go/middleware"
+	"main.go/repository"
+)
+
+// publicKeyPrefixes - Prefix key storage yang boleh diakses tanpa tanda tangan
+var publicKeyPrefixes = []string{"products/"}
+
+// FileController - Melayani objek storage lokal (file publik dan URL bertandatangan)
+type FileController struct {
+	storage repository.StorageRepository
+}
+
+func NewFileController(storage repository.StorageRepository) *FileController {
+	return &FileController{storage: storage}
+}
+
+// ServePublic - Melayani objek publik seperti gambar produk
+func (fc *FileController) ServePublic(c *gin.Context) {
+	// Prefix dicek pada key yang sudah dibersihkan, key dengan segmen ".." ditolak
+	key, err := repository.CleanObjectKey(c.Param("key"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		return
+	}
+	public := false
+	for _, prefix := range publicKeyPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			public = true
+			break
+		}
+	}
+	if !public {
+		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		return
+	}
+	fc.serve(c, key, false)
+}
+
+// ServeSigned - Melayani objek lewat URL dari SignedURL (mis. unduhan laporan)
+func (fc *FileController) ServeSigned(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	verifier, ok := fc.storage.(repository.SignedURLVerifier)
+	if !ok || !verifier.VerifySignedURL(key, c.Query("expires"), c.Query("signature")) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Invalid or expired link"})
+		return
+	}
+	fc.serve(c, key, true)
+}
+
+func (fc *FileController) serve(c *gin.Context, key string, attachment bool) {
+	file, err := fc.storage.Get(key)
+	if err != nil {
+		if errors.Is(err, repository.ErrObjectNotFound) || errors.Is(err, repository.ErrInvalidObjectKey) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+			return
+		}
+		middleware.Logger.Error("Failed to read file from storage", zap.String("key", key), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read file"})
+		return
+	}
+	defer file.Close()
+
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	headers := map[string]string{}
+	if attachment {
+		headers["Content-Disposition"] = fmt.Sprintf(`attachment; filename="%s"`, path.Base(key))
+	}
+
+	var size int64 = -1
+	if seeker, ok := file.(io.Seeker); ok {
+		if end, err := seeker.Seek(0, io.SeekEnd); err == nil {
+			if _, err := seeker.Seek(0, io.SeekStart); err == nil {
+				size = end
+			}
+		}
+	}
+	c.DataFromReader(http.StatusOK, size, contentType, file, headers)
+}
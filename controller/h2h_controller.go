@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"main.go/entity"
+	"main.go/middleware"
+	"main.go/service"
+)
+
+type H2HController struct {
+	service service.H2HService
+}
+
+func NewH2HController(service service.H2HService) *H2HController {
+	return &H2HController{service: service}
+}
+
+// Purchase - POST /h2h/purchase
+func (hc *H2HController) Purchase(c *gin.Context) {
+	var request entity.H2HPurchaseRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, entity.H2HResponse{RefID: request.RefID, Message: "Invalid input: ref_id and product_id are required"})
+		return
+	}
+
+	response, err := hc.service.Purchase(c.GetUint("user_id"), request)
+	if err != nil {
+		middleware.Logger.Warn("H2H purchase rejected", zap.String("ref_id", request.RefID), zap.Error(err))
+		c.JSON(errorStatus(err, http.StatusBadRequest), entity.H2HResponse{RefID: request.RefID, Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// Status - GET /h2h/status/:ref_id
+func (hc *H2HController) Status(c *gin.Context) {
+	refID := c.Param("ref_id")
+	response, err := hc.service.Status(c.GetUint("user_id"), refID)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), entity.H2HResponse{RefID: refID, Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// Balance - GET /h2h/balance
+func (hc *H2HController) Balance(c *gin.Context) {
+	response, err := hc.service.Balance(c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), entity.H2HBalanceResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
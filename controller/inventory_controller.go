@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"main.go/entity"
+	"main.go/middleware"
+	"main.go/service"
+)
+
+type InventoryController struct {
+	service service.InventoryService
+}
+
+func NewInventoryController(service service.InventoryService) *InventoryController {
+	return &InventoryController{service: service}
+}
+
+// AdjustStock - Admin menambah/mengurangi stok produk dengan alasan yang tercatat di ledger
+func (ic *InventoryController) AdjustStock(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	var request entity.StockAdjustmentRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "quantity (non-zero) and reason are required"})
+		return
+	}
+
+	movement, err := ic.service.AdjustStock(uint(id), c.GetUint("user_id"), request)
+	if err != nil {
+		middleware.Logger.Error("Failed to adjust stock", zap.Error(err))
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Stock adjusted successfully", "data": movement})
+}
+
+// GetMovements - Riwayat mutasi stok terbaru sebuah produk
+func (ic *InventoryController) GetMovements(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	movements, err := ic.service.GetMovements(uint(id))
+	if err != nil {
+		middleware.Logger.Error("Failed to fetch inventory movements", zap.Error(err))
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Inventory movements fetched successfully", "data": movements})
+}
+
+// GetLowStockProducts - Produk yang stoknya sudah mencapai batas minimum
+func (ic *InventoryController) GetLowStockProducts(c *gin.Context) {
+	products, err := ic.service.GetLowStockProducts()
+	if err != nil {
+		middleware.Logger.Error("Failed to fetch low stock products", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch low stock products"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Low stock products fetched successfully", "data": products})
+}
@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"main.go/entity"
+	"main.go/middleware"
+	"main.go/service"
+)
+
+type LoyaltyController struct {
+	service service.LoyaltyService
+}
+
+func NewLoyaltyController(service service.LoyaltyService) *LoyaltyController {
+	return &LoyaltyController{service: service}
+}
+
+// GetLoyalty - Saldo poin, saldo rupiah dan riwayat poin milik user yang login
+func (lc *LoyaltyController) GetLoyalty(c *gin.Context) {
+	summary, err := lc.service.GetSummary(c.GetUint("user_id"))
+	if err != nil {
+		middleware.Logger.Error("Failed to fetch loyalty summary", zap.Error(err))
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Loyalty summary fetched successfully", "data": summary})
+}
+
+// RedeemPoints - Menukar poin menjadi saldo atau kode diskon
+func (lc *LoyaltyController) RedeemPoints(c *gin.Context) {
+	var request entity.LoyaltyRedeemRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	result, err := lc.service.Redeem(c.GetUint("user_id"), request)
+	if err != nil {
+		middleware.Logger.Error("Failed to redeem loyalty points", zap.Error(err))
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Points redeemed successfully", "data": result})
+}
+
+// GetLoyaltyRules - Daftar aturan perolehan poin
+func (lc *LoyaltyController) GetLoyaltyRules(c *gin.Context) {
+	rules, err := lc.service.GetAllRules()
+	if err != nil {
+		middleware.Logger.Error("Failed to fetch loyalty rules", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch loyalty rules"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Loyalty rules fetched successfully", "data": rules})
+}
+
+// CreateLoyaltyRule - Membuat aturan perolehan poin
+func (lc *LoyaltyController) CreateLoyaltyRule(c *gin.Context) {
+	rule := entity.LoyaltyRule{IsActive: true}
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	rule.ID = 0
+	if err := lc.service.CreateRule(&rule); err != nil {
+		middleware.Logger.Error("Failed to create loyalty rule", zap.Error(err))
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Loyalty rule created successfully", "data": rule})
+}
+
+// UpdateLoyaltyRule - Mengubah aturan perolehan poin
+func (lc *LoyaltyController) UpdateLoyaltyRule(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid loyalty rule ID"})
+		return
+	}
+
+	rule := entity.LoyaltyRule{IsActive: true}
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	rule.ID = uint(id)
+	if err := lc.service.UpdateRule(&rule); err != nil {
+		middleware.Logger.Error("Failed to update loyalty rule", zap.Error(err))
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Loyalty rule updated successfully"})
+}
+
+// DeleteLoyaltyRule - Menghapus aturan perolehan poin
+func (lc *LoyaltyController) DeleteLoyaltyRule(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid loyalty rule ID"})
+		return
+	}
+
+	if err := lc.service.DeleteRule(uint(id)); err != nil {
+		middleware.Logger.Error("Failed to delete loyalty rule", zap.Error(err))
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Loyalty rule deleted successfully"})
+}
@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"main.go/entity"
+	"main.go/middleware"
+	"main.go/service"
+)
+
+type MaintenanceController struct {
+	service service.MaintenanceService
+}
+
+func NewMaintenanceController(service service.MaintenanceService) *MaintenanceController {
+	return &MaintenanceController{service: service}
+}
+
+// GetMaintenanceWindows - Daftar semua jadwal maintenance
+func (mc *MaintenanceController) GetMaintenanceWindows(c *gin.Context) {
+	windows, err := mc.service.GetAllWindows()
+	if err != nil {
+		middleware.Logger.Error("Failed to fetch maintenance windows", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch maintenance windows"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Maintenance windows fetched successfully", "data": windows})
+}
+
+// GetMaintenanceWindowByID - Detail jadwal maintenance
+func (mc *MaintenanceController) GetMaintenanceWindowByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid maintenance window ID"})
+		return
+	}
+
+	window, err := mc.service.GetWindowByID(uint(id))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Maintenance window fetched successfully", "data": window})
+}
+
+// CreateMaintenanceWindow - Membuat jadwal maintenance baru
+func (mc *MaintenanceController) CreateMaintenanceWindow(c *gin.Context) {
+	window := entity.MaintenanceWindow{IsActive: true}
+	if err := c.ShouldBindJSON(&window); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	window.ID = 0
+	if err := mc.service.CreateWindow(&window); err != nil {
+		middleware.Logger.Error("Failed to create maintenance window", zap.Error(err))
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Maintenance window created successfully", "data": window})
+}
+
+// UpdateMaintenanceWindow - Mengubah jadwal maintenance
+func (mc *MaintenanceController) UpdateMaintenanceWindow(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid maintenance window ID"})
+		return
+	}
+
+	window := entity.MaintenanceWindow{IsActive: true}
+	if err := c.ShouldBindJSON(&window); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	window.ID = uint(id)
+	if err := mc.service.UpdateWindow(&window); err != nil {
+		middleware.Logger.Error("Failed to update maintenance window", zap.Error(err))
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Maintenance window updated successfully"})
+}
+
+// DeleteMaintenanceWindow - Menghapus jadwal maintenance
+func (mc *MaintenanceController) DeleteMaintenanceWindow(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid maintenance window ID"})
+		return
+	}
+
+	if err := mc.service.DeleteWindow(uint(id)); err != nil {
+		middleware.Logger.Error("Failed to delete maintenance window", zap.Error(err))
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Maintenance window deleted successfully"})
+}
@@ -0,0 +1,212 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"main.go/entity"
+	"main.go/middleware"
+	"main.go/service"
+)
+
+type PriceGroupController struct {
+	service service.PriceGroupService
+}
+
+func NewPriceGroupController(service service.PriceGroupService) *PriceGroupController {
+	return &PriceGroupController{service: service}
+}
+
+// GetPriceGroups - Daftar semua price group
+func (pc *PriceGroupController) GetPriceGroups(c *gin.Context) {
+	groups, err := pc.service.GetAllPriceGroups()
+	if err != nil {
+		middleware.Logger.Error("Failed to fetch price groups", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch price groups"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Price groups fetched successfully", "data": groups})
+}
+
+// GetPriceGroupByID - Detail price group beserta harga produk dan aturan kategori
+func (pc *PriceGroupController) GetPriceGroupByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid price group ID"})
+		return
+	}
+
+	group, err := pc.service.GetPriceGroupByID(uint(id))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Price group fetched successfully", "data": group})
+}
+
+// CreatePriceGroup - Membuat price group baru
+func (pc *PriceGroupController) CreatePriceGroup(c *gin.Context) {
+	var group entity.PriceGroup
+	if err := c.ShouldBindJSON(&group); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	group.ID = 0
+	group.Prices = nil
+	group.Rules = nil
+	if err := pc.service.CreatePriceGroup(&group); err != nil {
+		middleware.Logger.Error("Failed to create price group", zap.Error(err))
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Price group created successfully", "data": group})
+}
+
+// UpdatePriceGroup - Mengubah nama, deskripsi, atau status default price group
+func (pc *PriceGroupController) UpdatePriceGroup(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid price group ID"})
+		return
+	}
+
+	var group entity.PriceGroup
+	if err := c.ShouldBindJSON(&group); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	group.ID = uint(id)
+	if err := pc.service.UpdatePriceGroup(&group); err != nil {
+		middleware.Logger.Error("Failed to update price group", zap.Error(err))
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Price group updated successfully"})
+}
+
+// DeletePriceGroup - Menghapus price group
+func (pc *PriceGroupController) DeletePriceGroup(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid price group ID"})
+		return
+	}
+
+	if err := pc.service.DeletePriceGroup(uint(id)); err != nil {
+		middleware.Logger.Error("Failed to delete price group", zap.Error(err))
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Price group deleted successfully"})
+}
+
+// SetProductPrice - Menetapkan harga khusus produk dalam price group
+func (pc *PriceGroupController) SetProductPrice(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Param("id"))
+	productID, err2 := strconv.Atoi(c.Param("product_id"))
+	if err != nil || err2 != nil || groupID <= 0 || productID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid price group or product ID"})
+		return
+	}
+
+	var price entity.PriceGroupPrice
+	if err := c.ShouldBindJSON(&price); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	price.ID = 0
+	price.PriceGroupID = uint(groupID)
+	price.ProductID = uint(productID)
+	if err := pc.service.SetProductPrice(&price); err != nil {
+		middleware.Logger.Error("Failed to set price group price", zap.Error(err))
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Product price saved successfully", "data": price})
+}
+
+// DeleteProductPrice - Menghapus harga khusus produk dari price group
+func (pc *PriceGroupController) DeleteProductPrice(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Param("id"))
+	productID, err2 := strconv.Atoi(c.Param("product_id"))
+	if err != nil || err2 != nil || groupID <= 0 || productID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid price group or product ID"})
+		return
+	}
+
+	if err := pc.service.DeleteProductPrice(uint(groupID), uint(productID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete product price"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Product price deleted successfully"})
+}
+
+// SetCategoryRule - Menetapkan penyesuaian harga untuk seluruh produk dalam kategori
+func (pc *PriceGroupController) SetCategoryRule(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Param("id"))
+	categoryID, err2 := strconv.Atoi(c.Param("category_id"))
+	if err != nil || err2 != nil || groupID <= 0 || categoryID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid price group or category ID"})
+		return
+	}
+
+	var rule entity.PriceGroupRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	rule.ID = 0
+	rule.PriceGroupID = uint(groupID)
+	rule.CategoryID = uint(categoryID)
+	if err := pc.service.SetCategoryRule(&rule); err != nil {
+		middleware.Logger.Error("Failed to set price group rule", zap.Error(err))
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Category rule saved successfully", "data": rule})
+}
+
+// DeleteCategoryRule - Menghapus aturan kategori dari price group
+func (pc *PriceGroupController) DeleteCategoryRule(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Param("id"))
+	categoryID, err2 := strconv.Atoi(c.Param("category_id"))
+	if err != nil || err2 != nil || groupID <= 0 || categoryID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid price group or category ID"})
+		return
+	}
+
+	if err := pc.service.DeleteCategoryRule(uint(groupID), uint(categoryID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete category rule"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Category rule deleted successfully"})
+}
+
+// AssignUserPriceGroup - Memasukkan user ke price group (price_group_id null = default)
+func (pc *PriceGroupController) AssignUserPriceGroup(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || userID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req struct {
+		PriceGroupID *uint `json:"price_group_id"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	if err := pc.service.AssignUser(uint(userID), req.PriceGroupID); err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Price group assigned successfully"})
+}
@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"main.go/entity"
+	"main.go/middleware"
+	"main.go/service"
+)
+
+type PriceScheduleController struct {
+	service service.PriceScheduleService
+}
+
+func NewPriceScheduleController(service service.PriceScheduleService) *PriceScheduleController {
+	return &PriceScheduleController{service: service}
+}
+
+// GetProductPrices - Harga saat ini, riwayat harga dan jadwal harga yang akan berlaku untuk produk
+func (pc *PriceScheduleController) GetProductPrices(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	prices, err := pc.service.GetProductPrices(uint(id))
+	if err != nil {
+		middleware.Logger.Error("Failed to fetch product prices", zap.Error(err))
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Product prices fetched successfully", "data": prices})
+}
+
+// GetPriceSchedules - Daftar jadwal harga, dapat difilter dengan ?status=
+func (pc *PriceScheduleController) GetPriceSchedules(c *gin.Context) {
+	schedules, err := pc.service.GetSchedules(c.Query("status"))
+	if err != nil {
+		middleware.Logger.Error("Failed to fetch price schedules", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch price schedules"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Price schedules fetched successfully", "data": schedules})
+}
+
+// CreatePriceSchedule - Menjadwalkan perubahan harga produk atau kategori
+func (pc *PriceScheduleController) CreatePriceSchedule(c *gin.Context) {
+	var request entity.PriceScheduleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	schedule, err := pc.service.CreateSchedule(c.GetUint("user_id"), request)
+	if err != nil {
+		middleware.Logger.Error("Failed to create price schedule", zap.Error(err))
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Price schedule created successfully", "data": schedule})
+}
+
+// CancelPriceSchedule - Membatalkan jadwal harga yang belum diterapkan
+func (pc *PriceScheduleController) CancelPriceSchedule(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid price schedule ID"})
+		return
+	}
+
+	if err := pc.service.CancelSchedule(uint(id)); err != nil {
+		middleware.Logger.Error("Failed to cancel price schedule", zap.Error(err))
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Price schedule cancelled successfully"})
+}
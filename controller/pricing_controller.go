@@ -0,0 +1,160 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"main.go/entity"
+	"main.go/middleware"
+	"main.go/service"
+)
+
+type PricingController struct {
+	service service.PricingService
+}
+
+func NewPricingController(service service.PricingService) *PricingController {
+	return &PricingController{service: service}
+}
+
+// GetMarkupRules - Daftar semua aturan markup
+func (pc *PricingController) GetMarkupRules(c *gin.Context) {
+	rules, err := pc.service.GetAllMarkupRules()
+	if err != nil {
+		middleware.Logger.Error("Failed to fetch markup rules", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch markup rules"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Markup rules fetched successfully", "data": rules})
+}
+
+// CreateMarkupRule - Membuat aturan markup baru (belum mengubah harga sampai /apply dipanggil)
+func (pc *PricingController) CreateMarkupRule(c *gin.Context) {
+	rule := entity.MarkupRule{IsActive: true}
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	rule.ID = 0
+	if err := pc.service.CreateMarkupRule(&rule); err != nil {
+		middleware.Logger.Error("Failed to create markup rule", zap.Error(err))
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Markup rule created successfully", "data": rule})
+}
+
+// UpdateMarkupRule - Mengubah aturan markup
+func (pc *PricingController) UpdateMarkupRule(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid markup rule ID"})
+		return
+	}
+
+	rule := entity.MarkupRule{IsActive: true}
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	rule.ID = uint(id)
+	if err := pc.service.UpdateMarkupRule(&rule); err != nil {
+		middleware.Logger.Error("Failed to update markup rule", zap.Error(err))
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Markup rule updated successfully", "data": rule})
+}
+
+// DeleteMarkupRule - Menghapus aturan markup
+func (pc *PricingController) DeleteMarkupRule(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid markup rule ID"})
+		return
+	}
+
+	if err := pc.service.DeleteMarkupRule(uint(id)); err != nil {
+		middleware.Logger.Error("Failed to delete markup rule", zap.Error(err))
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Markup rule deleted successfully"})
+}
+
+// PreviewMarkup - Pratinjau dampak aturan markup (body kosong = aturan saat ini) tanpa menyimpan
+func (pc *PricingController) PreviewMarkup(c *gin.Context) {
+	var candidate *entity.MarkupRule
+	if c.Request.ContentLength != 0 {
+		rule := entity.MarkupRule{IsActive: true}
+		if err := c.ShouldBindJSON(&rule); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+			return
+		}
+		candidate = &rule
+	}
+
+	changes, err := pc.service.PreviewMarkup(candidate)
+	if err != nil {
+		middleware.Logger.Error("Failed to preview markup", zap.Error(err))
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Markup preview generated", "data": changes, "total": len(changes)})
+}
+
+// ApplyMarkup - Menerapkan aturan markup aktif ke harga jual produk
+func (pc *PricingController) ApplyMarkup(c *gin.Context) {
+	changes, err := pc.service.ApplyMarkup()
+	if err != nil {
+		middleware.Logger.Error("Failed to apply markup", zap.Error(err))
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Markup applied successfully", "data": changes, "total": len(changes)})
+}
+
+// GetProductCosts - Daftar harga modal produk per supplier
+func (pc *PricingController) GetProductCosts(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	costs, err := pc.service.GetProductCosts(uint(id))
+	if err != nil {
+		middleware.Logger.Error("Failed to fetch product costs", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch product costs"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Product costs fetched successfully", "data": costs})
+}
+
+// SetProductCost - Menyimpan harga modal produk untuk satu supplier
+func (pc *PricingController) SetProductCost(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	var cost entity.ProductCost
+	if err := c.ShouldBindJSON(&cost); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	cost.ID = 0
+	cost.ProductID = uint(id)
+	if err := pc.service.SetProductCost(&cost); err != nil {
+		middleware.Logger.Error("Failed to set product cost", zap.Error(err))
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Product cost saved successfully", "data": cost})
+}
@@ -7,8 +7,6 @@ import (
 	"main.go/middleware"
 	"main.go/service"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strconv"
 )
 
@@ -39,7 +37,7 @@ func (pc *ProductController) CreateCategory(c *gin.Context) {
 
 	if err := pc.service.CreateCategory(&category); err != nil {
 		middleware.Logger.Error("Failed to create category", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
 		return
 	}
 	middleware.Logger.Info("Category created successfully", zap.String("name", category.Name))
@@ -50,7 +48,14 @@ func (pc *ProductController) CreateCategory(c *gin.Context) {
 func (pc *ProductController) GetAllCategories(c *gin.Context) {
 	middleware.Logger.Info("Controller: GetAllCategories called")
 
-	categories, err := pc.service.GetAllCategories()
+	// Default berupa tree, flat=true mengembalikan daftar datar seperti sebelumnya
+	var categories []entity.Category
+	var err error
+	if c.Query("flat") == "true" {
+		categories, err = pc.service.GetAllCategories()
+	} else {
+		categories, err = pc.service.GetCategoryTree()
+	}
 	if err != nil {
 		middleware.Logger.Error("Failed to fetch categories", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch categories"})
@@ -80,12 +85,22 @@ func (pc *ProductController) GetCategoryByID(c *gin.Context) {
 	}
 
 	middleware.Logger.Info("Category fetched successfully", zap.String("name", category.Name))
+	setETag(c, category.Version)
 	c.JSON(http.StatusOK, gin.H{"message": "Category fetched successfully", "data": category})
 }
 
-// UpdateCategory - Update an existing category
+// UpdateCategory - Replace a category (PUT requires a complete object)
 func (pc *ProductController) UpdateCategory(c *gin.Context) {
-	middleware.Logger.Info("Controller: UpdateCategory called")
+	pc.writeCategory(c, pc.service.ReplaceCategory)
+}
+
+// PatchCategory - Partially update a category (only provided fields change)
+func (pc *ProductController) PatchCategory(c *gin.Context) {
+	pc.writeCategory(c, pc.service.PatchCategory)
+}
+
+func (pc *ProductController) writeCategory(c *gin.Context, update func(uint, entity.CategoryPatchRequest, uint) (*entity.Category, error)) {
+	middleware.Logger.Info("Controller: UpdateCategory called", zap.String("method", c.Request.Method))
 
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil || id <= 0 {
@@ -94,27 +109,27 @@ func (pc *ProductController) UpdateCategory(c *gin.Context) {
 		return
 	}
 
-	var category entity.Category
-	if err := c.ShouldBindJSON(&category); err != nil {
+	var request entity.CategoryPatchRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
 		middleware.Logger.Error("Invalid input", zap.Error(err))
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
 		return
 	}
-
-	if category.Name == "" {
-		middleware.Logger.Warn("Category name cannot be empty")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Category name cannot be empty"})
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusPreconditionFailed), gin.H{"error": err.Error()})
 		return
 	}
 
-	category.ID = uint(id)
-	if err := pc.service.UpdateCategory(&category); err != nil {
+	category, err := update(uint(id), request, version)
+	if err != nil {
 		middleware.Logger.Error("Failed to update category", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
 		return
 	}
 	middleware.Logger.Info("Category updated successfully", zap.String("name", category.Name))
-	c.JSON(http.StatusOK, gin.H{"message": "Category updated successfully"})
+	setETag(c, category.Version)
+	c.JSON(http.StatusOK, gin.H{"message": "Category updated successfully", "data": category})
 }
 
 // DeleteCategory - Delete a category by ID
@@ -128,27 +143,39 @@ func (pc *ProductController) DeleteCategory(c *gin.Context) {
 		return
 	}
 
-	if err := pc.service.DeleteCategory(uint(id)); err != nil {
+	// Kategori yang masih dipakai hanya bisa dihapus dengan policy=move&target_id=<id> atau policy=deactivate
+	targetID, _ := strconv.Atoi(c.Query("target_id"))
+	if targetID < 0 {
+		targetID = 0
+	}
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusPreconditionFailed), gin.H{"error": err.Error()})
+		return
+	}
+	result, err := pc.service.DeleteCategory(uint(id), c.Query("policy"), uint(targetID), c.GetUint("user_id"), version)
+	if err != nil {
 		middleware.Logger.Error("Failed to delete category", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
 		return
 	}
 	middleware.Logger.Info("Category deleted successfully", zap.Int("id", id))
-	c.JSON(http.StatusOK, gin.H{"message": "Category deleted successfully"})
+	c.JSON(http.StatusOK, gin.H{"message": "Category deleted successfully", "data": result})
 }
 
 // CreateProduct - Create a new product
 func (pc *ProductController) CreateProduct(c *gin.Context) {
 	middleware.Logger.Info("Controller: CreateProduct called")
 
-	var product entity.Product
+	// Produk baru aktif kecuali is_active dikirim false
+	product := entity.Product{IsActive: true}
 	if err := c.ShouldBindJSON(&product); err != nil {
 		middleware.Logger.Error("Invalid input", zap.Error(err))
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
 		return
 	}
 
-	if product.Name == "" || product.Price <= 0 || product.Stock < 0 {
+	if product.Name == "" || product.Price <= 0 || product.Stock < 0 || product.LowStockThreshold < 0 {
 		middleware.Logger.Warn("Invalid product data", zap.String("name", product.Name))
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product data"})
 		return
@@ -156,7 +183,7 @@ func (pc *ProductController) CreateProduct(c *gin.Context) {
 
 	if err := pc.service.CreateProduct(&product); err != nil {
 		middleware.Logger.Error("Failed to create product", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -164,19 +191,48 @@ func (pc *ProductController) CreateProduct(c *gin.Context) {
 	c.JSON(http.StatusCreated, gin.H{"message": "Product created successfully"})
 }
 
-// GetAllProducts - Retrieve all products
+// GetAllProducts - Retrieve products with search, filters, sorting and pagination
 func (pc *ProductController) GetAllProducts(c *gin.Context) {
 	middleware.Logger.Info("Controller: GetAllProducts called")
 
-	products, err := pc.service.GetAllProducts()
+	var filter entity.ProductFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		middleware.Logger.Error("Invalid query parameters", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+
+	// Administrator melihat harga dasar, user melihat harga sesuai price group-nya
+	isAdmin := middleware.HasRole(c, []string{"administrator"})
+
+	var products []entity.Product
+	var total int64
+	var err error
+	if isAdmin {
+		products, total, err = pc.service.SearchProducts(&filter)
+	} else {
+		products, total, err = pc.service.SearchProductsForUser(c.GetUint("user_id"), &filter)
+	}
 	if err != nil {
 		middleware.Logger.Error("Failed to fetch products", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch products"})
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
 		return
 	}
 
-	middleware.Logger.Info("Products fetched successfully", zap.Int("count", len(products)))
-	c.JSON(http.StatusOK, gin.H{"message": "Products fetched successfully", "data": products})
+	if !isAdmin {
+		for i := range products {
+			hideCostFields(&products[i])
+		}
+	}
+
+	middleware.Logger.Info("Products fetched successfully", zap.Int("count", len(products)), zap.Int64("total", total))
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Products fetched successfully",
+		"data":    products,
+		"page":    filter.Page,
+		"limit":   filter.Limit,
+		"total":   total,
+	})
 }
 
 // GetProductByID - Retrieve a product by ID
@@ -190,20 +246,70 @@ func (pc *ProductController) GetProductByID(c *gin.Context) {
 		return
 	}
 
-	product, err := pc.service.GetProductByID(uint(id))
+	isAdmin := middleware.HasRole(c, []string{"administrator"})
+
+	var product *entity.Product
+	if isAdmin {
+		product, err = pc.service.GetProductByID(uint(id))
+	} else {
+		product, err = pc.service.GetProductByIDForUser(uint(id), c.GetUint("user_id"))
+	}
 	if err != nil {
 		middleware.Logger.Error("Product not found", zap.Error(err))
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
+	if !isAdmin {
+		hideCostFields(product)
+	}
+
+	middleware.Logger.Info("Product fetched successfully", zap.String("name", product.Name))
+	setETag(c, product.Version)
+	c.JSON(http.StatusOK, gin.H{"message": "Product fetched successfully", "data": product})
+}
+
+// GetProductByCode - Retrieve a product by its SKU code
+func (pc *ProductController) GetProductByCode(c *gin.Context) {
+	middleware.Logger.Info("Controller: GetProductByCode called")
+
+	code := c.Param("code")
+	isAdmin := middleware.HasRole(c, []string{"administrator"})
+
+	var product *entity.Product
+	var err error
+	if isAdmin {
+		product, err = pc.service.GetProductByCode(code)
+	} else {
+		product, err = pc.service.GetProductByCodeForUser(code, c.GetUint("user_id"))
+	}
+	if err != nil {
+		middleware.Logger.Error("Product not found", zap.String("code", code), zap.Error(err))
+		c.JSON(errorStatus(err, http.StatusNotFound), gin.H{"error": err.Error()})
+		return
+	}
+
+	if !isAdmin {
+		hideCostFields(product)
+	}
+
 	middleware.Logger.Info("Product fetched successfully", zap.String("name", product.Name))
+	setETag(c, product.Version)
 	c.JSON(http.StatusOK, gin.H{"message": "Product fetched successfully", "data": product})
 }
 
-// UpdateProduct - Update an existing product
+// UpdateProduct - Replace a product (PUT requires a complete object)
 func (pc *ProductController) UpdateProduct(c *gin.Context) {
-	middleware.Logger.Info("Controller: UpdateProduct called")
+	pc.writeProduct(c, pc.service.ReplaceProduct)
+}
+
+// PatchProduct - Partially update a product (only provided fields change)
+func (pc *ProductController) PatchProduct(c *gin.Context) {
+	pc.writeProduct(c, pc.service.PatchProduct)
+}
+
+func (pc *ProductController) writeProduct(c *gin.Context, update func(uint, entity.ProductPatchRequest, uint, uint) (*entity.Product, error)) {
+	middleware.Logger.Info("Controller: UpdateProduct called", zap.String("method", c.Request.Method))
 
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil || id <= 0 {
@@ -212,22 +318,28 @@ func (pc *ProductController) UpdateProduct(c *gin.Context) {
 		return
 	}
 
-	var product entity.Product
-	if err := c.ShouldBindJSON(&product); err != nil {
+	var request entity.ProductPatchRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
 		middleware.Logger.Error("Invalid input", zap.Error(err))
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
 		return
 	}
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusPreconditionFailed), gin.H{"error": err.Error()})
+		return
+	}
 
-	product.ID = uint(id)
-	if err := pc.service.UpdateProduct(&product); err != nil {
+	product, err := update(uint(id), request, c.GetUint("user_id"), version)
+	if err != nil {
 		middleware.Logger.Error("Failed to update product", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
 		return
 	}
 
 	middleware.Logger.Info("Product updated successfully", zap.String("name", product.Name))
-	c.JSON(http.StatusOK, gin.H{"message": "Product updated successfully"})
+	setETag(c, product.Version)
+	c.JSON(http.StatusOK, gin.H{"message": "Product updated successfully", "data": product})
 }
 
 // DeleteProduct - Delete a product by ID
@@ -241,45 +353,24 @@ func (pc *ProductController) DeleteProduct(c *gin.Context) {
 		return
 	}
 
-	if err := pc.service.DeleteProduct(uint(id)); err != nil {
-		middleware.Logger.Error("Failed to delete product", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	middleware.Logger.Info("Product deleted successfully", zap.Int("id", id))
-	c.JSON(http.StatusOK, gin.H{"message": "Product deleted successfully"})
-}
-
-func (pc *ProductController) UploadProductImage(c *gin.Context) {
-	productID := c.Param("id")
-
-	// Ambil file dari request
-	file, err := c.FormFile("image")
+	version, err := ifMatchVersion(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file"})
+		c.JSON(errorStatus(err, http.StatusPreconditionFailed), gin.H{"error": err.Error()})
 		return
 	}
 
-	// Tentukan lokasi penyimpanan file
-	uploadDir := "uploads/products/"
-	if _, err := os.Stat(uploadDir); os.IsNotExist(err) {
-		os.MkdirAll(uploadDir, os.ModePerm)
-	}
-	filePath := filepath.Join(uploadDir, filepath.Base(file.Filename))
-
-	// Simpan file
-	if err := c.SaveUploadedFile(file, filePath); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save file"})
+	if err := pc.service.DeleteProduct(uint(id), version); err != nil {
+		middleware.Logger.Error("Failed to delete product", zap.Error(err))
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
 		return
 	}
 
-	// Update URL gambar di database
-	imageURL := "/" + filePath
-	if err := pc.service.UpdateProductImage(productID, imageURL); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update product image"})
-		return
-	}
+	middleware.Logger.Info("Product deleted successfully", zap.Int("id", id))
+	c.JSON(http.StatusOK, gin.H{"message": "Product deleted successfully"})
+}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Image uploaded successfully", "image_url": imageURL})
+// hideCostFields - Harga modal dan supplier hanya boleh dilihat administrator
+func hideCostFields(product *entity.Product) {
+	product.CostPrice = 0
+	product.Supplier = ""
 }
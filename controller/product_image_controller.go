@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"main.go/middleware"
+	"main.go/service"
+)
+
+type ProductImageController struct {
+	service service.ProductImageService
+}
+
+func NewProductImageController(service service.ProductImageService) *ProductImageController {
+	return &ProductImageController{service: service}
+}
+
+// UploadProductImage - Unggah gambar produk (multipart field "image")
+func (pc *ProductImageController) UploadProductImage(c *gin.Context) {
+	middleware.Logger.Info("Controller: UploadProductImage called")
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	fileHeader, err := c.FormFile("image")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Image file is required"})
+		return
+	}
+	if fileHeader.Size > service.MaxProductImageBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Image file is too large"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file"})
+		return
+	}
+	defer file.Close()
+
+	// Dibaca maksimal batas + 1 byte sehingga file yang melebihi batas tetap terdeteksi
+	data, err := io.ReadAll(io.LimitReader(file, service.MaxProductImageBytes+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file"})
+		return
+	}
+
+	result, err := pc.service.UploadImage(uint(id), data)
+	if err != nil {
+		middleware.Logger.Error("Failed to upload product image", zap.Error(err))
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	// image_url di level atas dipertahankan untuk klien lama
+	c.JSON(http.StatusOK, gin.H{"message": "Image uploaded successfully", "image_url": result.ImageURL, "data": result})
+}
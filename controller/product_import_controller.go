@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"main.go/middleware"
+	"main.go/service"
+)
+
+type ProductImportController struct {
+	service service.ProductImportService
+}
+
+func NewProductImportController(service service.ProductImportService) *ProductImportController {
+	return &ProductImportController{service: service}
+}
+
+// ImportProducts - Impor katalog produk dari CSV/XLSX, gunakan ?dry_run=true untuk melihat diff tanpa menyimpan
+func (pc *ProductImportController) ImportProducts(c *gin.Context) {
+	middleware.Logger.Info("Controller: ImportProducts called")
+
+	dryRun := false
+	if value := c.Query("dry_run"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "dry_run must be true or false"})
+			return
+		}
+		dryRun = parsed
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "CSV or XLSX file is required"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file"})
+		return
+	}
+	defer file.Close()
+
+	result, err := pc.service.Import(c.GetUint("user_id"), fileHeader.Filename, file, dryRun)
+	if err != nil {
+		middleware.Logger.Error("Failed to import products", zap.Error(err))
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	message := "Products imported successfully"
+	if dryRun {
+		message = "Dry run completed, no changes were saved"
+	}
+	c.JSON(http.StatusOK, gin.H{"message": message, "data": result})
+}
+
+// ExportProducts - Unduh seluruh katalog dalam format yang sama dengan file impor
+func (pc *ProductImportController) ExportProducts(c *gin.Context) {
+	format := c.DefaultQuery("format", service.CatalogFormatCSV)
+
+	content, err := pc.service.Export(format)
+	if err != nil {
+		middleware.Logger.Error("Failed to export products", zap.Error(err))
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	contentType := "text/csv"
+	if format == service.CatalogFormatXLSX {
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	filename := fmt.Sprintf("products_%s.%s", time.Now().Format("20060102_150405"), format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, contentType, content)
+}
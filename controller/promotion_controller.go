@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"main.go/entity"
+	"main.go/middleware"
+	"main.go/service"
+)
+
+type PromotionController struct {
+	service service.PromotionService
+}
+
+func NewPromotionController(service service.PromotionService) *PromotionController {
+	return &PromotionController{service: service}
+}
+
+// GetPromotions - Daftar semua promo
+func (pc *PromotionController) GetPromotions(c *gin.Context) {
+	promotions, err := pc.service.GetAllPromotions()
+	if err != nil {
+		middleware.Logger.Error("Failed to fetch promotions", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch promotions"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Promotions fetched successfully", "data": promotions})
+}
+
+// GetPromotionByID - Detail promo
+func (pc *PromotionController) GetPromotionByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid promotion ID"})
+		return
+	}
+
+	promotion, err := pc.service.GetPromotionByID(uint(id))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Promotion fetched successfully", "data": promotion})
+}
+
+// CreatePromotion - Membuat promo baru
+func (pc *PromotionController) CreatePromotion(c *gin.Context) {
+	promotion := entity.Promotion{IsActive: true}
+	if err := c.ShouldBindJSON(&promotion); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	promotion.ID = 0
+	if err := pc.service.CreatePromotion(&promotion); err != nil {
+		middleware.Logger.Error("Failed to create promotion", zap.Error(err))
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Promotion created successfully", "data": promotion})
+}
+
+// UpdatePromotion - Mengubah promo
+func (pc *PromotionController) UpdatePromotion(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid promotion ID"})
+		return
+	}
+
+	promotion := entity.Promotion{IsActive: true}
+	if err := c.ShouldBindJSON(&promotion); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	promotion.ID = uint(id)
+	if err := pc.service.UpdatePromotion(&promotion); err != nil {
+		middleware.Logger.Error("Failed to update promotion", zap.Error(err))
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Promotion updated successfully"})
+}
+
+// DeletePromotion - Menghapus promo
+func (pc *PromotionController) DeletePromotion(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid promotion ID"})
+		return
+	}
+
+	if err := pc.service.DeletePromotion(uint(id)); err != nil {
+		middleware.Logger.Error("Failed to delete promotion", zap.Error(err))
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Promotion deleted successfully"})
+}
+
+// GetPromotionUsages - Riwayat pemakaian promo
+func (pc *PromotionController) GetPromotionUsages(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid promotion ID"})
+		return
+	}
+
+	usages, err := pc.service.GetUsages(uint(id))
+	if err != nil {
+		middleware.Logger.Error("Failed to fetch promotion usages", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch promotion usages"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Promotion usages fetched successfully", "data": usages})
+}
@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"main.go/entity"
+	"main.go/middleware"
+	"main.go/service"
+)
+
+type ReferralController struct {
+	service service.ReferralService
+}
+
+func NewReferralController(service service.ReferralService) *ReferralController {
+	return &ReferralController{service: service}
+}
+
+// GetReferral - Kode referral, upline dan downline milik user yang login
+func (rc *ReferralController) GetReferral(c *gin.Context) {
+	info, err := rc.service.GetReferralInfo(c.GetUint("user_id"))
+	if err != nil {
+		middleware.Logger.Error("Failed to fetch referral info", zap.Error(err))
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Referral info fetched successfully", "data": info})
+}
+
+// GetCommissionReport - Laporan komisi; user biasa hanya melihat komisinya sendiri
+func (rc *ReferralController) GetCommissionReport(c *gin.Context) {
+	var filters entity.CommissionFilters
+	if err := c.ShouldBindQuery(&filters); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid filters"})
+		return
+	}
+	if (filters.StartDate == "") != (filters.EndDate == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Start date and end date must be provided together"})
+		return
+	}
+
+	if c.GetString("role") != "administrator" {
+		filters.UplineID = c.GetUint("user_id")
+	}
+
+	report, err := rc.service.GetCommissionReport(filters)
+	if err != nil {
+		middleware.Logger.Error("Failed to generate commission report", zap.Error(err))
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Commission report generated successfully", "data": report})
+}
+
+// GetCommissionLevels - Daftar level dan persentase komisi
+func (rc *ReferralController) GetCommissionLevels(c *gin.Context) {
+	levels, err := rc.service.GetLevels()
+	if err != nil {
+		middleware.Logger.Error("Failed to fetch commission levels", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch commission levels"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Commission levels fetched successfully", "data": levels})
+}
+
+// SetCommissionLevel - Membuat atau mengubah persentase komisi sebuah level
+func (rc *ReferralController) SetCommissionLevel(c *gin.Context) {
+	level, err := strconv.Atoi(c.Param("level"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid level"})
+		return
+	}
+
+	var request struct {
+		Percentage float64 `json:"percentage" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	commissionLevel := entity.CommissionLevel{Level: level, Percentage: request.Percentage}
+	if err := rc.service.SetLevel(&commissionLevel); err != nil {
+		middleware.Logger.Error("Failed to set commission level", zap.Error(err))
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Commission level saved successfully", "data": commissionLevel})
+}
+
+// DeleteCommissionLevel - Menghapus level komisi
+func (rc *ReferralController) DeleteCommissionLevel(c *gin.Context) {
+	level, err := strconv.Atoi(c.Param("level"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid level"})
+		return
+	}
+
+	if err := rc.service.DeleteLevel(level); err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Commission level deleted successfully"})
+}
@@ -1,13 +1,19 @@
 package controller
 
 import (
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"main.go/entity"
 	"main.go/service"
 	"net/http"
+	"path"
 	"strconv"
+	"time"
 )
 
+// reportLinkExpiry - Masa berlaku URL unduhan laporan
+const reportLinkExpiry = 15 * time.Minute
+
 type ReportController struct {
 	reportService service.ReportService
 }
@@ -118,11 +124,11 @@ func (rc *ReportController) DownloadReport(c *gin.Context) {
 	}
 
 	// Simpan laporan sesuai format
-	var filePath string
+	var key string
 	if format == "csv" {
-		filePath, err = rc.reportService.SaveReportToCSV(summaries)
+		key, err = rc.reportService.SaveReportToCSV(summaries)
 	} else if format == "pdf" {
-		filePath, err = rc.reportService.SaveReportToPDF(summaries)
+		key, err = rc.reportService.SaveReportToPDF(summaries)
 	}
 
 	if err != nil {
@@ -130,6 +136,57 @@ func (rc *ReportController) DownloadReport(c *gin.Context) {
 		return
 	}
 
+	// link=true mengembalikan URL unduhan sementara alih-alih isi file
+	if c.Query("link") == "true" {
+		url, err := rc.reportService.GetReportURL(key, reportLinkExpiry)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create report link"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Report link created", "data": gin.H{
+			"url":        url,
+			"expires_at": time.Now().Add(reportLinkExpiry),
+		}})
+		return
+	}
+
 	// Kirim file ke klien
-	c.File(filePath)
+	file, err := rc.reportService.OpenReport(key)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read report"})
+		return
+	}
+	defer file.Close()
+
+	contentType := "text/csv"
+	if format == "pdf" {
+		contentType = "application/pdf"
+	}
+	c.DataFromReader(http.StatusOK, -1, contentType, file, map[string]string{
+		"Content-Disposition": fmt.Sprintf(`attachment; filename="%s"`, path.Base(key)),
+	})
+}
+
+// GetCategoryReport untuk ringkasan transaksi per kategori tingkat atas
+func (rc *ReportController) GetCategoryReport(c *gin.Context) {
+	filters := entity.ReportFilters{
+		StartDate:   c.Query("start_date"),
+		EndDate:     c.Query("end_date"),
+		Username:    c.Query("username"),
+		ProductName: c.Query("product_name"),
+	}
+
+	// Validasi wajib adanya start_date dan end_date
+	if filters.StartDate == "" || filters.EndDate == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Start date and end date are required"})
+		return
+	}
+
+	isAdmin := c.GetString("role") == "administrator"
+	rollups, err := rc.reportService.GetCategoryRollup(filters, isAdmin, c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate category report"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": rollups})
 }
@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"main.go/entity"
+	"main.go/middleware"
+	"main.go/service"
+)
+
+type TextCommandController struct {
+	service service.TextCommandService
+}
+
+func NewTextCommandController(service service.TextCommandService) *TextCommandController {
+	return &TextCommandController{service: service}
+}
+
+// HandleGatewayMessage - Menerima pesan dari gateway chat dan mengembalikan teks balasan
+func (tc *TextCommandController) HandleGatewayMessage(c *gin.Context) {
+	var message entity.GatewayMessage
+	if err := c.ShouldBindJSON(&message); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sender and message are required"})
+		return
+	}
+
+	reply := tc.service.HandleMessage(message)
+	c.JSON(http.StatusOK, reply)
+}
+
+// GetCommandTemplates - Daftar template perintah teks
+func (tc *TextCommandController) GetCommandTemplates(c *gin.Context) {
+	commands, err := tc.service.GetAllCommands()
+	if err != nil {
+		middleware.Logger.Error("Failed to fetch command templates", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch command templates"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Command templates fetched successfully", "data": commands})
+}
+
+// CreateCommandTemplate - Membuat template perintah teks
+func (tc *TextCommandController) CreateCommandTemplate(c *gin.Context) {
+	command := entity.CommandTemplate{IsActive: true}
+	if err := c.ShouldBindJSON(&command); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	command.ID = 0
+	if err := tc.service.CreateCommand(&command); err != nil {
+		middleware.Logger.Error("Failed to create command template", zap.Error(err))
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Command template created successfully", "data": command})
+}
+
+// UpdateCommandTemplate - Mengubah template perintah teks
+func (tc *TextCommandController) UpdateCommandTemplate(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid command template ID"})
+		return
+	}
+
+	command := entity.CommandTemplate{IsActive: true}
+	if err := c.ShouldBindJSON(&command); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	command.ID = uint(id)
+	if err := tc.service.UpdateCommand(&command); err != nil {
+		middleware.Logger.Error("Failed to update command template", zap.Error(err))
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Command template updated successfully"})
+}
+
+// DeleteCommandTemplate - Menghapus template perintah teks
+func (tc *TextCommandController) DeleteCommandTemplate(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid command template ID"})
+		return
+	}
+
+	if err := tc.service.DeleteCommand(uint(id)); err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Command template deleted successfully"})
+}
+
+// GetReplyTemplates - Daftar template balasan yang berlaku
+func (tc *TextCommandController) GetReplyTemplates(c *gin.Context) {
+	replies, err := tc.service.GetReplies()
+	if err != nil {
+		middleware.Logger.Error("Failed to fetch reply templates", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reply templates"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Reply templates fetched successfully", "data": replies})
+}
+
+// SetReplyTemplate - Mengubah template balasan berdasarkan key
+func (tc *TextCommandController) SetReplyTemplate(c *gin.Context) {
+	var request struct {
+		Template string `json:"template" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	reply := entity.ReplyTemplate{Key: c.Param("key"), Template: request.Template}
+	if err := tc.service.SetReply(&reply); err != nil {
+		middleware.Logger.Error("Failed to set reply template", zap.Error(err))
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Reply template saved successfully", "data": reply})
+}
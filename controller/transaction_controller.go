@@ -34,7 +34,7 @@ func (tc *TransactionsController) CreateTransaction(c *gin.Context) {
 
 	transaction, err := tc.service.CreateTransaction(&transactionRequest)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -65,6 +65,7 @@ func (tc *TransactionsController) GetTransactionByID(c *gin.Context) {
 	}
 
 	response := service.ConvertToTransactionResponse(transaction)
+	setETag(c, transaction.Version)
 	c.JSON(http.StatusOK, gin.H{"message": "Transaction fetched successfully", "data": response})
 }
 
@@ -90,9 +91,15 @@ func (tc *TransactionsController) UpdateTransactionStatus(c *gin.Context) {
 		return
 	}
 
-	if err := tc.service.UpdateTransactionStatus(uint(id), statusRequest.Status); err != nil {
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusPreconditionFailed), gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tc.service.UpdateTransactionStatus(uint(id), statusRequest.Status, version); err != nil {
 		middleware.Logger.Error("Failed to update transaction status", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -115,9 +122,15 @@ func (tc *TransactionsController) DeleteTransaction(c *gin.Context) {
 		return
 	}
 
-	if err := tc.service.DeleteTransaction(uint(id)); err != nil {
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusPreconditionFailed), gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tc.service.DeleteTransaction(uint(id), version); err != nil {
 		middleware.Logger.Error("Failed to delete transaction", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
 		return
 	}
 
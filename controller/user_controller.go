@@ -3,9 +3,11 @@ package controller
 import (
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+	"main.go/entity"
 	"main.go/middleware"
 	"main.go/service"
 	"net/http"
+	"strconv"
 )
 
 type UserController struct {
@@ -97,19 +99,35 @@ func (uc *UserController) GetUserDetails(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	c.JSON(http.StatusOK, userDetails(user))
+}
+
+// userDetails - Data profil yang aman ditampilkan (tanpa password dan PIN)
+func userDetails(user *entity.User) gin.H {
+	return gin.H{
 		"id":         user.ID,
 		"full_name":  user.FullName,
 		"phone":      user.PhoneNumber,
 		"email":      user.Email,
 		"address":    user.Address,
 		"role":       user.Role,
+		"balance":    user.Balance,
+		"pin_set":    user.Pin != "",
 		"created_at": user.CreatedAt,
-	})
+	}
 }
 
 // ======================== UPDATE PROFILE ==========================
 func (uc *UserController) UpdateUser(c *gin.Context) {
+	uc.writeUser(c, uc.userService.ReplaceUser)
+}
+
+// PatchUser - Ubah sebagian profil (hanya field yang dikirim)
+func (uc *UserController) PatchUser(c *gin.Context) {
+	uc.writeUser(c, uc.userService.PatchUser)
+}
+
+func (uc *UserController) writeUser(c *gin.Context, update func(uint, entity.UserPatchRequest) (*entity.User, error)) {
 	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
@@ -121,18 +139,19 @@ func (uc *UserController) UpdateUser(c *gin.Context) {
 		return
 	}
 
-	var updatedData service.UserUpdateRequest
-	if err := c.ShouldBindJSON(&updatedData); err != nil {
+	var request entity.UserPatchRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
 		return
 	}
 
-	if err := uc.userService.UpdateUser(userIDUint, updatedData); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	user, err := update(userIDUint, request)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "User updated successfully"})
+	c.JSON(http.StatusOK, gin.H{"message": "User updated successfully", "data": userDetails(user)})
 }
 
 // ======================== UPDATE PROFILE ==========================
@@ -153,3 +172,64 @@ func (uc *UserController) Logout(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Logout successful"})
 }
+
+// ======================== PIN ==========================
+// SetPin - Membuat PIN transaksi pertama kali (butuh password)
+func (uc *UserController) SetPin(c *gin.Context) {
+	var request service.UserPinRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	if err := uc.userService.SetPin(c.GetUint("user_id"), request); err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "PIN set successfully"})
+}
+
+// ChangePin - Mengganti PIN transaksi (butuh PIN lama)
+func (uc *UserController) ChangePin(c *gin.Context) {
+	var request service.UserPinRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	if err := uc.userService.ChangePin(c.GetUint("user_id"), request); err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "PIN changed successfully"})
+}
+
+// ResetPin - Mengganti PIN yang terlupa (butuh password)
+func (uc *UserController) ResetPin(c *gin.Context) {
+	var request service.UserPinRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	if err := uc.userService.ResetPin(c.GetUint("user_id"), request); err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "PIN reset successfully"})
+}
+
+// ClearUserPin - Admin menghapus PIN user agar user membuat PIN baru
+func (uc *UserController) ClearUserPin(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err := uc.userService.ClearPin(c.GetUint("user_id"), uint(id)); err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "User PIN cleared successfully"})
+}
@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"main.go/middleware"
+	"main.go/service"
+)
+
+type VoucherController struct {
+	service service.VoucherService
+}
+
+func NewVoucherController(service service.VoucherService) *VoucherController {
+	return &VoucherController{service: service}
+}
+
+// ImportVoucherCodes - Admin mengimpor batch kode voucher (CSV) untuk sebuah produk
+func (vc *VoucherController) ImportVoucherCodes(c *gin.Context) {
+	middleware.Logger.Info("Controller: ImportVoucherCodes called")
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		middleware.Logger.Error("Invalid product ID", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "CSV file is required"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file"})
+		return
+	}
+	defer file.Close()
+
+	batchName := c.PostForm("batch_name")
+	if batchName == "" {
+		batchName = fileHeader.Filename
+	}
+
+	result, err := vc.service.ImportCodes(uint(id), c.GetUint("user_id"), batchName, file)
+	if err != nil {
+		middleware.Logger.Error("Failed to import voucher codes", zap.Error(err))
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Voucher codes imported successfully", "data": result})
+}
+
+// GetVoucherBatches - Daftar batch kode voucher untuk sebuah produk
+func (vc *VoucherController) GetVoucherBatches(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	batches, err := vc.service.GetBatches(uint(id))
+	if err != nil {
+		middleware.Logger.Error("Failed to fetch voucher batches", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch voucher batches"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Voucher batches fetched successfully", "data": batches})
+}
+
+// GetVoucherAssignments - Jejak audit kode voucher yang sudah diberikan ke user
+func (vc *VoucherController) GetVoucherAssignments(c *gin.Context) {
+	productID, _ := strconv.Atoi(c.Query("product_id"))
+	userID, _ := strconv.Atoi(c.Query("user_id"))
+	if productID < 0 || userID < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid filter"})
+		return
+	}
+
+	assignments, err := vc.service.GetAssignments(uint(productID), uint(userID))
+	if err != nil {
+		middleware.Logger.Error("Failed to fetch voucher assignments", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch voucher assignments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Voucher assignments fetched successfully", "data": assignments})
+}
@@ -0,0 +1,71 @@
+package entity
+
+import "time"
+
+// Scope API key H2H
+const (
+	ScopePurchase = "purchase"
+	ScopeStatus   = "status"
+	ScopeBalance  = "balance"
+)
+
+// APIKey - Kredensial host-to-host milik reseller. Secret disimpan terenkripsi karena dibutuhkan untuk memverifikasi HMAC.
+type APIKey struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"not null;index" json:"user_id"`
+	Name       string     `gorm:"size:100;not null" json:"name"`
+	KeyID      string     `gorm:"size:40;uniqueIndex;not null" json:"key_id"`
+	Secret     string     `gorm:"type:text;not null" json:"-"`
+	Scopes     []string   `gorm:"serializer:json;type:text" json:"scopes"`
+	AllowedIPs []string   `gorm:"serializer:json;type:text" json:"allowed_ips"` // IP atau CIDR; kosong = semua IP
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	User       User       `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// APIKeyRequest - Permintaan pembuatan API key
+type APIKeyRequest struct {
+	Name       string   `json:"name" binding:"required"`
+	Scopes     []string `json:"scopes" binding:"required"`
+	AllowedIPs []string `json:"allowed_ips"`
+}
+
+// APIKeyCreated - API key baru beserta secret-nya; secret hanya ditampilkan sekali
+type APIKeyCreated struct {
+	APIKey
+	Secret string `json:"secret"`
+}
+
+// H2HPurchaseRequest - Request pembelian dari server reseller
+type H2HPurchaseRequest struct {
+	RefID          string            `json:"ref_id" binding:"required"`
+	ProductID      uint              `json:"product_id"`
+	ProductCode    string            `json:"product_code"` // Wajib diisi jika product_id kosong
+	Quantity       int               `json:"quantity"`
+	Destination    string            `json:"destination"`
+	Pin            string            `json:"pin" binding:"required"`
+	CustomerInputs map[string]string `json:"customer_inputs"`
+}
+
+// H2HResponse - Kontrak response tetap untuk semua endpoint transaksi H2H
+type H2HResponse struct {
+	Success       bool       `json:"success"`
+	RefID         string     `json:"ref_id"`
+	TransactionID uint       `json:"trx_id,omitempty"`
+	Status        string     `json:"status,omitempty"`
+	ProductID     uint       `json:"product_id,omitempty"`
+	ProductCode   string     `json:"product_code,omitempty"`
+	Destination   string     `json:"destination,omitempty"`
+	Price         float64    `json:"price,omitempty"`
+	SerialNumber  string     `json:"serial_number,omitempty"`
+	Message       string     `json:"message"`
+	CreatedAt     *time.Time `json:"created_at,omitempty"`
+}
+
+// H2HBalanceResponse - Response cek saldo H2H
+type H2HBalanceResponse struct {
+	Success bool    `json:"success"`
+	Balance float64 `json:"balance"`
+	Message string  `json:"message"`
+}
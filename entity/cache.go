@@ -0,0 +1,16 @@
+package entity
+
+import "time"
+
+// CacheStats - Statistik cache katalog untuk endpoint admin
+type CacheStats struct {
+	Enabled       bool       `json:"enabled"`
+	TTL           string     `json:"ttl"`
+	Items         int        `json:"items"`
+	Hits          int64      `json:"hits"`
+	Misses        int64      `json:"misses"`
+	HitRate       float64    `json:"hit_rate"` // Persentase hit dari seluruh pembacaan
+	Invalidations int64      `json:"invalidations"`
+	Flushes       int64      `json:"flushes"`
+	LastFlushAt   *time.Time `json:"last_flush_at,omitempty"`
+}
@@ -0,0 +1,59 @@
+package entity
+
+import "time"
+
+// Cart - Keranjang belanja per user yang tersimpan di server
+type Cart struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"uniqueIndex;not null" json:"user_id"`
+	Items     []CartItem `gorm:"foreignKey:CartID;constraint:OnDelete:CASCADE;" json:"items"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// CartItem - Produk di dalam keranjang; harga tidak disimpan karena selalu dihitung ulang
+type CartItem struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CartID    uint      `gorm:"not null;uniqueIndex:idx_cart_product" json:"cart_id"`
+	ProductID uint      `gorm:"not null;uniqueIndex:idx_cart_product" json:"product_id"`
+	Quantity  int       `gorm:"not null" json:"quantity"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Product   Product   `gorm:"foreignKey:ProductID" json:"-"`
+}
+
+// CartItemRequest - Menambah atau mengubah jumlah produk di keranjang
+type CartItemRequest struct {
+	ProductID uint `json:"product_id"`
+	Quantity  int  `json:"quantity" binding:"required"`
+}
+
+// CartLine - Baris keranjang dengan harga dan stok terkini
+type CartLine struct {
+	ProductID uint    `json:"product_id"`
+	Name      string  `json:"name"`
+	Quantity  int     `json:"quantity"`
+	UnitPrice float64 `json:"unit_price"`
+	Subtotal  float64 `json:"subtotal"`
+	Stock     int     `json:"stock"`
+	Available bool    `json:"available"`
+	Message   string  `json:"message,omitempty"` // Alasan item tidak dapat di-checkout
+}
+
+// CartView - Isi keranjang yang sudah divalidasi terhadap harga dan stok terkini
+type CartView struct {
+	Items       []CartLine `json:"items"`
+	TotalItems  int        `json:"total_items"`
+	Total       float64    `json:"total"`
+	CanCheckout bool       `json:"can_checkout"`
+}
+
+// CartCheckoutRequest - Data tambahan saat keranjang diubah menjadi transaksi
+type CartCheckoutRequest struct {
+	DestinationNumber string            `json:"destination_number"`
+	FavoriteID        uint              `json:"favorite_id"`
+	CustomerInputs    map[string]string `json:"customer_inputs"`
+	PromoCode         string            `json:"promo_code"`
+	ReferenceID       string            `json:"reference_id"`
+	Pin               string            `json:"pin" binding:"required"`
+}
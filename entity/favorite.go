@@ -0,0 +1,29 @@
+package entity
+
+import "time"
+
+// Favorite - Nomor tujuan yang disimpan user (buku alamat)
+type Favorite struct {
+	ID                uint       `gorm:"primaryKey" json:"id"`
+	UserID            uint       `gorm:"not null;uniqueIndex:idx_favorite_user_number" json:"user_id"`
+	Label             string     `gorm:"size:100;not null" json:"label"`
+	DestinationNumber string     `gorm:"size:20;not null;uniqueIndex:idx_favorite_user_number" json:"destination_number"`
+	Operator          string     `gorm:"size:50" json:"operator"` // Terdeteksi dari prefix nomor
+	LastUsedAt        *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// FavoriteRequest - Membuat atau mengubah nomor favorit
+type FavoriteRequest struct {
+	Label             string `json:"label" binding:"required"`
+	DestinationNumber string `json:"destination_number" binding:"required"`
+}
+
+// FavoriteSuggestion - Nomor yang sering dipakai di transaksi tetapi belum disimpan
+type FavoriteSuggestion struct {
+	DestinationNumber string    `json:"destination_number"`
+	Operator          string    `json:"operator"`
+	UsageCount        int64     `json:"usage_count"`
+	LastUsedAt        time.Time `json:"last_used_at"`
+}
@@ -0,0 +1,42 @@
+package entity
+
+import "time"
+
+// Jenis mutasi stok produk
+const (
+	InventoryPurchase   = "purchase"   // Stok terpakai oleh transaksi
+	InventoryRefund     = "refund"     // Stok dikembalikan karena transaksi gagal/di-refund
+	InventoryAdjustment = "adjustment" // Penyesuaian manual oleh admin (wajib ada alasan)
+	InventoryImport     = "import"     // Impor katalog atau impor kode voucher
+)
+
+// InventoryMovement - Jejak setiap perubahan stok produk. Quantity positif menambah stok, negatif mengurangi.
+type InventoryMovement struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	ProductID     uint      `gorm:"not null;index" json:"product_id"`
+	Type          string    `gorm:"size:20;not null;index" json:"type"` // purchase / refund / adjustment / import
+	Quantity      int       `gorm:"not null" json:"quantity"`
+	StockBefore   int       `gorm:"not null" json:"stock_before"`
+	StockAfter    int       `gorm:"not null" json:"stock_after"`
+	Reason        string    `gorm:"size:255" json:"reason"`
+	TransactionID *uint     `gorm:"index" json:"transaction_id,omitempty"`
+	CreatedBy     *uint     `json:"created_by,omitempty"` // Admin yang mengubah, kosong untuk perubahan otomatis
+	CreatedAt     time.Time `gorm:"index" json:"created_at"`
+}
+
+// StockAdjustmentRequest - Penyesuaian stok manual, Quantity adalah selisih (mis. -3 untuk barang rusak)
+type StockAdjustmentRequest struct {
+	Quantity int    `json:"quantity" binding:"required"`
+	Reason   string `json:"reason" binding:"required"`
+}
+
+// LowStockAlert - Data yang dikirim ke notification hook saat stok turun sampai batas minimum
+type LowStockAlert struct {
+	ProductID uint              `json:"product_id"`
+	Name      string            `json:"name"`
+	Code      *string           `json:"code,omitempty"`
+	Stock     int               `json:"stock"`
+	Threshold int               `json:"low_stock_threshold"`
+	Movement  InventoryMovement `json:"movement"`
+	AlertedAt time.Time         `json:"alerted_at"`
+}
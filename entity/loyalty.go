@@ -0,0 +1,70 @@
+package entity
+
+import "time"
+
+// Tipe entri ledger poin
+const (
+	LoyaltyEarn    = "earn"
+	LoyaltyRedeem  = "redeem"
+	LoyaltyExpire  = "expire"
+	LoyaltyReverse = "reverse"
+	LoyaltyRefund  = "refund" // Poin penukaran yang gagal dikembalikan
+)
+
+// Cara penukaran poin
+const (
+	RedeemAsBalance  = "balance"
+	RedeemAsDiscount = "discount"
+)
+
+// LoyaltyRule - Aturan perolehan poin; aturan kategori mengalahkan aturan umum (CategoryID kosong)
+type LoyaltyRule struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	Name           string    `gorm:"size:100;not null" json:"name"`
+	CategoryID     *uint     `gorm:"index" json:"category_id,omitempty"`
+	SpendUnit      float64   `gorm:"type:decimal(12,2);not null" json:"spend_unit"`       // Setiap kelipatan belanja ini...
+	PointsPerUnit  int       `gorm:"not null" json:"points_per_unit"`                     // ...mendapat poin sebanyak ini
+	MinTransaction float64   `gorm:"type:decimal(12,2);default:0" json:"min_transaction"` // Minimum subtotal item
+	ExpiryDays     int       `gorm:"default:0" json:"expiry_days"`                        // 0 = tidak kedaluwarsa
+	IsActive       bool      `gorm:"default:true" json:"is_active"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// LoyaltyLedger - Mutasi poin user. Entri earn menyimpan sisa poin (Remaining) untuk penukaran FIFO.
+type LoyaltyLedger struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	UserID        uint       `gorm:"not null;index" json:"user_id"`
+	TransactionID *uint      `gorm:"index" json:"transaction_id,omitempty"`
+	Type          string     `gorm:"size:20;not null" json:"type"`         // earn / redeem / expire / reverse / refund
+	Points        int        `gorm:"not null" json:"points"`               // Positif untuk earn/refund, negatif untuk lainnya
+	Remaining     int        `gorm:"default:0" json:"remaining,omitempty"` // Sisa poin earn, atau utang poin (negatif) pada reverse
+	ExpiresAt     *time.Time `gorm:"index" json:"expires_at,omitempty"`
+	Description   string     `gorm:"size:255" json:"description"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// LoyaltySummary - Ringkasan poin untuk GET /api/loyalty
+type LoyaltySummary struct {
+	Points       int             `json:"points"`
+	PointValue   float64         `json:"point_value"` // Nilai rupiah per poin
+	Balance      float64         `json:"balance"`
+	ExpiringSoon int             `json:"expiring_soon"` // Poin yang kedaluwarsa dalam 30 hari
+	History      []LoyaltyLedger `json:"history"`
+}
+
+// LoyaltyRedeemRequest - Permintaan penukaran poin
+type LoyaltyRedeemRequest struct {
+	Points int    `json:"points" binding:"required"`
+	Type   string `json:"type" binding:"required"` // balance / discount
+}
+
+// LoyaltyRedeemResult - Hasil penukaran poin
+type LoyaltyRedeemResult struct {
+	Points    int     `json:"points"`
+	Type      string  `json:"type"`
+	Amount    float64 `json:"amount"`
+	PromoCode string  `json:"promo_code,omitempty"`
+	Balance   float64 `json:"balance"`
+	Remaining int     `json:"remaining_points"`
+}
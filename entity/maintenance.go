@@ -0,0 +1,34 @@
+package entity
+
+import "time"
+
+// Cakupan jadwal maintenance
+const (
+	MaintenanceScopeProduct  = "product"
+	MaintenanceScopeCategory = "category"
+	MaintenanceScopeOperator = "operator"
+)
+
+// Jenis jadwal maintenance
+const (
+	MaintenanceDaily   = "daily"    // Berulang setiap hari, mis. cutoff operator 23:00-00:30
+	MaintenanceOneTime = "one_time" // Sekali jalan, mis. gangguan supplier
+)
+
+// MaintenanceWindow - Jadwal ketika produk tidak dapat dibeli
+type MaintenanceWindow struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	Name      string     `gorm:"size:100;not null" json:"name"`
+	Scope     string     `gorm:"size:20;not null;index" json:"scope"`     // product / category / operator
+	TargetID  uint       `gorm:"index" json:"target_id,omitempty"`        // ID produk atau kategori
+	Operator  string     `gorm:"size:50;index" json:"operator,omitempty"` // Untuk scope operator
+	Type      string     `gorm:"size:20;not null" json:"type"`            // daily / one_time
+	StartTime string     `gorm:"size:5" json:"start_time,omitempty"`      // HH:MM, untuk daily
+	EndTime   string     `gorm:"size:5" json:"end_time,omitempty"`        // HH:MM, boleh melewati tengah malam
+	StartAt   *time.Time `json:"start_at,omitempty"`                      // Untuk one_time
+	EndAt     *time.Time `json:"end_at,omitempty"`
+	Reason    string     `gorm:"size:255" json:"reason"`
+	IsActive  bool       `gorm:"default:true" json:"is_active"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,62 @@
+package entity
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// PatchField - Field pada body PATCH yang membedakan "tidak dikirim", "dikirim null" dan "dikirim dengan nilai"
+type PatchField[T any] struct {
+	Set   bool // Field ada di body
+	Null  bool // Field dikirim sebagai null
+	Value T
+}
+
+// UnmarshalJSON hanya dipanggil untuk field yang ada di body, sehingga Set tetap false untuk field yang tidak dikirim
+func (f *PatchField[T]) UnmarshalJSON(data []byte) error {
+	f.Set = true
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		f.Null = true
+		var zero T
+		f.Value = zero
+		return nil
+	}
+	f.Null = false
+	return json.Unmarshal(data, &f.Value)
+}
+
+// HasValue - Field dikirim dengan nilai (bukan null)
+func (f PatchField[T]) HasValue() bool {
+	return f.Set && !f.Null
+}
+
+// ProductPatchRequest - Body PATCH/PUT produk. Gambar hanya diubah lewat endpoint upload gambar.
+type ProductPatchRequest struct {
+	Name              PatchField[string]       `json:"name"`
+	Code              PatchField[string]       `json:"code"`        // null menghapus kode
+	Description       PatchField[string]       `json:"description"` // null mengosongkan
+	Price             PatchField[float64]      `json:"price"`
+	CostPrice         PatchField[float64]      `json:"cost_price"`          // null mengosongkan (0)
+	Supplier          PatchField[string]       `json:"supplier"`            // null mengosongkan
+	Operator          PatchField[string]       `json:"operator"`            // null mengosongkan
+	Stock             PatchField[int]          `json:"stock"`               // Harus sama dengan stok saat ini, perubahan lewat endpoint inventori
+	LowStockThreshold PatchField[int]          `json:"low_stock_threshold"` // null mematikan alert (0)
+	CategoryID        PatchField[uint]         `json:"category_id"`
+	IsVoucher         PatchField[bool]         `json:"is_voucher"`
+	InputSchema       PatchField[[]InputField] `json:"input_schema"` // null menghapus schema
+	IsActive          PatchField[bool]         `json:"is_active"`
+}
+
+// CategoryPatchRequest - Body PATCH/PUT kategori
+type CategoryPatchRequest struct {
+	Name        PatchField[string] `json:"name"`
+	Description PatchField[string] `json:"description"` // null mengosongkan
+	ParentID    PatchField[uint]   `json:"parent_id"`   // null menjadikan kategori tingkat atas
+}
+
+// UserPatchRequest - Body PATCH/PUT profil user
+type UserPatchRequest struct {
+	FullName PatchField[string] `json:"full_name"`
+	Email    PatchField[string] `json:"email"`   // null menghapus email
+	Address  PatchField[string] `json:"address"` // null mengosongkan
+}
@@ -0,0 +1,37 @@
+package entity
+
+import "time"
+
+// PriceGroup - Kelompok harga (mis. end user, agen, master dealer)
+type PriceGroup struct {
+	ID          uint              `gorm:"primaryKey" json:"id"`
+	Name        string            `gorm:"size:50;unique;not null" json:"name"`
+	Description string            `gorm:"type:text" json:"description"`
+	IsDefault   bool              `gorm:"default:false" json:"is_default"` // Dipakai untuk user tanpa price group
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+	Prices      []PriceGroupPrice `gorm:"foreignKey:PriceGroupID;constraint:OnDelete:CASCADE;" json:"prices,omitempty"`
+	Rules       []PriceGroupRule  `gorm:"foreignKey:PriceGroupID;constraint:OnDelete:CASCADE;" json:"rules,omitempty"`
+}
+
+// PriceGroupPrice - Harga khusus satu produk untuk sebuah price group
+type PriceGroupPrice struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	PriceGroupID uint      `gorm:"not null;uniqueIndex:idx_price_group_product" json:"price_group_id"`
+	ProductID    uint      `gorm:"not null;uniqueIndex:idx_price_group_product" json:"product_id"`
+	Price        float64   `gorm:"type:decimal(12,2);not null" json:"price"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// PriceGroupRule - Penyesuaian harga dasar untuk seluruh produk dalam satu kategori.
+// Value boleh negatif (diskon) atau positif, bertipe fixed atau percentage.
+type PriceGroupRule struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	PriceGroupID uint      `gorm:"not null;uniqueIndex:idx_price_group_category" json:"price_group_id"`
+	CategoryID   uint      `gorm:"not null;uniqueIndex:idx_price_group_category" json:"category_id"`
+	Type         string    `gorm:"size:20;not null" json:"type"` // fixed / percentage
+	Value        float64   `gorm:"type:decimal(12,2);not null" json:"value"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
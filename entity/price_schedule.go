@@ -0,0 +1,78 @@
+package entity
+
+import "time"
+
+// Sumber perubahan harga produk
+const (
+	PriceSourceManual   = "manual"
+	PriceSourceImport   = "import"
+	PriceSourceSchedule = "schedule"
+	PriceSourceMarkup   = "markup"
+)
+
+// Cakupan jadwal harga
+const (
+	PriceScheduleProduct  = "product"
+	PriceScheduleCategory = "category"
+)
+
+// Cara menghitung harga baru
+const (
+	PriceChangeFixed      = "fixed"      // Harga baru ditetapkan langsung (hanya untuk produk)
+	PriceChangePercentage = "percentage" // Naik/turun persen dari harga saat jadwal diterapkan
+	PriceChangeAmount     = "amount"     // Naik/turun nominal dari harga saat jadwal diterapkan
+)
+
+// Status jadwal harga
+const (
+	PriceSchedulePending   = "pending"
+	PriceScheduleApplied   = "applied"
+	PriceScheduleCancelled = "cancelled"
+	PriceScheduleFailed    = "failed"
+)
+
+// ProductPriceHistory - Jejak setiap perubahan harga dasar produk
+type ProductPriceHistory struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	ProductID  uint      `gorm:"not null;index" json:"product_id"`
+	OldPrice   float64   `gorm:"type:decimal(12,2);not null" json:"old_price"`
+	NewPrice   float64   `gorm:"type:decimal(12,2);not null" json:"new_price"`
+	Source     string    `gorm:"size:20;not null" json:"source"` // manual / import / schedule / markup
+	ScheduleID *uint     `gorm:"index" json:"schedule_id,omitempty"`
+	ChangedBy  *uint     `json:"changed_by,omitempty"` // Admin yang mengubah, kosong untuk jadwal
+	CreatedAt  time.Time `gorm:"index" json:"created_at"`
+}
+
+// PriceSchedule - Perubahan harga yang berlaku mulai waktu tertentu
+type PriceSchedule struct {
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	Scope           string     `gorm:"size:20;not null" json:"scope"` // product / category
+	TargetID        uint       `gorm:"not null;index" json:"target_id"`
+	ChangeType      string     `gorm:"size:20;not null" json:"change_type"` // fixed / percentage / amount
+	Value           float64    `gorm:"type:decimal(12,2);not null" json:"value"`
+	EffectiveAt     time.Time  `gorm:"not null;index" json:"effective_at"`
+	Status          string     `gorm:"size:20;not null;index" json:"status"`
+	AppliedAt       *time.Time `json:"applied_at,omitempty"`
+	ProductsUpdated int        `gorm:"default:0" json:"products_updated"`
+	FailReason      string     `gorm:"size:255" json:"fail_reason,omitempty"`
+	CreatedBy       uint       `json:"created_by"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// PriceScheduleRequest - Request pembuatan jadwal harga
+type PriceScheduleRequest struct {
+	Scope       string    `json:"scope" binding:"required"`
+	TargetID    uint      `json:"target_id" binding:"required"`
+	ChangeType  string    `json:"change_type" binding:"required"`
+	Value       float64   `json:"value"`
+	EffectiveAt time.Time `json:"effective_at" binding:"required"`
+}
+
+// ProductPriceInfo - Harga saat ini, riwayat dan jadwal yang akan datang untuk satu produk
+type ProductPriceInfo struct {
+	ProductID    uint                  `json:"product_id"`
+	CurrentPrice float64               `json:"current_price"`
+	History      []ProductPriceHistory `json:"history"`
+	Upcoming     []PriceSchedule       `json:"upcoming"`
+}
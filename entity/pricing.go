@@ -0,0 +1,51 @@
+package entity
+
+import "time"
+
+// Cakupan dan tipe markup
+const (
+	MarkupScopeGlobal   = "global"
+	MarkupScopeCategory = "category"
+	MarkupScopeOperator = "operator"
+
+	MarkupTypeFixed      = "fixed"
+	MarkupTypePercentage = "percentage"
+)
+
+// ProductCost - Harga modal produk dari supplier tertentu
+type ProductCost struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	ProductID uint      `gorm:"not null;uniqueIndex:idx_product_supplier" json:"product_id"`
+	Supplier  string    `gorm:"size:50;not null;uniqueIndex:idx_product_supplier" json:"supplier"`
+	CostPrice float64   `gorm:"type:decimal(12,2);not null" json:"cost_price"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// MarkupRule - Aturan markup untuk menghitung harga jual dari harga modal
+type MarkupRule struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	Name       string    `gorm:"size:100;not null" json:"name"`
+	Scope      string    `gorm:"size:20;not null" json:"scope"` // global / category / operator
+	CategoryID *uint     `gorm:"index" json:"category_id,omitempty"`
+	Operator   string    `gorm:"size:50;index" json:"operator,omitempty"`
+	Type       string    `gorm:"size:20;not null" json:"type"` // fixed / percentage
+	Value      float64   `gorm:"type:decimal(12,2);not null" json:"value"`
+	Priority   int       `gorm:"default:0" json:"priority"` // Lebih besar menang jika cakupan sama
+	IsActive   bool      `gorm:"default:true" json:"is_active"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// MarkupPreviewItem - Dampak perubahan markup terhadap satu produk
+type MarkupPreviewItem struct {
+	ProductID     uint    `json:"product_id"`
+	ProductName   string  `json:"product_name"`
+	CostPrice     float64 `json:"cost_price"`
+	CurrentPrice  float64 `json:"current_price"`
+	NewPrice      float64 `json:"new_price"`
+	CurrentMargin float64 `json:"current_margin"`
+	NewMargin     float64 `json:"new_margin"`
+	RuleID        uint    `json:"rule_id"`
+	RuleName      string  `json:"rule_name"`
+}
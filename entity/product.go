@@ -1,24 +1,110 @@
 package entity
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Product struct {
 	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
-	Name        string    `gorm:"not null" json:"name"`
+	Name        string    `gorm:"size:150;not null;index" json:"name"`
+	Code        *string   `gorm:"size:32;uniqueIndex" json:"code,omitempty"` // Kode SKU singkat, mis. TSEL10
 	Description string    `gorm:"type:text" json:"description"`
-	Price       float64   `gorm:"not null" json:"price"`
-	Stock       int       `gorm:"default:0" json:"stock"`
-	CategoryID  uint      `gorm:"not null" json:"category_id"`
+	Price       float64   `gorm:"not null;index" json:"price"`
+	CostPrice   float64   `gorm:"type:decimal(12,2);default:0" json:"cost_price,omitempty"` // Harga modal default (hanya untuk admin)
+	Supplier    string    `gorm:"size:50" json:"supplier,omitempty"`                        // Supplier aktif untuk harga modal
+	Operator    string    `gorm:"size:50;index" json:"operator"`                            // Mis. Telkomsel, Indosat, XL
+	Stock       int       `gorm:"default:0;index" json:"stock"`
+	CategoryID  uint      `gorm:"not null;index" json:"category_id"`
+	Version     uint      `gorm:"not null;default:1" json:"version"` // Naik setiap kali produk diubah, dipakai untuk ETag/If-Match
 	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
 	Category    Category  `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
 	ImageURL    string    `gorm:"size:255"`
+	// Thumbnails - URL gambar kecil per ukuran (mis. "sm", "md"), dibuat saat gambar diunggah
+	Thumbnails map[string]string `gorm:"serializer:json;type:text" json:"thumbnails,omitempty"`
+	// IsVoucher - Produk dijual dari stok kode voucher yang diimpor, stok mengikuti sisa kode
+	IsVoucher bool `gorm:"default:false" json:"is_voucher"`
+	// InputSchema - Daftar field yang wajib/boleh diisi pelanggan saat membeli produk ini
+	InputSchema []InputField `gorm:"serializer:json;type:text" json:"input_schema"`
+	// IsActive - Produk nonaktif tidak tampil dan tidak bisa dibeli oleh user
+	IsActive bool `gorm:"default:true;index" json:"is_active"`
+	// LowStockThreshold - Alert dikirim saat stok turun sampai angka ini, 0 berarti tanpa alert
+	LowStockThreshold int `gorm:"default:0" json:"low_stock_threshold"`
+	// Available - Status saat ini (aktif dan tidak sedang maintenance), dihitung saat dibaca
+	Available bool `gorm:"-" json:"available"`
+	// UnavailableUntil - Akhir maintenance yang sedang berlangsung
+	UnavailableUntil *time.Time `gorm:"-" json:"unavailable_until,omitempty"`
+}
+
+// ProductFilter - Parameter pencarian, filter, sort dan pagination daftar produk
+type ProductFilter struct {
+	Keyword    string   `form:"q"` // Dicari pada nama dan deskripsi
+	CategoryID uint     `form:"category_id"`
+	Operator   string   `form:"operator"`
+	MinPrice   *float64 `form:"min_price"` // Berdasarkan harga dasar produk
+	MaxPrice   *float64 `form:"max_price"`
+	InStock    *bool    `form:"in_stock"`  // true = stok > 0, false = stok habis
+	IsActive   *bool    `form:"is_active"` // Selalu true untuk non-admin
+	Sort       string   `form:"sort"`      // name, -name, price, -price, stock, -stock, created_at, -created_at
+	Page       int      `form:"page"`
+	Limit      int      `form:"limit"`
+}
+
+// Tipe field yang didukung oleh InputField
+const (
+	InputFieldText   = "text"
+	InputFieldNumber = "number"
+	InputFieldPhone  = "phone"
+	InputFieldEmail  = "email"
+)
+
+// InputField - Definisi satu field input pelanggan (mis. user ID game, zone ID, akun e-wallet)
+type InputField struct {
+	Name      string `json:"name"`                 // Key yang dikirim client di customer_inputs
+	Label     string `json:"label"`                // Label yang ditampilkan di frontend
+	Type      string `json:"type"`                 // text / number / phone / email
+	Pattern   string `json:"pattern,omitempty"`    // Regex opsional untuk validasi nilai
+	Required  bool   `json:"required"`             // Wajib diisi atau tidak
+	MinLength int    `json:"min_length,omitempty"` // Panjang minimum (0 = tidak dibatasi)
+	MaxLength int    `json:"max_length,omitempty"` // Panjang maksimum (0 = tidak dibatasi)
 }
 
 type Category struct {
-	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
-	Name        string    `gorm:"unique;not null" json:"name"`
-	Description string    `gorm:"type:text" json:"description"`
-	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+	ID          uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name        string `gorm:"unique;not null" json:"name"`
+	Description string `gorm:"type:text" json:"description"`
+	// ParentID - Kategori induk, nil berarti kategori tingkat atas (mis. Pulsa > Telkomsel > Reguler)
+	ParentID  *uint      `gorm:"index" json:"parent_id"`
+	Children  []Category `gorm:"-" json:"children,omitempty"` // Diisi hanya pada respons tree
+	Version   uint       `gorm:"not null;default:1" json:"version"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+	// DeletedAt - Diisi saat kategori dihapus dengan policy deactivate, baris tetap ada agar join laporan tidak putus
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// Policy penghapusan kategori yang masih memiliki produk atau sub-kategori
+const (
+	CategoryDeleteMove       = "move"       // Pindahkan produk ke kategori lain
+	CategoryDeleteDeactivate = "deactivate" // Nonaktifkan produk, kategori di-soft delete
+)
+
+// CategoryDeleteResult - Hasil penghapusan kategori
+type CategoryDeleteResult struct {
+	CategoryID              uint   `json:"category_id"`
+	Policy                  string `json:"policy,omitempty"`
+	TargetCategoryID        *uint  `json:"target_category_id,omitempty"`
+	ProductCount            int64  `json:"product_count"`
+	SubcategoryCount        int64  `json:"subcategory_count"`
+	ProductsMoved           int64  `json:"products_moved"`
+	ProductsDeactivated     int64  `json:"products_deactivated"`
+	SubcategoriesReparented int64  `json:"subcategories_reparented"`
+}
+
+// ProductImageResult - URL gambar produk setelah diunggah
+type ProductImageResult struct {
+	ImageURL   string            `json:"image_url"`
+	Thumbnails map[string]string `json:"thumbnails"`
 }
@@ -0,0 +1,45 @@
+package entity
+
+// Aksi per baris impor produk
+const (
+	ImportActionCreate    = "create"
+	ImportActionUpdate    = "update"
+	ImportActionUnchanged = "unchanged"
+)
+
+// ProductImportColumns - Urutan kolom file impor/ekspor katalog produk
+var ProductImportColumns = []string{
+	"code", "name", "category", "operator", "price", "cost_price", "supplier", "stock", "is_active", "description",
+}
+
+// FieldChange - Nilai lama dan baru satu kolom pada baris yang diperbarui
+type FieldChange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// ProductImportRow - Hasil satu baris yang valid
+type ProductImportRow struct {
+	Row     int                    `json:"row"`
+	Code    string                 `json:"code"`
+	Name    string                 `json:"name"`
+	Action  string                 `json:"action"`
+	Changes map[string]FieldChange `json:"changes,omitempty"` // Hanya untuk update
+}
+
+// ProductImportError - Baris yang tidak valid beserta alasannya
+type ProductImportError struct {
+	Row   int    `json:"row"`
+	Code  string `json:"code,omitempty"`
+	Error string `json:"error"`
+}
+
+// ProductImportResult - Diff hasil impor (atau rencana impor saat dry run)
+type ProductImportResult struct {
+	DryRun            bool                 `json:"dry_run"`
+	Created           []ProductImportRow   `json:"created"`
+	Updated           []ProductImportRow   `json:"updated"`
+	Unchanged         int                  `json:"unchanged"`
+	Invalid           []ProductImportError `json:"invalid"`
+	CategoriesCreated []string             `json:"categories_created"`
+}
@@ -0,0 +1,51 @@
+package entity
+
+import "time"
+
+// Tipe diskon promo
+const (
+	DiscountTypePercentage = "percentage"
+	DiscountTypeFixed      = "fixed"
+)
+
+// Status pemakaian promo
+const (
+	PromotionUsageReserved = "reserved" // Sudah dipotong kuotanya, transaksi belum tersimpan
+	PromotionUsageApplied  = "applied"
+	PromotionUsageReversed = "reversed" // Transaksi gagal/refund, kuota dikembalikan
+)
+
+// Promotion - Kode promo / voucher diskon untuk checkout
+type Promotion struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	Code          string    `gorm:"size:32;unique;not null" json:"code"`
+	Name          string    `gorm:"size:100;not null" json:"name"`
+	Description   string    `gorm:"type:text" json:"description"`
+	DiscountType  string    `gorm:"size:20;not null" json:"discount_type"` // percentage / fixed
+	DiscountValue float64   `gorm:"type:decimal(12,2);not null" json:"discount_value"`
+	MaxDiscount   float64   `gorm:"type:decimal(12,2);default:0" json:"max_discount"` // 0 = tanpa batas
+	MinPurchase   float64   `gorm:"type:decimal(12,2);default:0" json:"min_purchase"`
+	StartAt       time.Time `gorm:"not null" json:"start_at"`
+	EndAt         time.Time `gorm:"not null" json:"end_at"`
+	GlobalLimit   int       `gorm:"default:0" json:"global_limit"`   // 0 = tanpa batas
+	PerUserLimit  int       `gorm:"default:0" json:"per_user_limit"` // 0 = tanpa batas
+	UsedCount     int       `gorm:"default:0" json:"used_count"`
+	ProductIDs    []uint    `gorm:"serializer:json;type:text" json:"product_ids"`  // Kosong = semua produk
+	CategoryIDs   []uint    `gorm:"serializer:json;type:text" json:"category_ids"` // Kosong = semua kategori
+	UserID        *uint     `gorm:"index" json:"user_id,omitempty"`                // Promo pribadi (mis. hasil tukar poin)
+	IsActive      bool      `gorm:"default:true" json:"is_active"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// PromotionUsage - Jejak pemakaian promo per transaksi
+type PromotionUsage struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	PromotionID   uint      `gorm:"not null;index" json:"promotion_id"`
+	UserID        uint      `gorm:"not null;index" json:"user_id"`
+	TransactionID *uint     `gorm:"uniqueIndex" json:"transaction_id,omitempty"`
+	Discount      float64   `gorm:"type:decimal(12,2);not null" json:"discount"`
+	Status        string    `gorm:"size:20;not null" json:"status"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
@@ -0,0 +1,71 @@
+package entity
+
+import "time"
+
+// Status komisi
+const (
+	CommissionAccrued  = "accrued"
+	CommissionReversed = "reversed"
+)
+
+// CommissionLevel - Persentase komisi untuk upline pada kedalaman tertentu (1 = upline langsung)
+type CommissionLevel struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	Level      int       `gorm:"uniqueIndex;not null" json:"level"`
+	Percentage float64   `gorm:"type:decimal(5,2);not null" json:"percentage"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Commission - Komisi yang diterima upline dari transaksi sukses downline
+type Commission struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	UplineID      uint      `gorm:"not null;index;uniqueIndex:idx_commission_transaction_upline" json:"upline_id"`
+	FromUserID    uint      `gorm:"not null;index" json:"from_user_id"`
+	TransactionID uint      `gorm:"not null;uniqueIndex:idx_commission_transaction_upline" json:"transaction_id"`
+	Level         int       `gorm:"not null" json:"level"`
+	BaseAmount    float64   `gorm:"type:decimal(12,2);not null" json:"base_amount"`
+	Percentage    float64   `gorm:"type:decimal(5,2);not null" json:"percentage"`
+	Amount        float64   `gorm:"type:decimal(12,2);not null" json:"amount"`
+	Status        string    `gorm:"size:20;not null;index" json:"status"` // accrued / reversed
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// DownlineInfo - Ringkasan downline langsung seorang user
+type DownlineInfo struct {
+	ID        uint      `json:"id"`
+	FullName  string    `json:"full_name"`
+	JoinedAt  time.Time `json:"joined_at"`
+	Downlines int64     `json:"downlines"` // Jumlah downline milik downline ini
+}
+
+// ReferralInfo - Kode referral, upline dan downline langsung user
+type ReferralInfo struct {
+	ReferralCode string         `json:"referral_code"`
+	UplineID     *uint          `json:"upline_id,omitempty"`
+	Downlines    []DownlineInfo `json:"downlines"`
+}
+
+// CommissionFilters - Filter laporan komisi
+type CommissionFilters struct {
+	StartDate string `form:"start_date"`
+	EndDate   string `form:"end_date"`
+	UplineID  uint   `form:"upline_id"`
+	Status    string `form:"status"`
+}
+
+// CommissionLevelSummary - Total komisi per level
+type CommissionLevelSummary struct {
+	Level        int     `json:"level"`
+	Transactions int64   `json:"transactions"`
+	Amount       float64 `json:"amount"`
+}
+
+// CommissionReport - Laporan komisi beserta rinciannya
+type CommissionReport struct {
+	TotalAccrued  float64                  `json:"total_accrued"`
+	TotalReversed float64                  `json:"total_reversed"`
+	ByLevel       []CommissionLevelSummary `json:"by_level"`
+	Commissions   []Commission             `json:"commissions"`
+}
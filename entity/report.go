@@ -25,12 +25,25 @@ type TransactionSummary struct {
 	UserID          uint    `json:"user_id"`   // Digunakan di repository
 	UserName        string  `json:"user_name"` // Digunakan di repository
 	ProductName     string  `json:"product_name"`
+	CategoryID      uint    `json:"category_id"`
 	CategoryName    string  `json:"category_name"`
+	TopCategoryName string  `json:"top_category_name"` // Kategori tingkat atas dari CategoryName
 	Quantity        int     `json:"quantity"`
 	TotalPrice      float64 `json:"total_price"`
+	Margin          float64 `json:"margin"`
 	TransactionDate string  `json:"transaction_date"`
 }
 
+// CategoryRollup - Ringkasan transaksi per kategori tingkat atas (termasuk semua sub-kategorinya)
+type CategoryRollup struct {
+	CategoryID       uint    `json:"category_id"`
+	CategoryName     string  `json:"category_name"`
+	TransactionCount int     `json:"transaction_count"`
+	Quantity         int     `json:"quantity"`
+	TotalPrice       float64 `json:"total_price"`
+	Margin           float64 `json:"margin"`
+}
+
 type ReportFilters struct {
 	StartDate   string `json:"start_date" binding:"required"`
 	EndDate     string `json:"end_date" binding:"required"`
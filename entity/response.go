@@ -6,10 +6,15 @@ import "time"
 type TransactionResponse struct {
 	ID                uint                      `json:"id"`
 	UserID            uint                      `json:"user_id"`
+	ReferenceID       *string                   `json:"reference_id,omitempty"`
 	DestinationNumber string                    `json:"destination_number"`
 	TotalPrice        float64                   `json:"total_price"`
+	PromoCode         string                    `json:"promo_code,omitempty"`
+	DiscountAmount    float64                   `json:"discount_amount"`
 	Status            string                    `json:"status"`
 	SerialNumber      string                    `json:"serial_number"` // Tambahkan ini
+	CustomerInputs    map[string]string         `json:"customer_inputs,omitempty"`
+	Version           uint                      `json:"version"`
 	CreatedAt         time.Time                 `json:"created_at"`
 	UpdatedAt         time.Time                 `json:"updated_at"`
 	User              UserSafeResponse          `json:"user"`
@@ -34,6 +39,7 @@ type TransactionItemResponse struct {
 type ProductResponse struct {
 	ID          uint             `json:"id"`
 	Name        string           `json:"name"`
+	Code        *string          `json:"code,omitempty"`
 	Description string           `json:"description"`
 	Price       float64          `json:"price"`
 	Stock       int              `json:"stock"`
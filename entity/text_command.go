@@ -0,0 +1,58 @@
+package entity
+
+import "time"
+
+// Aksi yang dapat dijalankan dari perintah teks
+const (
+	CommandPurchase = "purchase"
+	CommandBalance  = "balance"
+	CommandPrice    = "price"
+	CommandStatus   = "status"
+)
+
+// Kunci template balasan
+const (
+	ReplyPurchaseAccepted = "purchase_accepted"
+	ReplyBalance          = "balance"
+	ReplyPrice            = "price"
+	ReplyStatus           = "status"
+	ReplyError            = "error"
+	ReplyUnknownCommand   = "unknown_command"
+	ReplyUnregistered     = "unregistered"
+)
+
+// CommandTemplate - Format perintah teks, mis. "{product}.{destination}.{pin}".
+// Placeholder: {product}, {destination}, {pin}, {ref}, {qty}; teks lain harus sama persis (tidak peka huruf besar/kecil).
+type CommandTemplate struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Name      string    `gorm:"size:100;not null" json:"name"`
+	Action    string    `gorm:"size:20;not null" json:"action"` // purchase / balance / price / status
+	Pattern   string    `gorm:"size:255;not null" json:"pattern"`
+	Priority  int       `gorm:"default:0" json:"priority"` // Dicoba dari prioritas tertinggi
+	IsActive  bool      `gorm:"default:true" json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ReplyTemplate - Teks balasan dengan placeholder seperti {trx_id}, {product}, {price}, {balance}, {status}, {message}
+type ReplyTemplate struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Key       string    `gorm:"size:50;uniqueIndex;not null" json:"key"`
+	Template  string    `gorm:"type:text;not null" json:"template"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// GatewayMessage - Pesan masuk dari gateway chat lokal (SMS/Telegram/Jabber)
+type GatewayMessage struct {
+	Sender  string `json:"sender" binding:"required"` // Nomor telepon pengirim yang terdaftar
+	Message string `json:"message" binding:"required"`
+	Channel string `json:"channel"`
+}
+
+// GatewayReply - Balasan yang harus dikirim gateway ke pengirim
+type GatewayReply struct {
+	To     string `json:"to"`
+	Reply  string `json:"reply"`
+	Action string `json:"action,omitempty"`
+}
@@ -5,11 +5,16 @@ import "time"
 // Transaction struct untuk merepresentasikan transaksi
 type Transaction struct {
 	ID                uint              `gorm:"primaryKey" json:"id"`
-	UserID            uint              `gorm:"not null" json:"user_id"`
+	UserID            uint              `gorm:"not null;uniqueIndex:idx_transaction_user_reference" json:"user_id"`
+	ReferenceID       *string           `gorm:"size:64;uniqueIndex:idx_transaction_user_reference" json:"reference_id,omitempty"` // ID transaksi milik reseller (H2H)
 	DestinationNumber string            `gorm:"size:15" json:"destination_number"`
-	TotalPrice        float64           `gorm:"type:decimal(10,2)" json:"total_price"`
+	TotalPrice        float64           `gorm:"type:decimal(10,2)" json:"total_price"` // Sudah dikurangi diskon
+	PromoCode         string            `gorm:"size:32;index" json:"promo_code,omitempty"`
+	DiscountAmount    float64           `gorm:"type:decimal(10,2);default:0" json:"discount_amount"`
 	Status            string            `gorm:"size:20;default:'pending'" json:"status"`
-	SerialNumber      string            `gorm:"size:50" json:"serial_number"` // Tambahkan ini
+	SerialNumber      string            `gorm:"type:text" json:"serial_number"` // Nomor seri supplier atau kode voucher
+	CustomerInputs    map[string]string `gorm:"serializer:json;type:text" json:"customer_inputs,omitempty"`
+	Version           uint              `gorm:"not null;default:1" json:"version"` // Naik setiap kali transaksi diubah
 	CreatedAt         time.Time         `json:"created_at"`
 	UpdatedAt         time.Time         `json:"updated_at"`
 	User              User              `gorm:"foreignKey:UserID" json:"user,omitempty"`
@@ -23,6 +28,9 @@ type TransactionItem struct {
 	ProductID     uint      `gorm:"not null" json:"product_id"`
 	Quantity      int       `gorm:"not null" json:"quantity"`
 	Price         float64   `gorm:"type:decimal(10,2);not null" json:"price"`
+	CostPrice     float64   `gorm:"type:decimal(10,2);default:0" json:"cost_price"`
+	Discount      float64   `gorm:"type:decimal(10,2);default:0" json:"discount"` // Bagian diskon promo untuk item ini
+	Margin        float64   `gorm:"type:decimal(10,2);default:0" json:"margin"`   // (Price - CostPrice) * Quantity - Discount
 	CreatedAt     time.Time `json:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at"`
 	Product       Product   `gorm:"foreignKey:ProductID" json:"product,omitempty"`
@@ -32,13 +40,19 @@ type TransactionItem struct {
 type TransactionRequest struct {
 	UserID            uint                     `json:"user_id"`
 	DestinationNumber string                   `json:"destination_number"` // Nomor tujuan transaksi
+	FavoriteID        uint                     `json:"favorite_id"`        // Opsional, pengganti destination_number
+	CustomerInputs    map[string]string        `json:"customer_inputs"`    // Nilai input sesuai InputSchema produk
+	PromoCode         string                   `json:"promo_code"`         // Kode promo opsional
+	ReferenceID       string                   `json:"reference_id"`       // Opsional, unik per user
+	Pin               string                   `json:"pin"`                // PIN transaksi, wajib
 	Items             []TransactionItemRequest `json:"items"`
 }
 
 // TransactionItemRequest struct untuk menerima item dalam request transaksi
 type TransactionItemRequest struct {
-	ProductID uint `json:"product_id"`
-	Quantity  int  `json:"quantity"`
+	ProductID   uint   `json:"product_id"`
+	ProductCode string `json:"product_code,omitempty"` // Alternatif product_id
+	Quantity    int    `json:"quantity"`
 }
 
 // TransactionStatusRequest struct untuk menerima request perubahan status transaksi
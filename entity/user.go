@@ -3,13 +3,20 @@ package entity
 import "time"
 
 type User struct {
-	ID          uint      `gorm:"primaryKey" json:"id"`
-	FullName    string    `gorm:"not null" json:"full_name"`
-	PhoneNumber string    `gorm:"unique;not null" json:"phone_number"`
-	Email       string    `gorm:"unique" json:"email,omitempty"` // Email opsional
-	Password    string    `gorm:"type:varchar(255);not null" json:"password"`
-	Address     string    `json:"address"`
-	Role        string    `gorm:"size:20;not null" json:"role"` // user / administrator
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID                uint       `gorm:"primaryKey" json:"id"`
+	FullName          string     `gorm:"not null" json:"full_name"`
+	PhoneNumber       string     `gorm:"unique;not null" json:"phone_number"`
+	Email             string     `gorm:"unique" json:"email,omitempty"` // Email opsional
+	Password          string     `gorm:"type:varchar(255);not null" json:"password"`
+	Address           string     `json:"address"`
+	Role              string     `gorm:"size:20;not null" json:"role"` // user / administrator
+	PriceGroupID      *uint      `gorm:"index" json:"price_group_id,omitempty"`
+	Balance           float64    `gorm:"type:decimal(14,2);default:0" json:"balance"` // Saldo dari cashback/penukaran poin
+	ReferralCode      *string    `gorm:"size:16;uniqueIndex" json:"referral_code,omitempty"`
+	UplineID          *uint      `gorm:"index" json:"upline_id,omitempty"` // User yang mereferensikan
+	Pin               string     `gorm:"type:varchar(255)" json:"-"`       // Hash bcrypt PIN transaksi
+	PinFailedAttempts int        `gorm:"default:0" json:"-"`
+	PinLockedUntil    *time.Time `json:"-"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
 }
@@ -0,0 +1,52 @@
+package entity
+
+import "time"
+
+// Status kode voucher
+const (
+	VoucherCodeAvailable = "available"
+	VoucherCodeAssigned  = "assigned"
+)
+
+// Versi CodeHash/MaskedCode kode voucher. Baris lama (SHA-256 tanpa kunci, masked 8 karakter) bernilai 1
+// dan dimigrasikan sekali ke versi terbaru saat startup.
+const (
+	VoucherHashLegacy = 1
+	VoucherHashHMAC   = 2
+)
+
+// VoucherBatch - Satu kali impor kode voucher untuk sebuah produk
+type VoucherBatch struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	ProductID  uint      `gorm:"not null;index" json:"product_id"`
+	Name       string    `gorm:"size:100" json:"name"`
+	TotalCodes int       `gorm:"not null" json:"total_codes"`
+	Duplicates int       `gorm:"not null;default:0" json:"duplicates"` // Baris yang dilewati karena kode sudah ada
+	ImportedBy uint      `gorm:"not null" json:"imported_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// VoucherCode - Kode voucher yang tersimpan terenkripsi, beserta jejak penerimanya
+type VoucherCode struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	BatchID       uint       `gorm:"not null;index" json:"batch_id"`
+	ProductID     uint       `gorm:"not null;index:idx_voucher_product_status" json:"product_id"`
+	Code          string     `gorm:"type:text;not null" json:"-"`           // Ciphertext (AES-GCM, base64)
+	CodeHash      string     `gorm:"size:64;uniqueIndex;not null" json:"-"` // HMAC-SHA256 untuk deteksi duplikat
+	HashVersion   int        `gorm:"not null;default:1;index" json:"-"`
+	MaskedCode    string     `gorm:"size:50" json:"masked_code"` // Mis. "****WXYZ", aman untuk ditampilkan
+	Status        string     `gorm:"size:20;not null;default:'available';index:idx_voucher_product_status" json:"status"`
+	TransactionID *uint      `gorm:"index" json:"transaction_id,omitempty"`
+	UserID        *uint      `gorm:"index" json:"user_id,omitempty"`
+	AssignedAt    *time.Time `json:"assigned_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// VoucherImportResult - Ringkasan hasil impor batch kode voucher
+type VoucherImportResult struct {
+	Batch     VoucherBatch `json:"batch"`
+	Imported  int          `json:"imported"`
+	Skipped   int          `json:"skipped"`
+	Available int64        `json:"available"`
+}
@@ -5,10 +5,12 @@ import (
 	"go.uber.org/zap"
 	"main.go/config"
 	"main.go/controller"
+	"main.go/entity"
 	"main.go/middleware"
 	"main.go/repository"
 	"main.go/service"
 	"net/http"
+	"os"
 	"time"
 )
 
@@ -24,20 +26,74 @@ func main() {
 	}
 	middleware.Logger.Info("Database berhasil diinisialisasi")
 
+	// Inisialisasi storage untuk gambar produk dan file laporan
+	if err := config.InitStorage(); err != nil {
+		middleware.Logger.Fatal("Gagal menginisialisasi storage", zap.Error(err))
+	}
+
+	// Inisialisasi cache katalog produk dan kategori
+	if err := config.InitCache(); err != nil {
+		middleware.Logger.Fatal("Gagal menginisialisasi cache", zap.Error(err))
+	}
+
 	// Inisialisasi Repository
 	userRepo := repository.NewUserRepository(config.DB)
-	productRepo := repository.NewProductRepository(config.DB)
+	productRepo := repository.NewCachedProductRepository(repository.NewProductRepository(config.DB), config.CacheStore, config.CatalogCacheTTL)
 	transactionRepo := repository.NewTransactionsRepository(config.DB)
 	activityLogRepo := repository.NewActivityLogRepository(config.DB)
 	reportRepo := repository.NewReportRepository(config.DB)
 	tokenRepo := repository.NewTokenRepository(config.DB)
+	voucherRepo := repository.NewVoucherRepository(config.DB)
+	pricingRepo := repository.NewPricingRepository(config.DB, productRepo)
+	priceGroupRepo := repository.NewPriceGroupRepository(config.DB)
+	promotionRepo := repository.NewPromotionRepository(config.DB)
+	loyaltyRepo := repository.NewLoyaltyRepository(config.DB)
+	referralRepo := repository.NewReferralRepository(config.DB)
+	apiKeyRepo := repository.NewAPIKeyRepository(config.DB)
+	textCommandRepo := repository.NewTextCommandRepository(config.DB)
+	cartRepo := repository.NewCartRepository(config.DB)
+	favoriteRepo := repository.NewFavoriteRepository(config.DB)
+	maintenanceRepo := repository.NewMaintenanceRepository(config.DB)
+	priceScheduleRepo := repository.NewPriceScheduleRepository(config.DB, productRepo)
+	inventoryRepo := repository.NewInventoryRepository(config.DB, productRepo)
 
 	// Inisialisasi Service
-	userService := service.NewUserService(userRepo, tokenRepo)
-	productService := service.NewProductService(productRepo)
 	activityLogService := service.NewActivityLogService(activityLogRepo)
-	transactionService := service.NewTransactionsService(transactionRepo, productRepo, activityLogService)
-	reportService := service.NewReportService(reportRepo) // Pastikan ini digunakan
+	userService := service.NewUserService(userRepo, tokenRepo, activityLogService)
+	priceGroupService := service.NewPriceGroupService(priceGroupRepo, userRepo, productRepo)
+	maintenanceService := service.NewMaintenanceService(maintenanceRepo, productRepo)
+	priceScheduleService := service.NewPriceScheduleService(priceScheduleRepo, productRepo, activityLogService)
+	productService := service.NewProductService(productRepo, priceGroupService, maintenanceService, priceScheduleService, activityLogService)
+	inventoryService := service.NewInventoryService(inventoryRepo, productRepo, activityLogService)
+	if webhookURL := os.Getenv("STOCK_ALERT_WEBHOOK_URL"); webhookURL != "" {
+		inventoryService.RegisterAlertHook(service.NewWebhookStockAlertHook(webhookURL))
+	}
+	productImportService := service.NewProductImportService(productRepo, activityLogService, priceScheduleService, inventoryService)
+	productImageService := service.NewProductImageService(productRepo, config.Storage)
+	voucherService := service.NewVoucherService(voucherRepo, productRepo, inventoryService, activityLogService)
+	// Migrasi sekali jalan untuk hash kode voucher lama; kode yang gagal tidak menghentikan startup
+	if migrated, failed, err := voucherService.MigrateCodes(); err != nil {
+		middleware.Logger.Error("Gagal memigrasikan hash kode voucher", zap.Int("migrated", migrated), zap.Error(err))
+	} else if migrated > 0 || failed > 0 {
+		middleware.Logger.Info("Hash kode voucher dimigrasikan", zap.Int("migrated", migrated), zap.Int("failed", failed))
+	}
+	pricingService := service.NewPricingService(pricingRepo, productRepo)
+	promotionService := service.NewPromotionService(promotionRepo, activityLogService)
+	favoriteService := service.NewFavoriteService(favoriteRepo)
+	transactionService := service.NewTransactionsService(transactionRepo, productRepo, activityLogService, voucherService, pricingService, priceGroupService, promotionService, userService, favoriteService, maintenanceService, inventoryService)
+	loyaltyService := service.NewLoyaltyService(loyaltyRepo, userRepo, productRepo, promotionService, activityLogService)
+	transactionService.RegisterHook(loyaltyService)
+	referralService := service.NewReferralService(referralRepo, userRepo, activityLogService)
+	transactionService.RegisterHook(referralService)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, activityLogService)
+	h2hService := service.NewH2HService(transactionService, userRepo)
+	cartService := service.NewCartService(cartRepo, productRepo, priceGroupService, transactionService)
+	textCommandService := service.NewTextCommandService(textCommandRepo, userRepo, productRepo, priceGroupService, transactionService, activityLogService, userService)
+	reportService := service.NewReportService(reportRepo, config.Storage) // Pastikan ini digunakan
+	cacheService := service.NewCacheService(productRepo, activityLogService)
+
+	// Worker background untuk menerapkan jadwal harga yang jatuh tempo
+	priceScheduleService.StartWorker()
 
 	// Inisialisasi Controller
 	userController := controller.NewUserController(userService)
@@ -45,6 +101,24 @@ func main() {
 	transactionController := controller.NewTransactionsController(transactionService)
 	reportController := controller.NewReportController(reportService) // Pastikan ini digunakan
 	callbackController := controller.NewCallbackController(transactionService)
+	voucherController := controller.NewVoucherController(voucherService)
+	pricingController := controller.NewPricingController(pricingService)
+	priceGroupController := controller.NewPriceGroupController(priceGroupService)
+	promotionController := controller.NewPromotionController(promotionService)
+	loyaltyController := controller.NewLoyaltyController(loyaltyService)
+	referralController := controller.NewReferralController(referralService)
+	apiKeyController := controller.NewAPIKeyController(apiKeyService)
+	h2hController := controller.NewH2HController(h2hService)
+	textCommandController := controller.NewTextCommandController(textCommandService)
+	cartController := controller.NewCartController(cartService)
+	favoriteController := controller.NewFavoriteController(favoriteService)
+	maintenanceController := controller.NewMaintenanceController(maintenanceService)
+	productImportController := controller.NewProductImportController(productImportService)
+	priceScheduleController := controller.NewPriceScheduleController(priceScheduleService)
+	productImageController := controller.NewProductImageController(productImageService)
+	inventoryController := controller.NewInventoryController(inventoryService)
+	cacheController := controller.NewCacheController(cacheService)
+	fileController := controller.NewFileController(config.Storage)
 
 	// Membuat router Gin
 	r := gin.Default()
@@ -59,6 +133,17 @@ func main() {
 	// Routes untuk Callback Simulasi
 	r.POST("/callback/transaction-status", callbackController.CallbackTransactionStatus)
 
+	// Route untuk gateway perintah teks (SMS/Telegram/Jabber)
+	r.POST("/gateway/message", middleware.AuthorizeGatewayToken, textCommandController.HandleGatewayMessage)
+
+	// Gambar produk publik, nama file berbasis hash sehingga boleh di-cache selamanya
+	uploadRoutes := r.Group(config.LocalPublicURLPrefix)
+	uploadRoutes.Use(middleware.StaticCacheHeaders(365 * 24 * time.Hour))
+	uploadRoutes.GET("/*key", fileController.ServePublic)
+
+	// Unduhan lewat URL bertandatangan (storage lokal)
+	r.GET(config.LocalSignedURLPrefix+"/*key", fileController.ServeSigned)
+
 	// Routes untuk Autentikasi
 	authRoutes := r.Group("/auth")
 	{
@@ -82,12 +167,94 @@ func main() {
 			// CRUD Categories
 			adminRoutes.POST("/categories", productController.CreateCategory)
 			adminRoutes.PUT("/categories/:id", productController.UpdateCategory)
+			adminRoutes.PATCH("/categories/:id", productController.PatchCategory)
 			adminRoutes.DELETE("/categories/:id", productController.DeleteCategory)
 
 			// CRUD Products
 			adminRoutes.POST("/products", productController.CreateProduct)
 			adminRoutes.PUT("/products/:id", productController.UpdateProduct)
+			adminRoutes.PATCH("/products/:id", productController.PatchProduct)
 			adminRoutes.DELETE("/products/:id", productController.DeleteProduct)
+			adminRoutes.POST("/products/:id/image", productImageController.UploadProductImage)
+			adminRoutes.POST("/products/import", productImportController.ImportProducts)
+			adminRoutes.GET("/products/export", productImportController.ExportProducts)
+			adminRoutes.GET("/products/:id/prices", priceScheduleController.GetProductPrices)
+
+			// Ledger inventori dan stok minimum
+			adminRoutes.GET("/products/:id/inventory", inventoryController.GetMovements)
+			adminRoutes.POST("/products/:id/inventory", inventoryController.AdjustStock)
+			adminRoutes.GET("/inventory/low-stock", inventoryController.GetLowStockProducts)
+
+			// Cache katalog
+			adminRoutes.GET("/cache/stats", cacheController.GetCacheStats)
+			adminRoutes.DELETE("/cache", cacheController.FlushCache)
+
+			// Jadwal perubahan harga
+			adminRoutes.GET("/price-schedules", priceScheduleController.GetPriceSchedules)
+			adminRoutes.POST("/price-schedules", priceScheduleController.CreatePriceSchedule)
+			adminRoutes.DELETE("/price-schedules/:id", priceScheduleController.CancelPriceSchedule)
+
+			// Voucher Code Inventory
+			adminRoutes.POST("/products/:id/vouchers/import", voucherController.ImportVoucherCodes)
+			adminRoutes.GET("/products/:id/vouchers/batches", voucherController.GetVoucherBatches)
+			adminRoutes.GET("/vouchers/assignments", voucherController.GetVoucherAssignments)
+
+			// Cost Price & Markup
+			adminRoutes.GET("/products/:id/costs", pricingController.GetProductCosts)
+			adminRoutes.PUT("/products/:id/costs", pricingController.SetProductCost)
+			adminRoutes.GET("/markup-rules", pricingController.GetMarkupRules)
+			adminRoutes.POST("/markup-rules", pricingController.CreateMarkupRule)
+			adminRoutes.PUT("/markup-rules/:id", pricingController.UpdateMarkupRule)
+			adminRoutes.DELETE("/markup-rules/:id", pricingController.DeleteMarkupRule)
+			adminRoutes.POST("/markup-rules/preview", pricingController.PreviewMarkup)
+			adminRoutes.POST("/markup-rules/apply", pricingController.ApplyMarkup)
+
+			// Price Groups
+			adminRoutes.GET("/price-groups", priceGroupController.GetPriceGroups)
+			adminRoutes.GET("/price-groups/:id", priceGroupController.GetPriceGroupByID)
+			adminRoutes.POST("/price-groups", priceGroupController.CreatePriceGroup)
+			adminRoutes.PUT("/price-groups/:id", priceGroupController.UpdatePriceGroup)
+			adminRoutes.DELETE("/price-groups/:id", priceGroupController.DeletePriceGroup)
+			adminRoutes.PUT("/price-groups/:id/products/:product_id", priceGroupController.SetProductPrice)
+			adminRoutes.DELETE("/price-groups/:id/products/:product_id", priceGroupController.DeleteProductPrice)
+			adminRoutes.PUT("/price-groups/:id/categories/:category_id", priceGroupController.SetCategoryRule)
+			adminRoutes.DELETE("/price-groups/:id/categories/:category_id", priceGroupController.DeleteCategoryRule)
+			adminRoutes.PUT("/users/:id/price-group", priceGroupController.AssignUserPriceGroup)
+			adminRoutes.DELETE("/users/:id/pin", userController.ClearUserPin)
+
+			// Promotions
+			adminRoutes.GET("/promotions", promotionController.GetPromotions)
+			adminRoutes.GET("/promotions/:id", promotionController.GetPromotionByID)
+			adminRoutes.POST("/promotions", promotionController.CreatePromotion)
+			adminRoutes.PUT("/promotions/:id", promotionController.UpdatePromotion)
+			adminRoutes.DELETE("/promotions/:id", promotionController.DeletePromotion)
+			adminRoutes.GET("/promotions/:id/usages", promotionController.GetPromotionUsages)
+
+			// Jadwal maintenance produk/kategori/operator
+			adminRoutes.GET("/maintenance-windows", maintenanceController.GetMaintenanceWindows)
+			adminRoutes.GET("/maintenance-windows/:id", maintenanceController.GetMaintenanceWindowByID)
+			adminRoutes.POST("/maintenance-windows", maintenanceController.CreateMaintenanceWindow)
+			adminRoutes.PUT("/maintenance-windows/:id", maintenanceController.UpdateMaintenanceWindow)
+			adminRoutes.DELETE("/maintenance-windows/:id", maintenanceController.DeleteMaintenanceWindow)
+
+			// Loyalty Rules
+			adminRoutes.GET("/loyalty/rules", loyaltyController.GetLoyaltyRules)
+			adminRoutes.POST("/loyalty/rules", loyaltyController.CreateLoyaltyRule)
+			adminRoutes.PUT("/loyalty/rules/:id", loyaltyController.UpdateLoyaltyRule)
+			adminRoutes.DELETE("/loyalty/rules/:id", loyaltyController.DeleteLoyaltyRule)
+
+			// Referral Commission Levels
+			adminRoutes.GET("/commission-levels", referralController.GetCommissionLevels)
+			adminRoutes.PUT("/commission-levels/:level", referralController.SetCommissionLevel)
+			adminRoutes.DELETE("/commission-levels/:level", referralController.DeleteCommissionLevel)
+
+			// Text Command Templates
+			adminRoutes.GET("/command-templates", textCommandController.GetCommandTemplates)
+			adminRoutes.POST("/command-templates", textCommandController.CreateCommandTemplate)
+			adminRoutes.PUT("/command-templates/:id", textCommandController.UpdateCommandTemplate)
+			adminRoutes.DELETE("/command-templates/:id", textCommandController.DeleteCommandTemplate)
+			adminRoutes.GET("/reply-templates", textCommandController.GetReplyTemplates)
+			adminRoutes.PUT("/reply-templates/:key", textCommandController.SetReplyTemplate)
 
 			// Transactions Management
 			adminRoutes.DELETE("/transactions/:id", transactionController.DeleteTransaction)
@@ -105,25 +272,67 @@ func main() {
 
 			userRoutes.GET("/products", productController.GetAllProducts)
 			userRoutes.GET("/products/:id", productController.GetProductByID)
-			userRoutes.POST("/products/:id/image", productController.UploadProductImage)
+			userRoutes.GET("/products/code/:code", productController.GetProductByCode)
 
 			// Routes untuk User Management
 			userRoutes.GET("/user", userController.GetUserDetails)
 			userRoutes.PUT("/user", userController.UpdateUser)
+			userRoutes.PATCH("/user", userController.PatchUser)
+			userRoutes.POST("/user/pin", userController.SetPin)
+			userRoutes.PUT("/user/pin", userController.ChangePin)
+			userRoutes.POST("/user/pin/reset", middleware.LimitRequest(3, 5*time.Minute), userController.ResetPin)
+
+			// Loyalty
+			userRoutes.GET("/loyalty", loyaltyController.GetLoyalty)
+			userRoutes.POST("/loyalty/redeem", loyaltyController.RedeemPoints)
+
+			// Referral & Commission
+			userRoutes.GET("/referral", referralController.GetReferral)
+			userRoutes.GET("/commissions/report", referralController.GetCommissionReport)
+
+			// API Key H2H
+			userRoutes.GET("/api-keys", apiKeyController.GetAPIKeys)
+			userRoutes.POST("/api-keys", apiKeyController.CreateAPIKey)
+			userRoutes.DELETE("/api-keys/:id", apiKeyController.RevokeAPIKey)
 
 			// Routes untuk Transactions
 			userRoutes.POST("/transactions", transactionController.CreateTransaction)
 			userRoutes.GET("/transactions/:id", transactionController.GetTransactionByID)
 			userRoutes.GET("/users/:user_id/transactions", transactionController.GetTransactionByUserID)
 
+			// Routes untuk Cart
+			userRoutes.GET("/cart", cartController.GetCart)
+			userRoutes.POST("/cart/items", cartController.AddCartItem)
+			userRoutes.PUT("/cart/items/:product_id", cartController.UpdateCartItem)
+			userRoutes.DELETE("/cart/items/:product_id", cartController.RemoveCartItem)
+			userRoutes.DELETE("/cart", cartController.ClearCart)
+			userRoutes.POST("/cart/checkout", cartController.CheckoutCart)
+
+			// Routes untuk Favorites
+			userRoutes.GET("/favorites", favoriteController.GetFavorites)
+			userRoutes.GET("/favorites/suggestions", favoriteController.GetFavoriteSuggestions)
+			userRoutes.POST("/favorites", favoriteController.CreateFavorite)
+			userRoutes.PUT("/favorites/:id", favoriteController.UpdateFavorite)
+			userRoutes.DELETE("/favorites/:id", favoriteController.DeleteFavorite)
+
 			// Reports Management
 			userRoutes.POST("/reports/generate", reportController.GenerateReport)
 			userRoutes.GET("/reports/download", reportController.DownloadReport)
+			userRoutes.GET("/reports/categories", reportController.GetCategoryReport)
 		}
 	}
 
 	middleware.Logger.Info("Routes yang dilindungi JWT berhasil didaftarkan")
 
+	// Routes host-to-host untuk server reseller, diautentikasi dengan API key + HMAC
+	h2hRoutes := r.Group("/h2h")
+	h2hRoutes.Use(middleware.AuthorizeAPIKey(apiKeyService))
+	{
+		h2hRoutes.POST("/purchase", middleware.RequireScope(entity.ScopePurchase), h2hController.Purchase)
+		h2hRoutes.GET("/status/:ref_id", middleware.RequireScope(entity.ScopeStatus), h2hController.Status)
+		h2hRoutes.GET("/balance", middleware.RequireScope(entity.ScopeBalance), h2hController.Balance)
+	}
+
 	// Endpoint debugging untuk mencetak semua rute
 	r.GET("/debug/routes", func(c *gin.Context) {
 		routes := r.Routes()
@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"main.go/entity"
+)
+
+// signatureTolerance - Selisih maksimum antara X-Timestamp dan waktu server
+const signatureTolerance = 5 * time.Minute
+
+// APIKeyResolver - Sumber API key untuk middleware H2H (diimplementasikan oleh service)
+type APIKeyResolver interface {
+	// ResolveAPIKey - Mengembalikan API key aktif beserta secret plaintext-nya
+	ResolveAPIKey(keyID string) (*entity.APIKey, string, error)
+	// MarkAPIKeyUsed - Mencatat waktu terakhir API key dipakai
+	MarkAPIKeyUsed(key *entity.APIKey)
+}
+
+// AuthorizeAPIKey - Middleware autentikasi request H2H yang ditandatangani.
+// Header: X-Api-Key (key ID), X-Timestamp (unix detik), X-Signature =
+// hex(HMAC-SHA256(secret, timestamp + "\n" + METHOD + "\n" + path?query + "\n" + body)).
+func AuthorizeAPIKey(resolver APIKeyResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keyID := c.GetHeader("X-Api-Key")
+		timestamp := c.GetHeader("X-Timestamp")
+		signature := c.GetHeader("X-Signature")
+		if keyID == "" || timestamp == "" || signature == "" {
+			abortH2H(c, http.StatusUnauthorized, "X-Api-Key, X-Timestamp and X-Signature headers are required")
+			return
+		}
+
+		unix, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			abortH2H(c, http.StatusUnauthorized, "Invalid X-Timestamp")
+			return
+		}
+		if skew := time.Since(time.Unix(unix, 0)); skew > signatureTolerance || skew < -signatureTolerance {
+			abortH2H(c, http.StatusUnauthorized, "Request timestamp is outside the allowed window")
+			return
+		}
+
+		key, secret, err := resolver.ResolveAPIKey(keyID)
+		if err != nil {
+			Logger.Warn("Middleware: Unknown API key", zap.String("key_id", keyID))
+			abortH2H(c, http.StatusUnauthorized, "Invalid API key")
+			return
+		}
+
+		if !ipAllowed(c.ClientIP(), key.AllowedIPs) {
+			Logger.Warn("Middleware: API key used from disallowed IP", zap.String("key_id", keyID), zap.String("ip", c.ClientIP()))
+			abortH2H(c, http.StatusForbidden, "IP address is not allowed for this API key")
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			abortH2H(c, http.StatusBadRequest, "Failed to read request body")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(timestamp + "\n" + c.Request.Method + "\n" + c.Request.URL.RequestURI() + "\n"))
+		mac.Write(body)
+		expected := hex.EncodeToString(mac.Sum(nil))
+		if !hmac.Equal([]byte(expected), []byte(signature)) {
+			Logger.Warn("Middleware: Invalid request signature", zap.String("key_id", keyID))
+			abortH2H(c, http.StatusUnauthorized, "Invalid signature")
+			return
+		}
+
+		resolver.MarkAPIKeyUsed(key)
+
+		c.Set("user_id", key.UserID)
+		c.Set("role", key.User.Role)
+		c.Set("api_key_id", key.ID)
+		c.Set("api_scopes", key.Scopes)
+		c.Next()
+	}
+}
+
+// RequireScope - Menolak request H2H jika API key tidak memiliki scope yang dibutuhkan
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scopes, _ := c.Get("api_scopes")
+		if list, ok := scopes.([]string); ok {
+			for _, s := range list {
+				if s == scope {
+					c.Next()
+					return
+				}
+			}
+		}
+		abortH2H(c, http.StatusForbidden, "API key does not have the "+scope+" scope")
+	}
+}
+
+// ipAllowed - Daftar kosong berarti semua IP diizinkan; entri dapat berupa IP atau CIDR
+func ipAllowed(clientIP string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	ip := net.ParseIP(clientIP)
+	if ip == nil {
+		return false
+	}
+	for _, entry := range allowed {
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if network.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if allowedIP := net.ParseIP(entry); allowedIP != nil && allowedIP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// abortH2H - Error dengan bentuk response yang sama seperti endpoint H2H
+func abortH2H(c *gin.Context, status int, message string) {
+	c.AbortWithStatusJSON(status, gin.H{"success": false, "message": message})
+}
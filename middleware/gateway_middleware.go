@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuthorizeGatewayToken - Memastikan request berasal dari gateway chat lokal (header X-Gateway-Token = GATEWAY_TOKEN)
+func AuthorizeGatewayToken(c *gin.Context) {
+	expected := os.Getenv("GATEWAY_TOKEN")
+	if expected == "" {
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Text command gateway is not configured"})
+		return
+	}
+
+	token := c.GetHeader("X-Gateway-Token")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid gateway token"})
+		return
+	}
+	c.Next()
+}
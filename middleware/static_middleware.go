@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StaticCacheHeaders - Header cache untuk file statis yang namanya berbasis hash konten (isi file tidak pernah berubah)
+func StaticCacheHeaders(maxAge time.Duration) gin.HandlerFunc {
+	cacheControl := fmt.Sprintf("public, max-age=%d, immutable", int(maxAge.Seconds()))
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", cacheControl)
+		c.Header("X-Content-Type-Options", "nosniff")
+		c.Next()
+	}
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"main.go/entity"
+)
+
+type APIKeyRepository interface {
+	Create(key *entity.APIKey) error
+	GetByUser(userID uint) ([]entity.APIKey, error)
+	GetByKeyID(keyID string) (*entity.APIKey, error)
+	Revoke(userID uint, id uint) error
+	TouchLastUsed(id uint, at time.Time) error
+}
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+func (r *apiKeyRepository) Create(key *entity.APIKey) error {
+	return r.db.Create(key).Error
+}
+
+func (r *apiKeyRepository) GetByUser(userID uint) ([]entity.APIKey, error) {
+	var keys []entity.APIKey
+	if err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// GetByKeyID - Mengambil API key aktif beserta pemiliknya
+func (r *apiKeyRepository) GetByKeyID(keyID string) (*entity.APIKey, error) {
+	var key entity.APIKey
+	if err := r.db.Preload("User").Where("key_id = ? AND revoked_at IS NULL", keyID).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("api key not found")
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepository) Revoke(userID uint, id uint) error {
+	result := r.db.Model(&entity.APIKey{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("api key not found")
+	}
+	return nil
+}
+
+func (r *apiKeyRepository) TouchLastUsed(id uint, at time.Time) error {
+	return r.db.Model(&entity.APIKey{}).Where("id = ?", id).UpdateColumn("last_used_at", at).Error
+}
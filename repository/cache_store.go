@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"strings"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+)
+
+// CacheStore - Penyimpanan key-value dengan TTL untuk cache katalog. Nilai disimpan sebagai byte
+// (JSON) sehingga implementasi lain, mis. Redis yang dipakai bersama beberapa instance, bisa
+// menggantikan store in-process tanpa mengubah CachedProductRepository.
+type CacheStore interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte, ttl time.Duration)
+	Delete(keys ...string)
+	DeletePrefix(prefix string)
+	Flush()
+	ItemCount() int
+}
+
+type memoryCacheStore struct {
+	items *cache.Cache
+}
+
+// NewMemoryCacheStore - Cache in-process (go-cache), entri kedaluwarsa dibersihkan setiap cleanupInterval
+func NewMemoryCacheStore(cleanupInterval time.Duration) CacheStore {
+	return &memoryCacheStore{items: cache.New(cache.NoExpiration, cleanupInterval)}
+}
+
+func (s *memoryCacheStore) Get(key string) ([]byte, bool) {
+	value, found := s.items.Get(key)
+	if !found {
+		return nil, false
+	}
+	return value.([]byte), true
+}
+
+func (s *memoryCacheStore) Set(key string, value []byte, ttl time.Duration) {
+	s.items.Set(key, value, ttl)
+}
+
+func (s *memoryCacheStore) Delete(keys ...string) {
+	for _, key := range keys {
+		s.items.Delete(key)
+	}
+}
+
+func (s *memoryCacheStore) DeletePrefix(prefix string) {
+	for key := range s.items.Items() {
+		if strings.HasPrefix(key, prefix) {
+			s.items.Delete(key)
+		}
+	}
+}
+
+func (s *memoryCacheStore) Flush() {
+	s.items.Flush()
+}
+
+func (s *memoryCacheStore) ItemCount() int {
+	return s.items.ItemCount()
+}
@@ -0,0 +1,286 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"main.go/entity"
+)
+
+// Prefix key cache katalog. Produk diinvalidasi per ID, daftar produk selalu ikut diinvalidasi
+// karena stok/harga/status yang berubah bisa memindahkan produk antar halaman hasil pencarian.
+const (
+	catalogCachePrefix     = "catalog:"
+	categoryCachePrefix    = "catalog:category:"
+	productCachePrefix     = "catalog:product:"
+	productCodeCachePrefix = "catalog:product-code:"
+	productListCachePrefix = "catalog:products:"
+)
+
+// CatalogCache - Invalidasi dan statistik cache katalog. Dipakai juga oleh repository lain yang
+// menulis tabel produk secara langsung (mutasi stok, jadwal harga).
+type CatalogCache interface {
+	InvalidateProducts(ids ...uint)
+	InvalidateCategories()
+	Flush()
+	Stats() entity.CacheStats
+}
+
+// CachedProductRepository - ProductRepository dengan cache baca di depannya
+type CachedProductRepository interface {
+	ProductRepository
+	CatalogCache
+}
+
+type cachedProductRepository struct {
+	ProductRepository // Method yang tidak di-cache diteruskan langsung
+	store             CacheStore
+	ttl               time.Duration
+
+	// generation naik setiap invalidasi; hasil baca yang dimulai sebelum invalidasi tidak disimpan
+	generation    atomic.Uint64
+	hits          atomic.Int64
+	misses        atomic.Int64
+	invalidations atomic.Int64
+	flushes       atomic.Int64
+	lastFlushAt   atomic.Int64 // Unix nano, 0 = belum pernah
+}
+
+// NewCachedProductRepository - Membungkus repo dengan cache ber-TTL, ttl <= 0 mematikan cache
+func NewCachedProductRepository(repo ProductRepository, store CacheStore, ttl time.Duration) CachedProductRepository {
+	return &cachedProductRepository{
+		ProductRepository: repo,
+		store:             store,
+		ttl:               ttl,
+	}
+}
+
+// cachedLoad - Mengambil nilai dari cache atau memuatnya dengan load lalu menyimpannya.
+// Error tidak di-cache sehingga data yang baru dibuat langsung terlihat.
+func cachedLoad[T any](r *cachedProductRepository, key string, load func() (T, error)) (T, error) {
+	if r.ttl <= 0 {
+		return load()
+	}
+
+	var value T
+	if data, found := r.store.Get(key); found {
+		if err := json.Unmarshal(data, &value); err == nil {
+			r.hits.Add(1)
+			return value, nil
+		}
+		r.store.Delete(key)
+	}
+	r.misses.Add(1)
+
+	generation := r.generation.Load()
+	value, err := load()
+	if err != nil {
+		return value, err
+	}
+	r.put(key, value, generation)
+	return value, nil
+}
+
+// put - Menyimpan nilai sebagai JSON, dilewati jika ada invalidasi sejak nilai mulai dibaca
+func (r *cachedProductRepository) put(key string, value interface{}, generation uint64) {
+	data, err := json.Marshal(value)
+	if err != nil || r.generation.Load() != generation {
+		return
+	}
+	r.store.Set(key, data, r.ttl)
+}
+
+// 🔍 Category Methods
+
+func (r *cachedProductRepository) GetAllCategories() ([]entity.Category, error) {
+	return cachedLoad(r, categoryCachePrefix+"all", r.ProductRepository.GetAllCategories)
+}
+
+func (r *cachedProductRepository) GetCategoryByID(id uint) (*entity.Category, error) {
+	return cachedLoad(r, fmt.Sprintf("%s%d", categoryCachePrefix, id), func() (*entity.Category, error) {
+		return r.ProductRepository.GetCategoryByID(id)
+	})
+}
+
+func (r *cachedProductRepository) GetCategoryDescendantIDs(id uint) ([]uint, error) {
+	return cachedLoad(r, fmt.Sprintf("%sdescendants:%d", categoryCachePrefix, id), func() ([]uint, error) {
+		return r.ProductRepository.GetCategoryDescendantIDs(id)
+	})
+}
+
+func (r *cachedProductRepository) GetCategoryAncestorIDs() (map[uint][]uint, error) {
+	return cachedLoad(r, categoryCachePrefix+"ancestors", r.ProductRepository.GetCategoryAncestorIDs)
+}
+
+func (r *cachedProductRepository) CreateCategory(category *entity.Category) error {
+	defer r.InvalidateCategories()
+	return r.ProductRepository.CreateCategory(category)
+}
+
+func (r *cachedProductRepository) UpdateCategory(category *entity.Category) error {
+	defer r.InvalidateCategories()
+	return r.ProductRepository.UpdateCategory(category)
+}
+
+func (r *cachedProductRepository) DeleteCategory(id uint, policy string, targetID uint, version uint) (*entity.CategoryDeleteResult, error) {
+	defer r.InvalidateCategories()
+	return r.ProductRepository.DeleteCategory(id, policy, targetID, version)
+}
+
+// 🔍 Product Methods
+
+// productListResult - Hasil SearchProducts yang disimpan di cache
+type productListResult struct {
+	Products []entity.Product `json:"products"`
+	Total    int64            `json:"total"`
+}
+
+func (r *cachedProductRepository) GetAllProducts() ([]entity.Product, error) {
+	return cachedLoad(r, productListCachePrefix+"all", r.ProductRepository.GetAllProducts)
+}
+
+func (r *cachedProductRepository) SearchProducts(filter entity.ProductFilter) ([]entity.Product, int64, error) {
+	filterKey, err := json.Marshal(filter)
+	if err != nil {
+		return r.ProductRepository.SearchProducts(filter)
+	}
+	result, err := cachedLoad(r, productListCachePrefix+"search:"+string(filterKey), func() (productListResult, error) {
+		products, total, err := r.ProductRepository.SearchProducts(filter)
+		return productListResult{Products: products, Total: total}, err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return result.Products, result.Total, nil
+}
+
+func (r *cachedProductRepository) GetProductByID(id uint) (*entity.Product, error) {
+	return cachedLoad(r, fmt.Sprintf("%s%d", productCachePrefix, id), func() (*entity.Product, error) {
+		return r.ProductRepository.GetProductByID(id)
+	})
+}
+
+// GetByID - Memakai entri cache yang sama dengan GetProductByID (keduanya memuat produk beserta kategorinya)
+func (r *cachedProductRepository) GetByID(id uint) (*entity.Product, error) {
+	return cachedLoad(r, fmt.Sprintf("%s%d", productCachePrefix, id), func() (*entity.Product, error) {
+		return r.ProductRepository.GetByID(id)
+	})
+}
+
+// GetByCode - Cache hanya menyimpan kode -> ID, produknya dibaca dari entri per ID sehingga
+// invalidasi per ID juga berlaku di sini. Kode yang sudah tidak cocok dianggap miss.
+func (r *cachedProductRepository) GetByCode(code string) (*entity.Product, error) {
+	if r.ttl <= 0 {
+		return r.ProductRepository.GetByCode(code)
+	}
+
+	key := productCodeCachePrefix + code
+	if data, found := r.store.Get(key); found {
+		var id uint
+		if json.Unmarshal(data, &id) == nil {
+			product, err := r.GetByID(id)
+			if err == nil && product.Code != nil && *product.Code == code {
+				return product, nil
+			}
+		}
+		r.store.Delete(key)
+	}
+
+	generation := r.generation.Load()
+	product, err := r.ProductRepository.GetByCode(code)
+	if err != nil {
+		return nil, err
+	}
+	r.put(key, product.ID, generation)
+	r.put(fmt.Sprintf("%s%d", productCachePrefix, product.ID), product, generation)
+	return product, nil
+}
+
+func (r *cachedProductRepository) CreateProduct(product *entity.Product) error {
+	// ID baru diketahui setelah insert, jadi dibaca saat defer dijalankan
+	defer func() { r.InvalidateProducts(product.ID) }()
+	return r.ProductRepository.CreateProduct(product)
+}
+
+// ImportProducts - Kategori baru dari impor ikut mengubah data kategori, sehingga seluruh katalog diinvalidasi
+func (r *cachedProductRepository) ImportProducts(categories []*entity.Category, products []*entity.Product) ([]entity.InventoryMovement, error) {
+	defer func() {
+		if len(categories) > 0 {
+			r.InvalidateCategories()
+			return
+		}
+		ids := make([]uint, 0, len(products))
+		for _, product := range products {
+			ids = append(ids, product.ID)
+		}
+		r.InvalidateProducts(ids...)
+	}()
+	return r.ProductRepository.ImportProducts(categories, products)
+}
+
+func (r *cachedProductRepository) UpdateProduct(product *entity.Product) error {
+	defer r.InvalidateProducts(product.ID)
+	return r.ProductRepository.UpdateProduct(product)
+}
+
+func (r *cachedProductRepository) DeleteProduct(id uint, version uint) error {
+	defer r.InvalidateProducts(id)
+	return r.ProductRepository.DeleteProduct(id, version)
+}
+
+func (r *cachedProductRepository) UpdateImage(productID uint, imageURL string, thumbnails map[string]string) error {
+	defer r.InvalidateProducts(productID)
+	return r.ProductRepository.UpdateImage(productID, imageURL, thumbnails)
+}
+
+// 🔍 Cache Methods
+
+// InvalidateProducts - Menghapus entri produk yang berubah beserta semua daftar produk
+func (r *cachedProductRepository) InvalidateProducts(ids ...uint) {
+	r.generation.Add(1)
+	r.invalidations.Add(1)
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, fmt.Sprintf("%s%d", productCachePrefix, id))
+	}
+	r.store.Delete(keys...)
+	r.store.DeletePrefix(productListCachePrefix)
+}
+
+// InvalidateCategories - Perubahan kategori ikut mengubah produk (relasi Category), seluruh katalog dihapus
+func (r *cachedProductRepository) InvalidateCategories() {
+	r.generation.Add(1)
+	r.invalidations.Add(1)
+	r.store.DeletePrefix(catalogCachePrefix)
+}
+
+// Flush - Mengosongkan cache katalog secara manual
+func (r *cachedProductRepository) Flush() {
+	r.generation.Add(1)
+	r.flushes.Add(1)
+	r.lastFlushAt.Store(time.Now().UnixNano())
+	r.store.DeletePrefix(catalogCachePrefix)
+}
+
+func (r *cachedProductRepository) Stats() entity.CacheStats {
+	stats := entity.CacheStats{
+		Enabled:       r.ttl > 0,
+		TTL:           r.ttl.String(),
+		Items:         r.store.ItemCount(),
+		Hits:          r.hits.Load(),
+		Misses:        r.misses.Load(),
+		Invalidations: r.invalidations.Load(),
+		Flushes:       r.flushes.Load(),
+	}
+	if reads := stats.Hits + stats.Misses; reads > 0 {
+		stats.HitRate = math.Round(float64(stats.Hits)/float64(reads)*10000) / 100
+	}
+	if flushedAt := r.lastFlushAt.Load(); flushedAt != 0 {
+		at := time.Unix(0, flushedAt)
+		stats.LastFlushAt = &at
+	}
+	return stats
+}
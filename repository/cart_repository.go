@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"main.go/entity"
+)
+
+type CartRepository interface {
+	GetOrCreate(userID uint) (*entity.Cart, error)
+	SetItemQuantity(cartID uint, productID uint, quantity int) error
+	RemoveItem(cartID uint, productID uint) error
+	Clear(cartID uint) error
+}
+
+type cartRepository struct {
+	db *gorm.DB
+}
+
+func NewCartRepository(db *gorm.DB) CartRepository {
+	return &cartRepository{db: db}
+}
+
+// GetOrCreate - Mengambil keranjang user beserta item dan produknya; dibuat jika belum ada
+func (r *cartRepository) GetOrCreate(userID uint) (*entity.Cart, error) {
+	cart := entity.Cart{UserID: userID}
+	if err := r.db.Where(entity.Cart{UserID: userID}).FirstOrCreate(&cart).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Preload("Product.Category").Where("cart_id = ?", cart.ID).Order("id ASC").Find(&cart.Items).Error; err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+func (r *cartRepository) SetItemQuantity(cartID uint, productID uint, quantity int) error {
+	item := entity.CartItem{CartID: cartID, ProductID: productID, Quantity: quantity}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "cart_id"}, {Name: "product_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"quantity", "updated_at"}),
+	}).Create(&item).Error
+}
+
+func (r *cartRepository) RemoveItem(cartID uint, productID uint) error {
+	return r.db.Where("cart_id = ? AND product_id = ?", cartID, productID).Delete(&entity.CartItem{}).Error
+}
+
+func (r *cartRepository) Clear(cartID uint) error {
+	return r.db.Where("cart_id = ?", cartID).Delete(&entity.CartItem{}).Error
+}
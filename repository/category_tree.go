@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"main.go/entity"
+)
+
+// loadCategories - Memuat semua kategori (termasuk yang di-soft delete) sebagai map ID -> kategori
+// untuk menelusuri hierarki di memori
+func loadCategories(db *gorm.DB) (map[uint]entity.Category, error) {
+	var categories []entity.Category
+	if err := db.Unscoped().Select("id", "name", "parent_id").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]entity.Category, len(categories))
+	for _, category := range categories {
+		byID[category.ID] = category
+	}
+	return byID, nil
+}
+
+// categoryDescendantIDs - ID kategori beserta seluruh turunannya
+func categoryDescendantIDs(categories map[uint]entity.Category, rootID uint) []uint {
+	children := make(map[uint][]uint)
+	for _, category := range categories {
+		if category.ParentID != nil {
+			children[*category.ParentID] = append(children[*category.ParentID], category.ID)
+		}
+	}
+
+	ids := []uint{rootID}
+	visited := map[uint]bool{rootID: true}
+	for i := 0; i < len(ids); i++ {
+		for _, childID := range children[ids[i]] {
+			if !visited[childID] {
+				visited[childID] = true
+				ids = append(ids, childID)
+			}
+		}
+	}
+	return ids
+}
+
+// categoryRootID - Kategori tingkat atas dari sebuah kategori, berhenti jika menemukan siklus
+func categoryRootID(categories map[uint]entity.Category, id uint) uint {
+	visited := make(map[uint]bool)
+	for {
+		category, ok := categories[id]
+		if !ok || category.ParentID == nil || visited[id] {
+			return id
+		}
+		if _, ok := categories[*category.ParentID]; !ok {
+			return id
+		}
+		visited[id] = true
+		id = *category.ParentID
+	}
+}
+
+// categoryAncestorIDs - ID kategori beserta induk-induknya, dari yang terdekat sampai tingkat atas
+func categoryAncestorIDs(categories map[uint]entity.Category, id uint) []uint {
+	ids := []uint{id}
+	visited := map[uint]bool{id: true}
+	for {
+		category, ok := categories[id]
+		if !ok || category.ParentID == nil || visited[*category.ParentID] {
+			return ids
+		}
+		id = *category.ParentID
+		visited[id] = true
+		ids = append(ids, id)
+	}
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"main.go/entity"
+)
+
+type FavoriteRepository interface {
+	Create(favorite *entity.Favorite) error
+	GetByUser(userID uint) ([]entity.Favorite, error)
+	GetByID(userID uint, id uint) (*entity.Favorite, error)
+	FindByNumber(userID uint, number string) (*entity.Favorite, error)
+	Update(favorite *entity.Favorite) error
+	Delete(userID uint, id uint) error
+	TouchLastUsed(id uint, at time.Time) error
+	Suggest(userID uint, limit int) ([]entity.FavoriteSuggestion, error)
+}
+
+type favoriteRepository struct {
+	db *gorm.DB
+}
+
+func NewFavoriteRepository(db *gorm.DB) FavoriteRepository {
+	return &favoriteRepository{db: db}
+}
+
+func (r *favoriteRepository) Create(favorite *entity.Favorite) error {
+	return r.db.Create(favorite).Error
+}
+
+// GetByUser - Favorit yang terakhir dipakai tampil paling atas
+func (r *favoriteRepository) GetByUser(userID uint) ([]entity.Favorite, error) {
+	var favorites []entity.Favorite
+	if err := r.db.Where("user_id = ?", userID).
+		Order("last_used_at IS NULL, last_used_at DESC, label ASC").
+		Find(&favorites).Error; err != nil {
+		return nil, err
+	}
+	return favorites, nil
+}
+
+func (r *favoriteRepository) GetByID(userID uint, id uint) (*entity.Favorite, error) {
+	var favorite entity.Favorite
+	if err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&favorite).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("favorite not found")
+		}
+		return nil, err
+	}
+	return &favorite, nil
+}
+
+func (r *favoriteRepository) FindByNumber(userID uint, number string) (*entity.Favorite, error) {
+	var favorite entity.Favorite
+	if err := r.db.Where("user_id = ? AND destination_number = ?", userID, number).First(&favorite).Error; err != nil {
+		return nil, err
+	}
+	return &favorite, nil
+}
+
+func (r *favoriteRepository) Update(favorite *entity.Favorite) error {
+	return r.db.Model(favorite).Select("label", "destination_number", "operator").Updates(favorite).Error
+}
+
+func (r *favoriteRepository) Delete(userID uint, id uint) error {
+	result := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&entity.Favorite{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("favorite not found")
+	}
+	return nil
+}
+
+func (r *favoriteRepository) TouchLastUsed(id uint, at time.Time) error {
+	return r.db.Model(&entity.Favorite{}).Where("id = ?", id).UpdateColumn("last_used_at", at).Error
+}
+
+// Suggest - Nomor tujuan dari riwayat transaksi yang belum ada di favorit, diurutkan dari yang paling sering dipakai
+func (r *favoriteRepository) Suggest(userID uint, limit int) ([]entity.FavoriteSuggestion, error) {
+	var suggestions []entity.FavoriteSuggestion
+	err := r.db.Table("transactions").
+		Select("destination_number, COUNT(*) as usage_count, MAX(created_at) as last_used_at").
+		Where("user_id = ? AND destination_number <> ''", userID).
+		Where("destination_number NOT IN (?)", r.db.Model(&entity.Favorite{}).Select("destination_number").Where("user_id = ?", userID)).
+		Group("destination_number").
+		Order("usage_count DESC, last_used_at DESC").
+		Limit(limit).
+		Scan(&suggestions).Error
+	return suggestions, err
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"main.go/entity"
+)
+
+// ErrInsufficientStock - Mutasi akan membuat stok produk negatif
+var ErrInsufficientStock = errors.New("insufficient stock")
+
+type InventoryRepository interface {
+	ApplyMovements(movements []entity.InventoryMovement) ([]entity.Product, error)
+	SetStock(productID uint, stock int, movement *entity.InventoryMovement) (*entity.Product, error)
+	GetMovements(productID uint, limit int) ([]entity.InventoryMovement, error)
+	GetTransactionMovements(transactionID uint) ([]entity.InventoryMovement, error)
+	GetLowStockProducts() ([]entity.Product, error)
+}
+
+type inventoryRepository struct {
+	db    *gorm.DB
+	cache CatalogCache // Produk yang stoknya berubah dihapus dari cache katalog
+}
+
+func NewInventoryRepository(db *gorm.DB, cache CatalogCache) InventoryRepository {
+	return &inventoryRepository{db: db, cache: cache}
+}
+
+// ApplyMovements - Menerapkan beberapa mutasi stok dalam satu transaksi (semua atau tidak sama sekali).
+// StockBefore/StockAfter setiap mutasi diisi, dan products[i] adalah kondisi produk setelah movements[i].
+func (r *inventoryRepository) ApplyMovements(movements []entity.InventoryMovement) ([]entity.Product, error) {
+	products := make([]entity.Product, len(movements))
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for i := range movements {
+			product, err := applyMovement(tx, &movements[i], nil)
+			if err != nil {
+				return err
+			}
+			products[i] = *product
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint, 0, len(movements))
+	for _, movement := range movements {
+		ids = append(ids, movement.ProductID)
+	}
+	r.cache.InvalidateProducts(ids...)
+	return products, nil
+}
+
+// SetStock - Menetapkan stok ke angka tertentu (mis. jumlah kode voucher tersisa) dan mencatat selisihnya.
+// Jika stok sudah sama, tidak ada mutasi yang dicatat (movement.ID tetap 0).
+func (r *inventoryRepository) SetStock(productID uint, stock int, movement *entity.InventoryMovement) (*entity.Product, error) {
+	movement.ProductID = productID
+	var product *entity.Product
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		product, err = applyMovement(tx, movement, &stock)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if movement.ID != 0 {
+		r.cache.InvalidateProducts(productID)
+	}
+	return product, nil
+}
+
+// applyMovement - Mengunci baris produk, mengubah stoknya lalu menyimpan mutasi.
+// stock selain nil menetapkan stok akhir, Quantity dihitung dari selisihnya.
+func applyMovement(tx *gorm.DB, movement *entity.InventoryMovement, stock *int) (*entity.Product, error) {
+	var product entity.Product
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, movement.ProductID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("product not found")
+		}
+		return nil, err
+	}
+
+	if stock != nil {
+		movement.Quantity = *stock - product.Stock
+	}
+	movement.StockBefore = product.Stock
+	movement.StockAfter = product.Stock + movement.Quantity
+	if movement.StockAfter < 0 {
+		return nil, ErrInsufficientStock
+	}
+	if movement.Quantity == 0 {
+		return &product, nil
+	}
+
+	err := tx.Model(&entity.Product{}).Where("id = ?", product.ID).
+		Updates(map[string]interface{}{"stock": movement.StockAfter, "version": versionIncrement}).Error
+	if err != nil {
+		return nil, err
+	}
+	product.Stock = movement.StockAfter
+	product.Version++
+
+	if err := tx.Create(movement).Error; err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// recordStockChange - Mencatat perubahan stok yang ditulis langsung ke tabel produk (produk baru dan impor katalog)
+func recordStockChange(tx *gorm.DB, productID uint, before int, after int, movementType string, reason string) (*entity.InventoryMovement, error) {
+	if before == after {
+		return nil, nil
+	}
+	movement := entity.InventoryMovement{
+		ProductID:   productID,
+		Type:        movementType,
+		Quantity:    after - before,
+		StockBefore: before,
+		StockAfter:  after,
+		Reason:      reason,
+	}
+	if err := tx.Create(&movement).Error; err != nil {
+		return nil, err
+	}
+	return &movement, nil
+}
+
+func (r *inventoryRepository) GetMovements(productID uint, limit int) ([]entity.InventoryMovement, error) {
+	var movements []entity.InventoryMovement
+	err := r.db.Where("product_id = ?", productID).Order("created_at DESC, id DESC").Limit(limit).Find(&movements).Error
+	return movements, err
+}
+
+func (r *inventoryRepository) GetTransactionMovements(transactionID uint) ([]entity.InventoryMovement, error) {
+	var movements []entity.InventoryMovement
+	err := r.db.Where("transaction_id = ?", transactionID).Order("id ASC").Find(&movements).Error
+	return movements, err
+}
+
+// GetLowStockProducts - Produk dengan batas minimum yang stoknya sudah mencapai batas tersebut
+func (r *inventoryRepository) GetLowStockProducts() ([]entity.Product, error) {
+	var products []entity.Product
+	err := r.db.Preload("Category").
+		Where("low_stock_threshold > 0 AND stock <= low_stock_threshold").
+		Order("stock ASC, id ASC").
+		Find(&products).Error
+	return products, err
+}
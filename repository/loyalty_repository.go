@@ -0,0 +1,339 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"main.go/entity"
+)
+
+// ErrInsufficientPoints - Poin aktif user tidak cukup untuk ditukar
+var ErrInsufficientPoints = errors.New("insufficient loyalty points")
+
+type LoyaltyRepository interface {
+	CreateRule(rule *entity.LoyaltyRule) error
+	GetAllRules() ([]entity.LoyaltyRule, error)
+	GetActiveRules() ([]entity.LoyaltyRule, error)
+	GetRuleByID(id uint) (*entity.LoyaltyRule, error)
+	UpdateRule(rule *entity.LoyaltyRule) error
+	DeleteRule(id uint) error
+
+	HasEntry(transactionID uint, entryType string) (bool, error)
+	CreateEntry(entry *entity.LoyaltyLedger) error
+	ExpireDue(userID uint, now time.Time) (int, error)
+	AvailablePoints(userID uint) (int, error)
+	ExpiringPoints(userID uint, before time.Time) (int, error)
+	GetLedger(userID uint, limit int) ([]entity.LoyaltyLedger, error)
+	Redeem(userID uint, points int, description string) (*entity.LoyaltyLedger, map[uint]int, error)
+	CancelRedeem(redeemed *entity.LoyaltyLedger, consumed map[uint]int, description string) error
+	ReverseEarn(transactionID uint, description string) (*entity.LoyaltyLedger, error)
+}
+
+type loyaltyRepository struct {
+	db *gorm.DB
+}
+
+func NewLoyaltyRepository(db *gorm.DB) LoyaltyRepository {
+	return &loyaltyRepository{db: db}
+}
+
+func (r *loyaltyRepository) CreateRule(rule *entity.LoyaltyRule) error {
+	return createWithActiveFlag(r.db, rule, rule.IsActive)
+}
+
+func (r *loyaltyRepository) GetAllRules() ([]entity.LoyaltyRule, error) {
+	var rules []entity.LoyaltyRule
+	if err := r.db.Order("id ASC").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *loyaltyRepository) GetActiveRules() ([]entity.LoyaltyRule, error) {
+	var rules []entity.LoyaltyRule
+	if err := r.db.Where("is_active = ?", true).Order("id ASC").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *loyaltyRepository) GetRuleByID(id uint) (*entity.LoyaltyRule, error) {
+	var rule entity.LoyaltyRule
+	if err := r.db.First(&rule, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("loyalty rule not found")
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *loyaltyRepository) UpdateRule(rule *entity.LoyaltyRule) error {
+	return r.db.Model(rule).Select("*").Omit("id", "created_at").Updates(rule).Error
+}
+
+func (r *loyaltyRepository) DeleteRule(id uint) error {
+	return r.db.Delete(&entity.LoyaltyRule{}, id).Error
+}
+
+// HasEntry - Mencegah poin dicatat dua kali untuk transaksi yang sama
+func (r *loyaltyRepository) HasEntry(transactionID uint, entryType string) (bool, error) {
+	var count int64
+	err := r.db.Model(&entity.LoyaltyLedger{}).
+		Where("transaction_id = ? AND type = ?", transactionID, entryType).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// CreateEntry - Entri earn baru langsung dipakai untuk melunasi utang poin user (jika ada)
+func (r *loyaltyRepository) CreateEntry(entry *entity.LoyaltyLedger) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(entry).Error; err != nil {
+			return err
+		}
+		if entry.Type != entity.LoyaltyEarn {
+			return nil
+		}
+		return settleDebt(tx, entry.UserID)
+	})
+}
+
+// ExpireDue - Menghanguskan sisa poin yang sudah lewat masa berlaku dan mencatatnya di ledger
+func (r *loyaltyRepository) ExpireDue(userID uint, now time.Time) (int, error) {
+	expired := 0
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var entries []entity.LoyaltyLedger
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND type = ? AND remaining > 0 AND expires_at IS NOT NULL AND expires_at <= ?", userID, entity.LoyaltyEarn, now).
+			Find(&entries).Error; err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			if err := tx.Model(&entry).Update("remaining", 0).Error; err != nil {
+				return err
+			}
+			expiry := entity.LoyaltyLedger{
+				UserID:        userID,
+				TransactionID: entry.TransactionID,
+				Type:          entity.LoyaltyExpire,
+				Points:        -entry.Remaining,
+				Description:   "Points expired",
+			}
+			if err := tx.Create(&expiry).Error; err != nil {
+				return err
+			}
+			expired += entry.Remaining
+		}
+		return nil
+	})
+	return expired, err
+}
+
+// AvailablePoints - Total sisa poin dari entri earn yang belum kedaluwarsa dikurangi utang poin
+// (remaining negatif pada entri reverse), sehingga hasilnya bisa negatif
+func (r *loyaltyRepository) AvailablePoints(userID uint) (int, error) {
+	var total int
+	err := r.db.Model(&entity.LoyaltyLedger{}).
+		Select("COALESCE(SUM(remaining), 0)").
+		Where("user_id = ?", userID).
+		Where(r.db.Where("type = ? AND remaining > 0 AND (expires_at IS NULL OR expires_at > ?)", entity.LoyaltyEarn, time.Now()).
+			Or("type = ? AND remaining < 0", entity.LoyaltyReverse)).
+		Scan(&total).Error
+	return total, err
+}
+
+func (r *loyaltyRepository) ExpiringPoints(userID uint, before time.Time) (int, error) {
+	var total int
+	err := r.db.Model(&entity.LoyaltyLedger{}).
+		Select("COALESCE(SUM(remaining), 0)").
+		Where("user_id = ? AND type = ? AND remaining > 0", userID, entity.LoyaltyEarn).
+		Where("expires_at > ? AND expires_at <= ?", time.Now(), before).
+		Scan(&total).Error
+	return total, err
+}
+
+func (r *loyaltyRepository) GetLedger(userID uint, limit int) ([]entity.LoyaltyLedger, error) {
+	var entries []entity.LoyaltyLedger
+	if err := r.db.Where("user_id = ?", userID).Order("created_at DESC, id DESC").Limit(limit).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Redeem - Memotong poin secara FIFO (yang paling cepat kedaluwarsa lebih dulu) lalu mencatat entri redeem.
+// Utang poin dilunasi lebih dulu. consumed berisi poin yang diambil per ID entri earn, untuk CancelRedeem.
+func (r *loyaltyRepository) Redeem(userID uint, points int, description string) (*entity.LoyaltyLedger, map[uint]int, error) {
+	var redeemed *entity.LoyaltyLedger
+	consumed := make(map[uint]int)
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := settleDebt(tx, userID); err != nil {
+			return err
+		}
+		entries, err := lockActiveEarnEntries(tx, userID)
+		if err != nil {
+			return err
+		}
+
+		left := points
+		for _, entry := range entries {
+			if left == 0 {
+				break
+			}
+			take := entry.Remaining
+			if take > left {
+				take = left
+			}
+			if err := tx.Model(&entry).Update("remaining", entry.Remaining-take).Error; err != nil {
+				return err
+			}
+			consumed[entry.ID] = take
+			left -= take
+		}
+		if left > 0 {
+			return ErrInsufficientPoints
+		}
+
+		redeemed = &entity.LoyaltyLedger{
+			UserID:      userID,
+			Type:        entity.LoyaltyRedeem,
+			Points:      -points,
+			Description: description,
+		}
+		return tx.Create(redeemed).Error
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return redeemed, consumed, nil
+}
+
+// CancelRedeem - Mengembalikan poin penukaran yang gagal ke entri earn asalnya (masa berlaku tetap)
+// dan mencatat entri refund di ledger
+func (r *loyaltyRepository) CancelRedeem(redeemed *entity.LoyaltyLedger, consumed map[uint]int, description string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for id, points := range consumed {
+			if err := tx.Model(&entity.LoyaltyLedger{}).Where("id = ?", id).
+				Update("remaining", gorm.Expr("remaining + ?", points)).Error; err != nil {
+				return err
+			}
+		}
+		refund := entity.LoyaltyLedger{
+			UserID:      redeemed.UserID,
+			Type:        entity.LoyaltyRefund,
+			Points:      -redeemed.Points,
+			Description: description,
+		}
+		if err := tx.Create(&refund).Error; err != nil {
+			return err
+		}
+		return settleDebt(tx, redeemed.UserID)
+	})
+}
+
+// lockActiveEarnEntries - Entri earn yang masih bersisa dan belum kedaluwarsa, urut FIFO
+func lockActiveEarnEntries(tx *gorm.DB, userID uint) ([]entity.LoyaltyLedger, error) {
+	var entries []entity.LoyaltyLedger
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND type = ? AND remaining > 0", userID, entity.LoyaltyEarn).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		Order("expires_at IS NULL, expires_at ASC, id ASC").
+		Find(&entries).Error
+	return entries, err
+}
+
+// settleDebt - Melunasi utang poin (entri reverse dengan remaining negatif) memakai poin aktif secara FIFO
+func settleDebt(tx *gorm.DB, userID uint) error {
+	var debts []entity.LoyaltyLedger
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND type = ? AND remaining < 0", userID, entity.LoyaltyReverse).
+		Order("id ASC").
+		Find(&debts).Error; err != nil {
+		return err
+	}
+	if len(debts) == 0 {
+		return nil
+	}
+
+	entries, err := lockActiveEarnEntries(tx, userID)
+	if err != nil {
+		return err
+	}
+
+	next := 0
+	for _, debt := range debts {
+		owed := -debt.Remaining
+		for owed > 0 && next < len(entries) {
+			entry := &entries[next]
+			take := entry.Remaining
+			if take > owed {
+				take = owed
+			}
+			entry.Remaining -= take
+			owed -= take
+			if err := tx.Model(entry).Update("remaining", entry.Remaining).Error; err != nil {
+				return err
+			}
+			if entry.Remaining == 0 {
+				next++
+			}
+		}
+		if owed != -debt.Remaining {
+			if err := tx.Model(&debt).Update("remaining", -owed).Error; err != nil {
+				return err
+			}
+		}
+		if owed > 0 {
+			break
+		}
+	}
+	return nil
+}
+
+// ReverseEarn - Menarik kembali seluruh poin transaksi yang di-refund. Sisa poin entri earn-nya dinolkan,
+// poin yang sudah ditukar atau kedaluwarsa dipotong dari poin aktif lain; kekurangannya menjadi utang poin
+// (remaining negatif pada entri reverse) yang dilunasi dari poin berikutnya. Mengembalikan nil jika transaksi tidak pernah menghasilkan poin atau sudah dibalik.
+func (r *loyaltyRepository) ReverseEarn(transactionID uint, description string) (*entity.LoyaltyLedger, error) {
+	var reversed *entity.LoyaltyLedger
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var earn entity.LoyaltyLedger
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("transaction_id = ? AND type = ?", transactionID, entity.LoyaltyEarn).
+			First(&earn).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&entity.LoyaltyLedger{}).
+			Where("transaction_id = ? AND type = ?", transactionID, entity.LoyaltyReverse).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+
+		if err := tx.Model(&earn).Update("remaining", 0).Error; err != nil {
+			return err
+		}
+		reversed = &entity.LoyaltyLedger{
+			UserID:        earn.UserID,
+			TransactionID: &transactionID,
+			Type:          entity.LoyaltyReverse,
+			Points:        -earn.Points,
+			Remaining:     -(earn.Points - earn.Remaining),
+			Description:   description,
+		}
+		if err := tx.Create(reversed).Error; err != nil {
+			return err
+		}
+		return settleDebt(tx, earn.UserID)
+	})
+	return reversed, err
+}
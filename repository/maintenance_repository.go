@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"main.go/entity"
+)
+
+type MaintenanceRepository interface {
+	Create(window *entity.MaintenanceWindow) error
+	GetAll() ([]entity.MaintenanceWindow, error)
+	GetActive() ([]entity.MaintenanceWindow, error)
+	GetByID(id uint) (*entity.MaintenanceWindow, error)
+	Update(window *entity.MaintenanceWindow) error
+	Delete(id uint) error
+}
+
+type maintenanceRepository struct {
+	db *gorm.DB
+}
+
+func NewMaintenanceRepository(db *gorm.DB) MaintenanceRepository {
+	return &maintenanceRepository{db: db}
+}
+
+func (r *maintenanceRepository) Create(window *entity.MaintenanceWindow) error {
+	return createWithActiveFlag(r.db, window, window.IsActive)
+}
+
+func (r *maintenanceRepository) GetAll() ([]entity.MaintenanceWindow, error) {
+	var windows []entity.MaintenanceWindow
+	if err := r.db.Order("created_at DESC").Find(&windows).Error; err != nil {
+		return nil, err
+	}
+	return windows, nil
+}
+
+// GetActive - Jadwal aktif yang masih mungkin berlaku (jadwal sekali jalan yang sudah lewat diabaikan)
+func (r *maintenanceRepository) GetActive() ([]entity.MaintenanceWindow, error) {
+	var windows []entity.MaintenanceWindow
+	err := r.db.Where("is_active = ?", true).
+		Where("type <> ? OR end_at > ?", entity.MaintenanceOneTime, time.Now()).
+		Find(&windows).Error
+	if err != nil {
+		return nil, err
+	}
+	return windows, nil
+}
+
+func (r *maintenanceRepository) GetByID(id uint) (*entity.MaintenanceWindow, error) {
+	var window entity.MaintenanceWindow
+	if err := r.db.First(&window, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("maintenance window not found")
+		}
+		return nil, err
+	}
+	return &window, nil
+}
+
+func (r *maintenanceRepository) Update(window *entity.MaintenanceWindow) error {
+	return r.db.Model(window).Select("*").Omit("id", "created_at").Updates(window).Error
+}
+
+func (r *maintenanceRepository) Delete(id uint) error {
+	return r.db.Delete(&entity.MaintenanceWindow{}, id).Error
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"main.go/entity"
+)
+
+type PriceGroupRepository interface {
+	Create(group *entity.PriceGroup) error
+	GetAll() ([]entity.PriceGroup, error)
+	GetByID(id uint) (*entity.PriceGroup, error)
+	GetDefault() (*entity.PriceGroup, error)
+	Update(group *entity.PriceGroup) error
+	Delete(id uint) error
+	ClearDefault(exceptID uint) error
+
+	UpsertPrice(price *entity.PriceGroupPrice) error
+	DeletePrice(groupID uint, productID uint) error
+	UpsertRule(rule *entity.PriceGroupRule) error
+	DeleteRule(groupID uint, categoryID uint) error
+}
+
+type priceGroupRepository struct {
+	db *gorm.DB
+}
+
+func NewPriceGroupRepository(db *gorm.DB) PriceGroupRepository {
+	return &priceGroupRepository{db: db}
+}
+
+func (r *priceGroupRepository) Create(group *entity.PriceGroup) error {
+	return r.db.Omit("Prices", "Rules").Create(group).Error
+}
+
+func (r *priceGroupRepository) GetAll() ([]entity.PriceGroup, error) {
+	var groups []entity.PriceGroup
+	if err := r.db.Order("id").Find(&groups).Error; err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// GetByID - Mengambil price group beserta harga produk dan aturan kategorinya
+func (r *priceGroupRepository) GetByID(id uint) (*entity.PriceGroup, error) {
+	var group entity.PriceGroup
+	if err := r.db.Preload("Prices").Preload("Rules").First(&group, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("price group not found")
+		}
+		return nil, err
+	}
+	return &group, nil
+}
+
+// GetDefault - Price group default, nil jika belum ada
+func (r *priceGroupRepository) GetDefault() (*entity.PriceGroup, error) {
+	var group entity.PriceGroup
+	err := r.db.Preload("Prices").Preload("Rules").Where("is_default = ?", true).First(&group).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+func (r *priceGroupRepository) Update(group *entity.PriceGroup) error {
+	return r.db.Model(group).Select("name", "description", "is_default").Updates(group).Error
+}
+
+func (r *priceGroupRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entity.User{}).Where("price_group_id = ?", id).Update("price_group_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("price_group_id = ?", id).Delete(&entity.PriceGroupPrice{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("price_group_id = ?", id).Delete(&entity.PriceGroupRule{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&entity.PriceGroup{}, id).Error
+	})
+}
+
+// ClearDefault - Hanya boleh ada satu price group default
+func (r *priceGroupRepository) ClearDefault(exceptID uint) error {
+	return r.db.Model(&entity.PriceGroup{}).Where("id <> ? AND is_default = ?", exceptID, true).Update("is_default", false).Error
+}
+
+func (r *priceGroupRepository) UpsertPrice(price *entity.PriceGroupPrice) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "price_group_id"}, {Name: "product_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"price", "updated_at"}),
+	}).Create(price).Error
+}
+
+func (r *priceGroupRepository) DeletePrice(groupID uint, productID uint) error {
+	return r.db.Where("price_group_id = ? AND product_id = ?", groupID, productID).Delete(&entity.PriceGroupPrice{}).Error
+}
+
+func (r *priceGroupRepository) UpsertRule(rule *entity.PriceGroupRule) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "price_group_id"}, {Name: "category_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"type", "value", "updated_at"}),
+	}).Create(rule).Error
+}
+
+func (r *priceGroupRepository) DeleteRule(groupID uint, categoryID uint) error {
+	return r.db.Where("price_group_id = ? AND category_id = ?", groupID, categoryID).Delete(&entity.PriceGroupRule{}).Error
+}
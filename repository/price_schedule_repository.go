@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"main.go/entity"
+)
+
+// ErrScheduleNotPending - Jadwal sudah diterapkan, dibatalkan atau gagal
+var ErrScheduleNotPending = errors.New("price schedule is no longer pending")
+
+// PriceCalculator - Menghitung harga baru sebuah produk untuk jadwal yang diterapkan
+type PriceCalculator func(schedule *entity.PriceSchedule, product *entity.Product) (float64, error)
+
+type PriceScheduleRepository interface {
+	CreateSchedule(schedule *entity.PriceSchedule) error
+	GetSchedules(status string) ([]entity.PriceSchedule, error)
+	GetScheduleByID(id uint) (*entity.PriceSchedule, error)
+	CancelSchedule(id uint) error
+	GetDueScheduleIDs(now time.Time) ([]uint, error)
+	ApplySchedule(id uint, now time.Time, calculate PriceCalculator) (*entity.PriceSchedule, error)
+	GetUpcoming(productID uint, categoryIDs []uint) ([]entity.PriceSchedule, error)
+
+	RecordHistory(entries []entity.ProductPriceHistory) error
+	GetHistory(productID uint) ([]entity.ProductPriceHistory, error)
+}
+
+type priceScheduleRepository struct {
+	db    *gorm.DB
+	cache CatalogCache // Produk yang harganya diubah jadwal dihapus dari cache katalog
+}
+
+func NewPriceScheduleRepository(db *gorm.DB, cache CatalogCache) PriceScheduleRepository {
+	return &priceScheduleRepository{db: db, cache: cache}
+}
+
+func (r *priceScheduleRepository) CreateSchedule(schedule *entity.PriceSchedule) error {
+	return r.db.Create(schedule).Error
+}
+
+func (r *priceScheduleRepository) GetSchedules(status string) ([]entity.PriceSchedule, error) {
+	query := r.db.Order("effective_at DESC, id DESC")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var schedules []entity.PriceSchedule
+	if err := query.Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+func (r *priceScheduleRepository) GetScheduleByID(id uint) (*entity.PriceSchedule, error) {
+	var schedule entity.PriceSchedule
+	if err := r.db.First(&schedule, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("price schedule not found")
+		}
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// CancelSchedule - Hanya jadwal yang masih pending yang bisa dibatalkan
+func (r *priceScheduleRepository) CancelSchedule(id uint) error {
+	result := r.db.Model(&entity.PriceSchedule{}).
+		Where("id = ? AND status = ?", id, entity.PriceSchedulePending).
+		Update("status", entity.PriceScheduleCancelled)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrScheduleNotPending
+	}
+	return nil
+}
+
+func (r *priceScheduleRepository) GetDueScheduleIDs(now time.Time) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&entity.PriceSchedule{}).
+		Where("status = ? AND effective_at <= ?", entity.PriceSchedulePending, now).
+		Order("effective_at ASC, id ASC").
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// ApplySchedule - Mengubah harga produk sasaran dan mencatat riwayatnya dalam satu transaksi.
+// Baris jadwal dikunci sehingga jadwal tidak diterapkan dua kali oleh worker yang berjalan bersamaan.
+// Jika harga salah satu produk tidak valid, tidak ada harga yang diubah dan jadwal ditandai gagal.
+func (r *priceScheduleRepository) ApplySchedule(id uint, now time.Time, calculate PriceCalculator) (*entity.PriceSchedule, error) {
+	var schedule entity.PriceSchedule
+	var updatedIDs []uint
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&schedule, id).Error; err != nil {
+			return err
+		}
+		if schedule.Status != entity.PriceSchedulePending {
+			return ErrScheduleNotPending
+		}
+
+		// Jadwal kategori berlaku juga untuk produk di seluruh sub-kategorinya
+		query := tx.Clauses(clause.Locking{Strength: "UPDATE"})
+		if schedule.Scope == entity.PriceScheduleCategory {
+			categories, err := loadCategories(tx)
+			if err != nil {
+				return err
+			}
+			query = query.Where("category_id IN ?", categoryDescendantIDs(categories, schedule.TargetID))
+		} else {
+			query = query.Where("id = ?", schedule.TargetID)
+		}
+		var products []entity.Product
+		if err := query.Find(&products).Error; err != nil {
+			return err
+		}
+
+		var history []entity.ProductPriceHistory
+		for i := range products {
+			newPrice, err := calculate(&schedule, &products[i])
+			if err != nil {
+				schedule.Status = entity.PriceScheduleFailed
+				schedule.FailReason = err.Error()
+				history = nil
+				break
+			}
+			if newPrice == products[i].Price {
+				continue
+			}
+			history = append(history, entity.ProductPriceHistory{
+				ProductID:  products[i].ID,
+				OldPrice:   products[i].Price,
+				NewPrice:   newPrice,
+				Source:     entity.PriceSourceSchedule,
+				ScheduleID: &schedule.ID,
+			})
+		}
+
+		for _, entry := range history {
+			if err := tx.Model(&entity.Product{}).Where("id = ?", entry.ProductID).Updates(map[string]interface{}{"price": entry.NewPrice, "version": versionIncrement}).Error; err != nil {
+				return err
+			}
+			updatedIDs = append(updatedIDs, entry.ProductID)
+		}
+		if len(history) > 0 {
+			if err := tx.Create(&history).Error; err != nil {
+				return err
+			}
+		}
+
+		if schedule.Status == entity.PriceSchedulePending {
+			schedule.Status = entity.PriceScheduleApplied
+			schedule.ProductsUpdated = len(history)
+		}
+		schedule.AppliedAt = &now
+		return tx.Model(&schedule).Updates(map[string]interface{}{
+			"status":           schedule.Status,
+			"applied_at":       schedule.AppliedAt,
+			"products_updated": schedule.ProductsUpdated,
+			"fail_reason":      schedule.FailReason,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(updatedIDs) > 0 {
+		r.cache.InvalidateProducts(updatedIDs...)
+	}
+	return &schedule, nil
+}
+
+// GetUpcoming - Jadwal pending yang mengenai produk, langsung maupun lewat kategorinya atau induknya
+func (r *priceScheduleRepository) GetUpcoming(productID uint, categoryIDs []uint) ([]entity.PriceSchedule, error) {
+	var schedules []entity.PriceSchedule
+	err := r.db.Where("status = ?", entity.PriceSchedulePending).
+		Where(r.db.Where("scope = ? AND target_id = ?", entity.PriceScheduleProduct, productID).
+			Or("scope = ? AND target_id IN ?", entity.PriceScheduleCategory, categoryIDs)).
+		Order("effective_at ASC, id ASC").
+		Find(&schedules).Error
+	return schedules, err
+}
+
+func (r *priceScheduleRepository) RecordHistory(entries []entity.ProductPriceHistory) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return r.db.Create(&entries).Error
+}
+
+func (r *priceScheduleRepository) GetHistory(productID uint) ([]entity.ProductPriceHistory, error) {
+	var history []entity.ProductPriceHistory
+	err := r.db.Where("product_id = ?", productID).Order("created_at DESC, id DESC").Find(&history).Error
+	return history, err
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"main.go/entity"
+)
+
+type PricingRepository interface {
+	// Markup rule methods
+	CreateMarkupRule(rule *entity.MarkupRule) error
+	GetAllMarkupRules() ([]entity.MarkupRule, error)
+	GetActiveMarkupRules() ([]entity.MarkupRule, error)
+	GetMarkupRuleByID(id uint) (*entity.MarkupRule, error)
+	UpdateMarkupRule(rule *entity.MarkupRule) error
+	DeleteMarkupRule(id uint) error
+
+	// Product cost methods
+	UpsertProductCost(cost *entity.ProductCost) error
+	GetProductCosts(productID uint) ([]entity.ProductCost, error)
+	GetProductCost(productID uint, supplier string) (*entity.ProductCost, error)
+	GetAllProductCosts() ([]entity.ProductCost, error)
+
+	// Markup apply
+	ApplyMarkup(changes []entity.MarkupPreviewItem) error
+}
+
+type pricingRepository struct {
+	db    *gorm.DB
+	cache CatalogCache // Produk yang harganya diubah markup dihapus dari cache katalog
+}
+
+func NewPricingRepository(db *gorm.DB, cache CatalogCache) PricingRepository {
+	return &pricingRepository{db: db, cache: cache}
+}
+
+func (r *pricingRepository) CreateMarkupRule(rule *entity.MarkupRule) error {
+	return createWithActiveFlag(r.db, rule, rule.IsActive)
+}
+
+func (r *pricingRepository) GetAllMarkupRules() ([]entity.MarkupRule, error) {
+	var rules []entity.MarkupRule
+	if err := r.db.Order("scope, priority DESC, id").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *pricingRepository) GetActiveMarkupRules() ([]entity.MarkupRule, error) {
+	var rules []entity.MarkupRule
+	if err := r.db.Where("is_active = ?", true).Order("priority DESC, id").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *pricingRepository) GetMarkupRuleByID(id uint) (*entity.MarkupRule, error) {
+	var rule entity.MarkupRule
+	if err := r.db.First(&rule, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("markup rule not found")
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *pricingRepository) UpdateMarkupRule(rule *entity.MarkupRule) error {
+	return r.db.Save(rule).Error
+}
+
+func (r *pricingRepository) DeleteMarkupRule(id uint) error {
+	return r.db.Delete(&entity.MarkupRule{}, id).Error
+}
+
+// UpsertProductCost - Menyimpan harga modal per supplier, memperbarui jika sudah ada
+func (r *pricingRepository) UpsertProductCost(cost *entity.ProductCost) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "product_id"}, {Name: "supplier"}},
+		DoUpdates: clause.AssignmentColumns([]string{"cost_price", "updated_at"}),
+	}).Create(cost).Error
+}
+
+func (r *pricingRepository) GetProductCosts(productID uint) ([]entity.ProductCost, error) {
+	var costs []entity.ProductCost
+	if err := r.db.Where("product_id = ?", productID).Order("cost_price").Find(&costs).Error; err != nil {
+		return nil, err
+	}
+	return costs, nil
+}
+
+func (r *pricingRepository) GetProductCost(productID uint, supplier string) (*entity.ProductCost, error) {
+	var cost entity.ProductCost
+	if err := r.db.Where("product_id = ? AND supplier = ?", productID, supplier).First(&cost).Error; err != nil {
+		return nil, err
+	}
+	return &cost, nil
+}
+
+func (r *pricingRepository) GetAllProductCosts() ([]entity.ProductCost, error) {
+	var costs []entity.ProductCost
+	if err := r.db.Find(&costs).Error; err != nil {
+		return nil, err
+	}
+	return costs, nil
+}
+
+// ApplyMarkup - Menerapkan harga baru hasil markup dan mencatat riwayat harga dalam satu transaksi.
+// Produk dikunci FOR UPDATE; jika harga produk sudah berubah sejak dihitung, semua perubahan dibatalkan dengan ErrVersionConflict.
+func (r *pricingRepository) ApplyMarkup(changes []entity.MarkupPreviewItem) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(changes))
+	for _, change := range changes {
+		ids = append(ids, change.ProductID)
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var products []entity.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id IN ?", ids).Find(&products).Error; err != nil {
+			return err
+		}
+		current := make(map[uint]float64, len(products))
+		for _, product := range products {
+			current[product.ID] = product.Price
+		}
+
+		history := make([]entity.ProductPriceHistory, 0, len(changes))
+		for _, change := range changes {
+			price, ok := current[change.ProductID]
+			if !ok || price != change.CurrentPrice {
+				return ErrVersionConflict
+			}
+			if err := tx.Model(&entity.Product{}).Where("id = ?", change.ProductID).Updates(map[string]interface{}{"price": change.NewPrice, "version": versionIncrement}).Error; err != nil {
+				return err
+			}
+			history = append(history, entity.ProductPriceHistory{
+				ProductID: change.ProductID,
+				OldPrice:  change.CurrentPrice,
+				NewPrice:  change.NewPrice,
+				Source:    entity.PriceSourceMarkup,
+			})
+		}
+		return tx.Create(&history).Error
+	})
+	if err != nil {
+		return err
+	}
+	r.cache.InvalidateProducts(ids...)
+	return nil
+}
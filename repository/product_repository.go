@@ -2,8 +2,13 @@ package repository
 
 import (
 	"errors"
+	"fmt"
+	"strings"
+	"time"
+
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"main.go/entity"
 	"main.go/middleware"
 )
@@ -14,20 +19,47 @@ type ProductRepository interface {
 	GetAllCategories() ([]entity.Category, error)
 	GetCategoryByID(id uint) (*entity.Category, error)
 	UpdateCategory(category *entity.Category) error
-	DeleteCategory(id uint) error
+	DeleteCategory(id uint, policy string, targetID uint, version uint) (*entity.CategoryDeleteResult, error)
+	GetCategoryDescendantIDs(id uint) ([]uint, error)
+	GetCategoryAncestorIDs() (map[uint][]uint, error)
 
 	// Product methods
 	CreateProduct(product *entity.Product) error
 	GetAllProducts() ([]entity.Product, error)
+	SearchProducts(filter entity.ProductFilter) ([]entity.Product, int64, error)
 	GetProductByID(id uint) (*entity.Product, error)
+	GetByCode(code string) (*entity.Product, error)
+	GetByCodes(codes []string) ([]entity.Product, error)
+	GetByCompactName(name string) (*entity.Product, error)
+	ImportProducts(categories []*entity.Category, products []*entity.Product) ([]entity.InventoryMovement, error)
 	UpdateProduct(product *entity.Product) error
-	DeleteProduct(id uint) error
-	UpdateImage(productID string, imageURL string) error
+	DeleteProduct(id uint, version uint) error
+	UpdateImage(productID uint, imageURL string, thumbnails map[string]string) error
+	CountByImageURL(imageURL string) (int64, error)
 
 	// ➕ Tambahkan ini
 	GetByID(id uint) (*entity.Product, error)
 }
 
+// ErrInvalidProductSort - Nilai sort tidak termasuk kolom yang diizinkan
+var ErrInvalidProductSort = errors.New("invalid sort field")
+
+var (
+	ErrCategoryNotFound = errors.New("category not found")
+	// ErrCategoryInUse - Kategori masih memiliki produk atau sub-kategori dan tidak ada policy yang dipilih
+	ErrCategoryInUse = errors.New("category is still in use")
+	// ErrCategoryNameTaken - Nama kategori sudah dipakai kategori lain
+	ErrCategoryNameTaken = errors.New("category name is already used")
+)
+
+// productSortColumns - Kolom yang boleh dipakai untuk sort daftar produk
+var productSortColumns = map[string]string{
+	"name":       "products.name",
+	"price":      "products.price",
+	"stock":      "products.stock",
+	"created_at": "products.created_at",
+}
+
 type productRepository struct {
 	db *gorm.DB
 }
@@ -43,7 +75,11 @@ func (r *productRepository) CreateCategory(category *entity.Category) error {
 		middleware.Logger.Warn("Repository: Category name cannot be empty")
 		return errors.New("category name cannot be empty")
 	}
+	category.Version = 1
 	if err := r.db.Create(category).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return ErrCategoryNameTaken
+		}
 		middleware.Logger.Error("Repository: Error creating category", zap.Error(err))
 		return err
 	}
@@ -51,6 +87,17 @@ func (r *productRepository) CreateCategory(category *entity.Category) error {
 	return nil
 }
 
+// deletedCategoryName - Nama pengganti untuk kategori yang di-soft delete agar nama uniknya bisa dipakai kategori baru.
+// Nama asli dipotong bila perlu supaya tetap muat di kolom varchar(191).
+func deletedCategoryName(name string, deletedAt time.Time) string {
+	suffix := fmt.Sprintf(" (deleted %d)", deletedAt.Unix())
+	runes := []rune(name)
+	if limit := 191 - len([]rune(suffix)); len(runes) > limit {
+		runes = runes[:limit]
+	}
+	return string(runes) + suffix
+}
+
 func (r *productRepository) GetAllCategories() ([]entity.Category, error) {
 	middleware.Logger.Info("Repository: Fetching all categories")
 	var categories []entity.Category
@@ -68,7 +115,7 @@ func (r *productRepository) GetCategoryByID(id uint) (*entity.Category, error) {
 	if err := r.db.First(&category, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			middleware.Logger.Warn("Repository: Category not found", zap.Uint("category_id", id))
-			return nil, errors.New("category not found")
+			return nil, ErrCategoryNotFound
 		}
 		middleware.Logger.Error("Repository: Error fetching category", zap.Error(err))
 		return nil, err
@@ -78,20 +125,123 @@ func (r *productRepository) GetCategoryByID(id uint) (*entity.Category, error) {
 
 func (r *productRepository) UpdateCategory(category *entity.Category) error {
 	middleware.Logger.Info("Repository: Updating category", zap.Uint("category_id", category.ID))
-	if err := r.db.Save(category).Error; err != nil {
-		middleware.Logger.Error("Repository: Error updating category", zap.Error(err))
+	if err := saveVersioned(r.db, category, &category.Version); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return ErrCategoryNameTaken
+		}
+		if !errors.Is(err, ErrVersionConflict) {
+			middleware.Logger.Error("Repository: Error updating category", zap.Error(err))
+		}
 		return err
 	}
 	return nil
 }
 
-func (r *productRepository) DeleteCategory(id uint) error {
-	middleware.Logger.Info("Repository: Deleting category", zap.Uint("category_id", id))
-	if err := r.db.Delete(&entity.Category{}, id).Error; err != nil {
-		middleware.Logger.Error("Repository: Error deleting category", zap.Error(err))
-		return err
+// DeleteCategory - Menghapus kategori dalam satu transaksi. Tanpa policy, kategori yang masih memiliki produk
+// atau sub-kategori ditolak dengan ErrCategoryInUse. Sub-kategori selalu dipindah ke induk kategori yang dihapus.
+// Version selain 0 harus sama dengan version kategori saat ini.
+func (r *productRepository) DeleteCategory(id uint, policy string, targetID uint, version uint) (*entity.CategoryDeleteResult, error) {
+	middleware.Logger.Info("Repository: Deleting category", zap.Uint("category_id", id), zap.String("policy", policy))
+	result := &entity.CategoryDeleteResult{CategoryID: id, Policy: policy}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var category entity.Category
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&category, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrCategoryNotFound
+			}
+			return err
+		}
+		if version > 0 && category.Version != version {
+			return ErrVersionConflict
+		}
+		if err := tx.Model(&entity.Product{}).Where("category_id = ?", id).Count(&result.ProductCount).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&entity.Category{}).Where("parent_id = ?", id).Count(&result.SubcategoryCount).Error; err != nil {
+			return err
+		}
+		if policy == "" && (result.ProductCount > 0 || result.SubcategoryCount > 0) {
+			return ErrCategoryInUse
+		}
+
+		if result.SubcategoryCount > 0 {
+			update := tx.Model(&entity.Category{}).Where("parent_id = ?", id).
+				Updates(map[string]interface{}{"parent_id": category.ParentID, "version": versionIncrement})
+			if update.Error != nil {
+				return update.Error
+			}
+			result.SubcategoriesReparented = update.RowsAffected
+		}
+
+		softDelete := false
+		switch policy {
+		case entity.CategoryDeleteMove:
+			var target entity.Category
+			if err := tx.First(&target, targetID).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return ErrCategoryNotFound
+				}
+				return err
+			}
+			result.TargetCategoryID = &target.ID
+			update := tx.Model(&entity.Product{}).Where("category_id = ?", id).
+				Updates(map[string]interface{}{"category_id": target.ID, "version": versionIncrement})
+			if update.Error != nil {
+				return update.Error
+			}
+			result.ProductsMoved = update.RowsAffected
+		case entity.CategoryDeleteDeactivate:
+			update := tx.Model(&entity.Product{}).Where("category_id = ? AND is_active = ?", id, true).
+				Updates(map[string]interface{}{"is_active": false, "version": versionIncrement})
+			if update.Error != nil {
+				return update.Error
+			}
+			result.ProductsDeactivated = update.RowsAffected
+			// Produk tetap menunjuk ke kategori ini, jadi barisnya dipertahankan
+			softDelete = result.ProductCount > 0
+		}
+
+		if softDelete {
+			// Nama dibebaskan agar kategori baru dengan nama yang sama bisa dibuat tanpa memulihkan baris ini
+			if err := tx.Model(&category).Update("name", deletedCategoryName(category.Name, time.Now())).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&category).Error
+		}
+		return tx.Unscoped().Delete(&category).Error
+	})
+	if err != nil {
+		if !errors.Is(err, ErrCategoryInUse) && !errors.Is(err, ErrVersionConflict) {
+			middleware.Logger.Error("Repository: Error deleting category", zap.Error(err))
+		}
+		return result, err
 	}
-	return nil
+	return result, nil
+}
+
+// GetCategoryDescendantIDs - ID kategori beserta seluruh sub-kategorinya (semua tingkat)
+func (r *productRepository) GetCategoryDescendantIDs(id uint) ([]uint, error) {
+	categories, err := loadCategories(r.db)
+	if err != nil {
+		middleware.Logger.Error("Repository: Error fetching category tree", zap.Error(err))
+		return nil, err
+	}
+	return categoryDescendantIDs(categories, id), nil
+}
+
+// GetCategoryAncestorIDs - Untuk setiap kategori: ID kategori itu sendiri lalu induk-induknya sampai
+// tingkat atas (terdekat lebih dulu). Dipakai aturan berbasis kategori agar ikut berlaku pada sub-kategori.
+func (r *productRepository) GetCategoryAncestorIDs() (map[uint][]uint, error) {
+	categories, err := loadCategories(r.db)
+	if err != nil {
+		middleware.Logger.Error("Repository: Error fetching category tree", zap.Error(err))
+		return nil, err
+	}
+	ancestors := make(map[uint][]uint, len(categories))
+	for id := range categories {
+		ancestors[id] = categoryAncestorIDs(categories, id)
+	}
+	return ancestors, nil
 }
 
 // 🔍 Product Methods
@@ -101,13 +251,45 @@ func (r *productRepository) CreateProduct(product *entity.Product) error {
 		middleware.Logger.Warn("Repository: Invalid product data")
 		return errors.New("invalid product data")
 	}
-	if err := r.db.Create(product).Error; err != nil {
+	product.Version = 1
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(product).Error; err != nil {
+			return err
+		}
+		if !product.IsActive {
+			if err := saveInactive(tx, product); err != nil {
+				return err
+			}
+		}
+		_, err := recordStockChange(tx, product.ID, 0, product.Stock, entity.InventoryAdjustment, "Initial stock")
+		return err
+	})
+	if err != nil {
 		middleware.Logger.Error("Repository: Error creating product", zap.Error(err))
 		return err
 	}
 	return nil
 }
 
+// saveInactive - Insert mengabaikan nilai false untuk kolom ber-default true, sehingga diset ulang
+func saveInactive(db *gorm.DB, product *entity.Product) error {
+	return db.Model(&entity.Product{}).Where("id = ?", product.ID).Update("is_active", false).Error
+}
+
+// createWithActiveFlag - Create untuk model dengan kolom is_active ber-default true. Seperti saveInactive,
+// nilai false yang diabaikan saat insert ditulis ulang dalam transaksi yang sama.
+func createWithActiveFlag(db *gorm.DB, value interface{}, isActive bool) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(value).Error; err != nil {
+			return err
+		}
+		if isActive {
+			return nil
+		}
+		return tx.Model(value).Update("is_active", false).Error
+	})
+}
+
 func (r *productRepository) GetAllProducts() ([]entity.Product, error) {
 	middleware.Logger.Info("Repository: Fetching all products")
 	var products []entity.Product
@@ -118,6 +300,76 @@ func (r *productRepository) GetAllProducts() ([]entity.Product, error) {
 	return products, nil
 }
 
+// SearchProducts - Pencarian produk dengan filter, sort dan pagination beserta total data
+func (r *productRepository) SearchProducts(filter entity.ProductFilter) ([]entity.Product, int64, error) {
+	middleware.Logger.Info("Repository: Searching products", zap.Any("filter", filter))
+
+	order := "products.id ASC"
+	if filter.Sort != "" {
+		direction := "ASC"
+		field := filter.Sort
+		if strings.HasPrefix(field, "-") {
+			direction = "DESC"
+			field = strings.TrimPrefix(field, "-")
+		}
+		column, ok := productSortColumns[field]
+		if !ok {
+			return nil, 0, ErrInvalidProductSort
+		}
+		order = fmt.Sprintf("%s %s, products.id ASC", column, direction)
+	}
+
+	query := r.db.Model(&entity.Product{})
+	if keyword := strings.TrimSpace(filter.Keyword); keyword != "" {
+		like := "%" + keyword + "%"
+		query = query.Where(r.db.Where("products.name LIKE ?", like).Or("products.description LIKE ?", like))
+	}
+	if filter.CategoryID > 0 {
+		// Filter kategori juga mencakup produk di semua sub-kategorinya
+		categoryIDs, err := r.GetCategoryDescendantIDs(filter.CategoryID)
+		if err != nil {
+			return nil, 0, err
+		}
+		query = query.Where("products.category_id IN ?", categoryIDs)
+	}
+	if filter.Operator != "" {
+		query = query.Where("products.operator = ?", filter.Operator)
+	}
+	if filter.MinPrice != nil {
+		query = query.Where("products.price >= ?", *filter.MinPrice)
+	}
+	if filter.MaxPrice != nil {
+		query = query.Where("products.price <= ?", *filter.MaxPrice)
+	}
+	if filter.InStock != nil {
+		if *filter.InStock {
+			query = query.Where("products.stock > 0")
+		} else {
+			query = query.Where("products.stock <= 0")
+		}
+	}
+	if filter.IsActive != nil {
+		query = query.Where("products.is_active = ?", *filter.IsActive)
+	}
+
+	// Session baru agar Count dan Find tidak saling mengubah statement
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		middleware.Logger.Error("Repository: Error counting products", zap.Error(err))
+		return nil, 0, err
+	}
+
+	var products []entity.Product
+	offset := (filter.Page - 1) * filter.Limit
+	if err := query.Preload("Category").Order(order).Limit(filter.Limit).Offset(offset).Find(&products).Error; err != nil {
+		middleware.Logger.Error("Repository: Error searching products", zap.Error(err))
+		return nil, 0, err
+	}
+	return products, total, nil
+}
+
 func (r *productRepository) GetProductByID(id uint) (*entity.Product, error) {
 	middleware.Logger.Info("Repository: Fetching product by ID", zap.Uint("product_id", id))
 	var product entity.Product
@@ -132,19 +384,132 @@ func (r *productRepository) GetProductByID(id uint) (*entity.Product, error) {
 	return &product, nil
 }
 
+// GetByCompactName - Mengambil produk berdasarkan nama tanpa spasi, tidak peka huruf besar/kecil
+func (r *productRepository) GetByCompactName(name string) (*entity.Product, error) {
+	var product entity.Product
+	err := r.db.Preload("Category").
+		Where("UPPER(REPLACE(name, ' ', '')) = ?", strings.ToUpper(strings.ReplaceAll(name, " ", ""))).
+		Order("id ASC").
+		First(&product).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("product not found")
+		}
+		return nil, err
+	}
+	return &product, nil
+}
+
+// GetByCode - Mengambil produk berdasarkan kode SKU
+func (r *productRepository) GetByCode(code string) (*entity.Product, error) {
+	middleware.Logger.Info("Repository: Fetching product by code", zap.String("code", code))
+	var product entity.Product
+	if err := r.db.Preload("Category").Where("code = ?", code).First(&product).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			middleware.Logger.Warn("Repository: Product not found", zap.String("code", code))
+			return nil, errors.New("product not found")
+		}
+		middleware.Logger.Error("Repository: Error fetching product", zap.Error(err))
+		return nil, err
+	}
+	return &product, nil
+}
+
+// GetByCodes - Mengambil produk berdasarkan daftar kode SKU
+func (r *productRepository) GetByCodes(codes []string) ([]entity.Product, error) {
+	var products []entity.Product
+	if len(codes) == 0 {
+		return products, nil
+	}
+	if err := r.db.Where("code IN ?", codes).Find(&products).Error; err != nil {
+		middleware.Logger.Error("Repository: Error fetching products by code", zap.Error(err))
+		return nil, err
+	}
+	return products, nil
+}
+
+// ImportProducts - Menyimpan kategori baru lalu produk hasil impor dalam satu transaksi.
+// Produk dengan CategoryID 0 memakai kategori baru yang namanya sama dengan Category.Name.
+// Perubahan stok dicatat di ledger inventori dan dikembalikan untuk pengecekan alert stok minimum.
+func (r *productRepository) ImportProducts(categories []*entity.Category, products []*entity.Product) ([]entity.InventoryMovement, error) {
+	middleware.Logger.Info("Repository: Importing products", zap.Int("categories", len(categories)), zap.Int("products", len(products)))
+	var movements []entity.InventoryMovement
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		categoryIDs := make(map[string]uint, len(categories))
+		for _, category := range categories {
+			category.Version = 1
+			if err := tx.Create(category).Error; err != nil {
+				if errors.Is(err, gorm.ErrDuplicatedKey) {
+					return ErrCategoryNameTaken
+				}
+				return err
+			}
+			categoryIDs[category.Name] = category.ID
+		}
+
+		for _, product := range products {
+			if product.CategoryID == 0 {
+				product.CategoryID = categoryIDs[product.Category.Name]
+			}
+			stockBefore := 0
+			if product.ID != 0 {
+				if err := tx.Model(&entity.Product{}).Where("id = ?", product.ID).Select("stock").Scan(&stockBefore).Error; err != nil {
+					return err
+				}
+				if err := saveVersioned(tx, product, &product.Version); err != nil {
+					return err
+				}
+			} else {
+				product.Version = 1
+				if err := tx.Omit(clause.Associations).Create(product).Error; err != nil {
+					return err
+				}
+				if !product.IsActive {
+					if err := saveInactive(tx, product); err != nil {
+						return err
+					}
+				}
+			}
+
+			movement, err := recordStockChange(tx, product.ID, stockBefore, product.Stock, entity.InventoryImport, "Catalog import")
+			if err != nil {
+				return err
+			}
+			if movement != nil {
+				movements = append(movements, *movement)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return movements, nil
+}
+
 func (r *productRepository) UpdateProduct(product *entity.Product) error {
 	middleware.Logger.Info("Repository: Updating product", zap.Uint("product_id", product.ID))
-	if err := r.db.Save(product).Error; err != nil {
-		middleware.Logger.Error("Repository: Error updating product", zap.Error(err))
+	// Relasi Category tidak ikut disimpan agar tidak menimpa category_id yang baru,
+	// dan update ditolak dengan ErrVersionConflict jika produk sudah diubah request lain
+	if err := saveVersioned(r.db, product, &product.Version); err != nil {
+		if !errors.Is(err, ErrVersionConflict) {
+			middleware.Logger.Error("Repository: Error updating product", zap.Error(err))
+		}
 		return err
 	}
 	return nil
 }
 
-func (r *productRepository) DeleteProduct(id uint) error {
+// DeleteProduct - Menghapus produk, version 0 berarti tanpa pengecekan version
+func (r *productRepository) DeleteProduct(id uint, version uint) error {
 	middleware.Logger.Info("Repository: Deleting product", zap.Uint("product_id", id))
-	if err := r.db.Delete(&entity.Product{}, id).Error; err != nil {
-		middleware.Logger.Error("Repository: Error deleting product", zap.Error(err))
+	if err := deleteVersioned(r.db, &entity.Product{}, id, version); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("product not found")
+		}
+		if !errors.Is(err, ErrVersionConflict) {
+			middleware.Logger.Error("Repository: Error deleting product", zap.Error(err))
+		}
 		return err
 	}
 	return nil
@@ -164,6 +529,21 @@ func (r *productRepository) GetByID(id uint) (*entity.Product, error) {
 	return &product, nil
 }
 
-func (r *productRepository) UpdateImage(productID string, imageURL string) error {
-	return r.db.Model(&entity.Product{}).Where("id = ?", productID).Update("image_url", imageURL).Error
+// UpdateImage - Menyimpan URL gambar utama beserta thumbnail-nya
+func (r *productRepository) UpdateImage(productID uint, imageURL string, thumbnails map[string]string) error {
+	// Update lewat struct agar serializer json pada Thumbnails dipakai; version dinaikkan terpisah
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entity.Product{ID: productID}).Select("image_url", "thumbnails").
+			Updates(&entity.Product{ImageURL: imageURL, Thumbnails: thumbnails}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&entity.Product{ID: productID}).UpdateColumn("version", versionIncrement).Error
+	})
+}
+
+// CountByImageURL - Jumlah produk yang memakai file gambar yang sama (nama file berbasis hash bisa dipakai bersama)
+func (r *productRepository) CountByImageURL(imageURL string) (int64, error) {
+	var count int64
+	err := r.db.Model(&entity.Product{}).Where("image_url = ?", imageURL).Count(&count).Error
+	return count, err
 }
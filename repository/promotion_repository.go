@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"main.go/entity"
+)
+
+var (
+	// ErrPromotionQuotaExhausted - Kuota global promo sudah habis
+	ErrPromotionQuotaExhausted = errors.New("promo code quota has been exhausted")
+	// ErrPromotionUserLimitReached - User sudah mencapai batas pemakaian promo
+	ErrPromotionUserLimitReached = errors.New("promo code usage limit reached for this user")
+	// ErrPromotionInactive - Promo dinonaktifkan sebelum kuotanya sempat dikunci
+	ErrPromotionInactive = errors.New("promo code is not active")
+)
+
+type PromotionRepository interface {
+	Create(promotion *entity.Promotion) error
+	GetAll() ([]entity.Promotion, error)
+	GetByID(id uint) (*entity.Promotion, error)
+	GetByCode(code string) (*entity.Promotion, error)
+	Update(promotion *entity.Promotion) error
+	Delete(id uint) error
+
+	ReserveUsage(promotionID uint, userID uint, discount float64) (*entity.PromotionUsage, error)
+	AttachTransaction(usageID uint, transactionID uint) error
+	ReleaseUsage(usageID uint) error
+	ReverseByTransaction(transactionID uint) (*entity.PromotionUsage, error)
+	GetUsages(promotionID uint) ([]entity.PromotionUsage, error)
+}
+
+type promotionRepository struct {
+	db *gorm.DB
+}
+
+func NewPromotionRepository(db *gorm.DB) PromotionRepository {
+	return &promotionRepository{db: db}
+}
+
+func (r *promotionRepository) Create(promotion *entity.Promotion) error {
+	return createWithActiveFlag(r.db, promotion, promotion.IsActive)
+}
+
+func (r *promotionRepository) GetAll() ([]entity.Promotion, error) {
+	var promotions []entity.Promotion
+	if err := r.db.Order("created_at DESC").Find(&promotions).Error; err != nil {
+		return nil, err
+	}
+	return promotions, nil
+}
+
+func (r *promotionRepository) GetByID(id uint) (*entity.Promotion, error) {
+	var promotion entity.Promotion
+	if err := r.db.First(&promotion, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("promotion not found")
+		}
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+func (r *promotionRepository) GetByCode(code string) (*entity.Promotion, error) {
+	var promotion entity.Promotion
+	if err := r.db.Where("code = ?", code).First(&promotion).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("promo code not found")
+		}
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+// Update - Menyimpan perubahan promo tanpa menimpa used_count yang dikelola ReserveUsage
+func (r *promotionRepository) Update(promotion *entity.Promotion) error {
+	return r.db.Model(promotion).Select("*").Omit("id", "used_count", "created_at").Updates(promotion).Error
+}
+
+func (r *promotionRepository) Delete(id uint) error {
+	return r.db.Delete(&entity.Promotion{}, id).Error
+}
+
+// ReserveUsage - Mengunci baris promo, memeriksa status aktif, kuota global dan per user, lalu mencatat pemakaian
+func (r *promotionRepository) ReserveUsage(promotionID uint, userID uint, discount float64) (*entity.PromotionUsage, error) {
+	var usage *entity.PromotionUsage
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var promotion entity.Promotion
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&promotion, promotionID).Error; err != nil {
+			return err
+		}
+
+		if !promotion.IsActive {
+			return ErrPromotionInactive
+		}
+
+		if promotion.GlobalLimit > 0 && promotion.UsedCount >= promotion.GlobalLimit {
+			return ErrPromotionQuotaExhausted
+		}
+
+		if promotion.PerUserLimit > 0 {
+			var used int64
+			if err := tx.Model(&entity.PromotionUsage{}).
+				Where("promotion_id = ? AND user_id = ? AND status <> ?", promotionID, userID, entity.PromotionUsageReversed).
+				Count(&used).Error; err != nil {
+				return err
+			}
+			if int(used) >= promotion.PerUserLimit {
+				return ErrPromotionUserLimitReached
+			}
+		}
+
+		if err := tx.Model(&promotion).UpdateColumn("used_count", gorm.Expr("used_count + 1")).Error; err != nil {
+			return err
+		}
+
+		usage = &entity.PromotionUsage{
+			PromotionID: promotionID,
+			UserID:      userID,
+			Discount:    discount,
+			Status:      entity.PromotionUsageReserved,
+		}
+		return tx.Create(usage).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+func (r *promotionRepository) AttachTransaction(usageID uint, transactionID uint) error {
+	return r.db.Model(&entity.PromotionUsage{}).Where("id = ?", usageID).Updates(map[string]interface{}{
+		"transaction_id": transactionID,
+		"status":         entity.PromotionUsageApplied,
+	}).Error
+}
+
+// ReleaseUsage - Membatalkan reservasi yang transaksinya gagal disimpan
+func (r *promotionRepository) ReleaseUsage(usageID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var usage entity.PromotionUsage
+		if err := tx.First(&usage, usageID).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&usage).Error; err != nil {
+			return err
+		}
+		return tx.Model(&entity.Promotion{}).Where("id = ? AND used_count > 0", usage.PromotionID).
+			UpdateColumn("used_count", gorm.Expr("used_count - 1")).Error
+	})
+}
+
+// ReverseByTransaction - Mengembalikan kuota promo milik transaksi; nil jika transaksi tidak memakai promo
+func (r *promotionRepository) ReverseByTransaction(transactionID uint) (*entity.PromotionUsage, error) {
+	var reversed *entity.PromotionUsage
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var usage entity.PromotionUsage
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("transaction_id = ? AND status = ?", transactionID, entity.PromotionUsageApplied).
+			First(&usage).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&usage).Update("status", entity.PromotionUsageReversed).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&entity.Promotion{}).Where("id = ? AND used_count > 0", usage.PromotionID).
+			UpdateColumn("used_count", gorm.Expr("used_count - 1")).Error; err != nil {
+			return err
+		}
+		reversed = &usage
+		return nil
+	})
+	return reversed, err
+}
+
+func (r *promotionRepository) GetUsages(promotionID uint) ([]entity.PromotionUsage, error) {
+	var usages []entity.PromotionUsage
+	if err := r.db.Where("promotion_id = ?", promotionID).Order("created_at DESC").Find(&usages).Error; err != nil {
+		return nil, err
+	}
+	return usages, nil
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"main.go/entity"
+)
+
+type ReferralRepository interface {
+	GetLevels() ([]entity.CommissionLevel, error)
+	UpsertLevel(level *entity.CommissionLevel) error
+	DeleteLevel(level int) error
+
+	CreateCommission(commission *entity.Commission) (bool, error)
+	ReverseByTransaction(transactionID uint) ([]entity.Commission, error)
+	GetCommissions(filters entity.CommissionFilters) ([]entity.Commission, error)
+	SummarizeByLevel(filters entity.CommissionFilters) ([]entity.CommissionLevelSummary, error)
+}
+
+type referralRepository struct {
+	db *gorm.DB
+}
+
+func NewReferralRepository(db *gorm.DB) ReferralRepository {
+	return &referralRepository{db: db}
+}
+
+func (r *referralRepository) GetLevels() ([]entity.CommissionLevel, error) {
+	var levels []entity.CommissionLevel
+	if err := r.db.Order("level ASC").Find(&levels).Error; err != nil {
+		return nil, err
+	}
+	return levels, nil
+}
+
+func (r *referralRepository) UpsertLevel(level *entity.CommissionLevel) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "level"}},
+		DoUpdates: clause.AssignmentColumns([]string{"percentage", "updated_at"}),
+	}).Create(level).Error
+}
+
+func (r *referralRepository) DeleteLevel(level int) error {
+	result := r.db.Where("level = ?", level).Delete(&entity.CommissionLevel{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("commission level not found")
+	}
+	return nil
+}
+
+// CreateCommission - Mencatat komisi; false jika komisi untuk transaksi dan upline ini sudah ada
+func (r *referralRepository) CreateCommission(commission *entity.Commission) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(commission)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ReverseByTransaction - Menandai komisi transaksi sebagai reversed dan mengembalikan komisi yang dibalik
+func (r *referralRepository) ReverseByTransaction(transactionID uint) ([]entity.Commission, error) {
+	var reversed []entity.Commission
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("transaction_id = ? AND status = ?", transactionID, entity.CommissionAccrued).
+			Find(&reversed).Error; err != nil {
+			return err
+		}
+		if len(reversed) == 0 {
+			return nil
+		}
+		return tx.Model(&entity.Commission{}).
+			Where("transaction_id = ? AND status = ?", transactionID, entity.CommissionAccrued).
+			Update("status", entity.CommissionReversed).Error
+	})
+	return reversed, err
+}
+
+func (r *referralRepository) GetCommissions(filters entity.CommissionFilters) ([]entity.Commission, error) {
+	var commissions []entity.Commission
+	if err := r.filtered(filters).Order("created_at DESC").Find(&commissions).Error; err != nil {
+		return nil, err
+	}
+	return commissions, nil
+}
+
+func (r *referralRepository) SummarizeByLevel(filters entity.CommissionFilters) ([]entity.CommissionLevelSummary, error) {
+	var summaries []entity.CommissionLevelSummary
+	err := r.filtered(filters).
+		Where("status = ?", entity.CommissionAccrued).
+		Select("level, COUNT(*) as transactions, SUM(amount) as amount").
+		Group("level").
+		Order("level ASC").
+		Scan(&summaries).Error
+	return summaries, err
+}
+
+func (r *referralRepository) filtered(filters entity.CommissionFilters) *gorm.DB {
+	query := r.db.Model(&entity.Commission{})
+	if filters.UplineID != 0 {
+		query = query.Where("upline_id = ?", filters.UplineID)
+	}
+	if filters.Status != "" {
+		query = query.Where("status = ?", filters.Status)
+	}
+	if filters.StartDate != "" && filters.EndDate != "" {
+		query = query.Where("created_at BETWEEN ? AND ?", filters.StartDate, filters.EndDate)
+	}
+	return query
+}
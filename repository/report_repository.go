@@ -1,12 +1,15 @@
 package repository
 
 import (
+	"sort"
+
 	"gorm.io/gorm"
 	"main.go/entity"
 )
 
 type ReportRepository interface {
 	GetTransactionsSummary(filters entity.ReportFilters, isAdmin bool, userID uint) ([]entity.TransactionSummary, error)
+	GetCategoryRollup(filters entity.ReportFilters, isAdmin bool, userID uint) ([]entity.CategoryRollup, error)
 }
 
 type reportRepository struct {
@@ -26,17 +29,101 @@ func (r *reportRepository) GetTransactionsSummary(filters entity.ReportFilters,
 			users.id as user_id,
 			users.username as user_name,
 			products.name as product_name,
+			categories.id as category_id,
 			categories.name as category_name,
 			sum(transaction_items.quantity) as quantity,
 			sum(transaction_items.quantity * transaction_items.price) as total_price,
+			sum(transaction_items.margin) as margin,
 			transactions.created_at as transaction_date
 		`).
 		Joins("join transaction_items on transactions.id = transaction_items.transaction_id").
 		Joins("join products on transaction_items.product_id = products.id").
 		Joins("join categories on products.category_id = categories.id").
 		Joins("join users on transactions.user_id = users.id").
-		Group("transactions.id, users.id, products.name, categories.name, transactions.created_at")
+		Group("transactions.id, users.id, products.name, categories.id, categories.name, transactions.created_at")
+	query = applyReportFilters(query, filters, isAdmin, userID)
+
+	// Pagination
+	offset := (filters.Page - 1) * filters.Limit
+	query = query.Limit(filters.Limit).Offset(offset)
+
+	if err := query.Scan(&summaries).Error; err != nil {
+		return nil, err
+	}
+
+	categories, err := loadCategories(r.db)
+	if err != nil {
+		return nil, err
+	}
+	for i := range summaries {
+		summaries[i].TopCategoryName = categories[categoryRootID(categories, summaries[i].CategoryID)].Name
+	}
 
+	return summaries, nil
+}
+
+// GetCategoryRollup - Total transaksi per kategori tingkat atas, sub-kategori digabung ke induk teratasnya
+func (r *reportRepository) GetCategoryRollup(filters entity.ReportFilters, isAdmin bool, userID uint) ([]entity.CategoryRollup, error) {
+	var rows []struct {
+		TransactionID uint
+		CategoryID    uint
+		Quantity      int
+		TotalPrice    float64
+		Margin        float64
+	}
+
+	query := r.db.Table("transactions").
+		Select(`
+			transactions.id as transaction_id,
+			products.category_id as category_id,
+			sum(transaction_items.quantity) as quantity,
+			sum(transaction_items.quantity * transaction_items.price) as total_price,
+			sum(transaction_items.margin) as margin
+		`).
+		Joins("join transaction_items on transactions.id = transaction_items.transaction_id").
+		Joins("join products on transaction_items.product_id = products.id").
+		Joins("join users on transactions.user_id = users.id").
+		Group("transactions.id, products.category_id")
+	query = applyReportFilters(query, filters, isAdmin, userID)
+
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	categories, err := loadCategories(r.db)
+	if err != nil {
+		return nil, err
+	}
+
+	// Satu transaksi dengan beberapa sub-kategori dari induk yang sama hanya dihitung sekali
+	rollups := make(map[uint]*entity.CategoryRollup)
+	counted := make(map[[2]uint]bool)
+	for _, row := range rows {
+		rootID := categoryRootID(categories, row.CategoryID)
+		rollup, ok := rollups[rootID]
+		if !ok {
+			rollup = &entity.CategoryRollup{CategoryID: rootID, CategoryName: categories[rootID].Name}
+			rollups[rootID] = rollup
+		}
+		if key := [2]uint{rootID, row.TransactionID}; !counted[key] {
+			counted[key] = true
+			rollup.TransactionCount++
+		}
+		rollup.Quantity += row.Quantity
+		rollup.TotalPrice += row.TotalPrice
+		rollup.Margin += row.Margin
+	}
+
+	result := make([]entity.CategoryRollup, 0, len(rollups))
+	for _, rollup := range rollups {
+		result = append(result, *rollup)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].TotalPrice > result[j].TotalPrice })
+	return result, nil
+}
+
+// applyReportFilters - Filter tanggal, user dan produk yang sama untuk semua laporan transaksi
+func applyReportFilters(query *gorm.DB, filters entity.ReportFilters, isAdmin bool, userID uint) *gorm.DB {
 	if filters.StartDate != "" && filters.EndDate != "" {
 		query = query.Where("transactions.created_at BETWEEN ? AND ?", filters.StartDate, filters.EndDate)
 	}
@@ -52,14 +139,5 @@ func (r *reportRepository) GetTransactionsSummary(filters entity.ReportFilters,
 	if filters.ProductName != "" {
 		query = query.Where("products.name LIKE ?", "%"+filters.ProductName+"%")
 	}
-
-	// Pagination
-	offset := (filters.Page - 1) * filters.Limit
-	query = query.Limit(filters.Limit).Offset(offset)
-
-	if err := query.Scan(&summaries).Error; err != nil {
-		return nil, err
-	}
-
-	return summaries, nil
+	return query
 }
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// s3RequestTimeout - Batas waktu setiap operasi ke object storage
+const s3RequestTimeout = 30 * time.Second
+
+// S3Config - Konfigurasi storage S3-compatible (AWS S3, MinIO, R2, dsb.)
+type S3Config struct {
+	Endpoint  string
+	AccessKey string
+	SecretKey string
+	Bucket    string
+	Region    string
+	UseSSL    bool
+	// PathStyle - Pakai URL endpoint/bucket/key, dibutuhkan sebagian besar server S3-compatible
+	PathStyle bool
+	// PublicURL - Prefix URL publik (mis. CDN). Kosong berarti endpoint/bucket.
+	PublicURL string
+}
+
+type s3Storage struct {
+	client    *minio.Client
+	bucket    string
+	publicURL string
+}
+
+// NewS3Storage - Storage S3-compatible. Bucket dibuat otomatis jika belum ada.
+func NewS3Storage(config S3Config) (StorageRepository, error) {
+	lookup := minio.BucketLookupAuto
+	if config.PathStyle {
+		lookup = minio.BucketLookupPath
+	}
+	client, err := minio.New(config.Endpoint, &minio.Options{
+		Creds:        credentials.NewStaticV4(config.AccessKey, config.SecretKey, ""),
+		Secure:       config.UseSSL,
+		Region:       config.Region,
+		BucketLookup: lookup,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s3RequestTimeout)
+	defer cancel()
+	exists, err := client.BucketExists(ctx, config.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check bucket %s: %w", config.Bucket, err)
+	}
+	if !exists {
+		if err := client.MakeBucket(ctx, config.Bucket, minio.MakeBucketOptions{Region: config.Region}); err != nil {
+			return nil, fmt.Errorf("failed to create bucket %s: %w", config.Bucket, err)
+		}
+	}
+
+	publicURL := config.PublicURL
+	if publicURL == "" {
+		publicURL = client.EndpointURL().String() + "/" + config.Bucket
+	}
+	return &s3Storage{
+		client:    client,
+		bucket:    config.Bucket,
+		publicURL: strings.TrimSuffix(publicURL, "/"),
+	}, nil
+}
+
+func (s *s3Storage) Put(key string, data []byte, options PutOptions) error {
+	key, err := CleanObjectKey(key)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s3RequestTimeout)
+	defer cancel()
+	_, err = s.client.PutObject(ctx, s.bucket, key, bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{
+		ContentType:  options.ContentType,
+		CacheControl: options.CacheControl,
+	})
+	return err
+}
+
+// Get - Objek dibaca secara streaming, pemanggil wajib menutup reader
+func (s *s3Storage) Get(key string) (io.ReadCloser, error) {
+	key, err := CleanObjectKey(key)
+	if err != nil {
+		return nil, err
+	}
+	object, err := s.client.GetObject(context.Background(), s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, mapS3Error(err)
+	}
+	// GetObject baru menghubungi server saat dibaca, Stat dipakai agar objek yang tidak ada langsung terdeteksi
+	if _, err := object.Stat(); err != nil {
+		object.Close()
+		return nil, mapS3Error(err)
+	}
+	return object, nil
+}
+
+func (s *s3Storage) Delete(key string) error {
+	key, err := CleanObjectKey(key)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s3RequestTimeout)
+	defer cancel()
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
+
+func (s *s3Storage) SignedURL(key string, expiry time.Duration) (string, error) {
+	key, err := CleanObjectKey(key)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s3RequestTimeout)
+	defer cancel()
+	signed, err := s.client.PresignedGetObject(ctx, s.bucket, key, expiry, url.Values{})
+	if err != nil {
+		return "", err
+	}
+	return signed.String(), nil
+}
+
+func (s *s3Storage) PublicURL(key string) string {
+	return s.publicURL + "/" + escapeObjectKey(key)
+}
+
+// mapS3Error - Objek yang tidak ada dipetakan ke ErrObjectNotFound
+func mapS3Error(err error) error {
+	if code := minio.ToErrorResponse(err).Code; code == "NoSuchKey" || code == "NotFound" {
+		return ErrObjectNotFound
+	}
+	return err
+}
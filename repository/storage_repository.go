@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrObjectNotFound   = errors.New("object not found")
+	ErrInvalidObjectKey = errors.New("invalid object key")
+)
+
+// PutOptions - Metadata yang disimpan bersama objek
+type PutOptions struct {
+	ContentType  string
+	CacheControl string
+}
+
+// StorageRepository - Penyimpanan objek (gambar produk, file laporan) yang bisa dipakai bersama oleh beberapa instance
+type StorageRepository interface {
+	Put(key string, data []byte, options PutOptions) error
+	Get(key string) (io.ReadCloser, error)
+	Delete(key string) error
+	SignedURL(key string, expiry time.Duration) (string, error)
+	PublicURL(key string) string
+}
+
+// SignedURLVerifier - Diimplementasikan storage yang URL bertandatangannya dilayani oleh aplikasi sendiri
+type SignedURLVerifier interface {
+	VerifySignedURL(key string, expires string, signature string) bool
+}
+
+type localStorage struct {
+	dir           string
+	publicURL     string
+	signedURLBase string
+	signingKey    []byte
+}
+
+// NewLocalStorage - Storage di filesystem lokal. publicURL dan signedURLBase adalah prefix URL yang dilayani aplikasi.
+func NewLocalStorage(dir string, publicURL string, signedURLBase string, signingKey []byte) StorageRepository {
+	return &localStorage{
+		dir:           dir,
+		publicURL:     strings.TrimSuffix(publicURL, "/"),
+		signedURLBase: strings.TrimSuffix(signedURLBase, "/"),
+		signingKey:    signingKey,
+	}
+}
+
+// Put - Menulis lewat file sementara lalu rename agar pembaca tidak pernah melihat file setengah jadi
+func (s *localStorage) Put(key string, data []byte, options PutOptions) error {
+	filePath, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(filePath)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(dir, ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(temp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), filePath)
+}
+
+func (s *localStorage) Get(key string) (io.ReadCloser, error) {
+	filePath, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrObjectNotFound
+	}
+	return file, err
+}
+
+func (s *localStorage) Delete(key string) error {
+	filePath, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *localStorage) SignedURL(key string, expiry time.Duration) (string, error) {
+	key, err := CleanObjectKey(key)
+	if err != nil {
+		return "", err
+	}
+	expires := strconv.FormatInt(time.Now().Add(expiry).Unix(), 10)
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("signature", s.sign(key, expires))
+	return s.signedURLBase + "/" + escapeObjectKey(key) + "?" + query.Encode(), nil
+}
+
+func (s *localStorage) PublicURL(key string) string {
+	return s.publicURL + "/" + escapeObjectKey(key)
+}
+
+// VerifySignedURL - Memeriksa tanda tangan HMAC dan masa berlaku URL dari SignedURL
+func (s *localStorage) VerifySignedURL(key string, expires string, signature string) bool {
+	key, err := CleanObjectKey(key)
+	if err != nil {
+		return false
+	}
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > unix {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(s.sign(key, expires)))
+}
+
+func (s *localStorage) sign(key string, expires string) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *localStorage) path(key string) (string, error) {
+	key, err := CleanObjectKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
+
+// CleanObjectKey - Key objek harus path relatif tanpa ".." agar tidak bisa keluar dari direktori/bucket
+func CleanObjectKey(key string) (string, error) {
+	cleaned := path.Clean(strings.TrimPrefix(key, "/"))
+	if key == "" || cleaned == "." || hasParentSegment(key) || strings.ContainsRune(key, '\\') {
+		return "", fmt.Errorf("%w: %q", ErrInvalidObjectKey, key)
+	}
+	return cleaned, nil
+}
+
+// hasParentSegment - Key yang memuat segmen ".." ditolak meskipun hasil Clean-nya tetap di dalam root,
+// agar pengecekan prefix (mis. "products/../reports/...") tidak bisa dilewati
+func hasParentSegment(key string) bool {
+	for _, segment := range strings.Split(key, "/") {
+		if segment == ".." {
+			return true
+		}
+	}
+	return false
+}
+
+// escapeObjectKey - Meng-escape tiap segmen key tanpa mengubah pemisah "/"
+func escapeObjectKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"main.go/entity"
+)
+
+type TextCommandRepository interface {
+	CreateCommand(command *entity.CommandTemplate) error
+	GetAllCommands() ([]entity.CommandTemplate, error)
+	GetCommandByID(id uint) (*entity.CommandTemplate, error)
+	UpdateCommand(command *entity.CommandTemplate) error
+	DeleteCommand(id uint) error
+
+	GetAllReplies() ([]entity.ReplyTemplate, error)
+	UpsertReply(reply *entity.ReplyTemplate) error
+}
+
+type textCommandRepository struct {
+	db *gorm.DB
+}
+
+func NewTextCommandRepository(db *gorm.DB) TextCommandRepository {
+	return &textCommandRepository{db: db}
+}
+
+func (r *textCommandRepository) CreateCommand(command *entity.CommandTemplate) error {
+	return createWithActiveFlag(r.db, command, command.IsActive)
+}
+
+func (r *textCommandRepository) GetAllCommands() ([]entity.CommandTemplate, error) {
+	var commands []entity.CommandTemplate
+	if err := r.db.Order("priority DESC, id ASC").Find(&commands).Error; err != nil {
+		return nil, err
+	}
+	return commands, nil
+}
+
+func (r *textCommandRepository) GetCommandByID(id uint) (*entity.CommandTemplate, error) {
+	var command entity.CommandTemplate
+	if err := r.db.First(&command, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("command template not found")
+		}
+		return nil, err
+	}
+	return &command, nil
+}
+
+func (r *textCommandRepository) UpdateCommand(command *entity.CommandTemplate) error {
+	return r.db.Model(command).Select("*").Omit("id", "created_at").Updates(command).Error
+}
+
+func (r *textCommandRepository) DeleteCommand(id uint) error {
+	return r.db.Delete(&entity.CommandTemplate{}, id).Error
+}
+
+func (r *textCommandRepository) GetAllReplies() ([]entity.ReplyTemplate, error) {
+	var replies []entity.ReplyTemplate
+	if err := r.db.Order("`key` ASC").Find(&replies).Error; err != nil {
+		return nil, err
+	}
+	return replies, nil
+}
+
+func (r *textCommandRepository) UpsertReply(reply *entity.ReplyTemplate) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"template", "updated_at"}),
+	}).Create(reply).Error
+}
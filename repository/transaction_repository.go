@@ -1,17 +1,23 @@
 package repository
 
 import (
+	"errors"
+
 	"gorm.io/gorm"
 	"main.go/entity"
 )
 
+// ErrDuplicateReference - Reference ID sudah dipakai transaksi lain milik user yang sama
+var ErrDuplicateReference = errors.New("reference_id has already been used")
+
 type TransactionsRepository interface {
 	Create(transaction *entity.Transaction) error
 	GetByID(id uint) (*entity.Transaction, error)
 	GetAllByUserID(userID uint) ([]entity.Transaction, error)
 	GetAll() ([]entity.Transaction, error) // ✅ Tambahkan method ini
 	Update(transaction *entity.Transaction) error
-	Delete(id uint) error
+	GetByReference(userID uint, referenceID string) (*entity.Transaction, error)
+	Delete(id uint, version uint) error
 }
 
 type transactionsRepository struct {
@@ -24,7 +30,12 @@ func NewTransactionsRepository(db *gorm.DB) TransactionsRepository {
 
 // ✅ Create - Membuat transaksi baru
 func (r *transactionsRepository) Create(transaction *entity.Transaction) error {
+	transaction.Version = 1
 	if err := r.db.Create(transaction).Error; err != nil {
+		// Pengecekan reference_id di service tidak atomik, index unik yang menjadi penentu akhirnya
+		if transaction.ReferenceID != nil && errors.Is(err, gorm.ErrDuplicatedKey) {
+			return ErrDuplicateReference
+		}
 		return err
 	}
 	return nil
@@ -34,7 +45,7 @@ func (r *transactionsRepository) Create(transaction *entity.Transaction) error {
 func (r *transactionsRepository) GetByID(id uint) (*entity.Transaction, error) {
 	var transaction entity.Transaction
 	err := r.db.Preload("User", func(db *gorm.DB) *gorm.DB {
-		return db.Select("id, username, email, role")
+		return db.Select("id, full_name, email, role")
 	}).Preload("Items.Product.Category").First(&transaction, id).Error
 
 	if err != nil {
@@ -43,6 +54,18 @@ func (r *transactionsRepository) GetByID(id uint) (*entity.Transaction, error) {
 	return &transaction, nil
 }
 
+// GetByReference - Mengambil transaksi berdasarkan reference ID milik user
+func (r *transactionsRepository) GetByReference(userID uint, referenceID string) (*entity.Transaction, error) {
+	var transaction entity.Transaction
+	err := r.db.Preload("Items.Product.Category").
+		Where("user_id = ? AND reference_id = ?", userID, referenceID).
+		First(&transaction).Error
+	if err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
 // ✅ GetAllByUserID - Mengambil semua transaksi berdasarkan User ID
 func (r *transactionsRepository) GetAllByUserID(userID uint) ([]entity.Transaction, error) {
 	var transactions []entity.Transaction
@@ -64,17 +87,17 @@ func (r *transactionsRepository) GetAll() ([]entity.Transaction, error) {
 	return transactions, nil
 }
 
-// ✅ Update - Mengupdate transaksi
+// ✅ Update - Mengupdate transaksi, ErrVersionConflict jika transaksi sudah diubah request lain
 func (r *transactionsRepository) Update(transaction *entity.Transaction) error {
-	if err := r.db.Save(transaction).Error; err != nil {
-		return err
-	}
-	return nil
+	return saveVersioned(r.db, transaction, &transaction.Version)
 }
 
-// ✅ Delete - Menghapus transaksi berdasarkan ID
-func (r *transactionsRepository) Delete(id uint) error {
-	if err := r.db.Delete(&entity.Transaction{}, id).Error; err != nil {
+// ✅ Delete - Menghapus transaksi berdasarkan ID, version 0 berarti tanpa pengecekan version
+func (r *transactionsRepository) Delete(id uint, version uint) error {
+	if err := deleteVersioned(r.db, &entity.Transaction{}, id, version); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("transaction not found")
+		}
 		return err
 	}
 	return nil
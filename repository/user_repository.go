@@ -1,8 +1,12 @@
 package repository
 
 import (
+	"errors"
+	"time"
+
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"main.go/entity"
 	"main.go/middleware"
 )
@@ -13,6 +17,13 @@ type UserRepository interface {
 	Create(user *entity.User) error
 	Update(user *entity.User) error
 	FindByPhoneNumber(phone string) (*entity.User, error)
+	AddBalance(userID uint, amount float64) error
+	FindByReferralCode(code string) (*entity.User, error)
+	SetReferralCode(userID uint, code string) error
+	GetDownlines(uplineID uint) ([]entity.DownlineInfo, error)
+	UpdatePinState(userID uint, fields map[string]interface{}) error
+	ReservePinAttempt(userID uint, maxAttempts int) (int, error)
+	UpdateProfile(userID uint, fields map[string]interface{}) error
 }
 
 type userRepository struct {
@@ -72,3 +83,89 @@ func (r *userRepository) FindByPhoneNumber(phone string) (*entity.User, error) {
 	}
 	return &user, nil
 }
+
+// AddBalance - Menambah (atau mengurangi jika negatif) saldo user secara atomik
+func (r *userRepository) AddBalance(userID uint, amount float64) error {
+	middleware.Logger.Info("Repository: Adjusting user balance", zap.Uint("user_id", userID), zap.Float64("amount", amount))
+	result := r.db.Model(&entity.User{}).
+		Where("id = ? AND balance + ? >= 0", userID, amount).
+		UpdateColumn("balance", gorm.Expr("balance + ?", amount))
+	if result.Error != nil {
+		middleware.Logger.Error("Repository: Error adjusting user balance", zap.Error(result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("insufficient balance")
+	}
+	return nil
+}
+
+func (r *userRepository) FindByReferralCode(code string) (*entity.User, error) {
+	var user entity.User
+	if err := r.db.Where("referral_code = ?", code).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// SetReferralCode - Mengisi kode referral hanya jika user belum memilikinya
+func (r *userRepository) SetReferralCode(userID uint, code string) error {
+	return r.db.Model(&entity.User{}).
+		Where("id = ? AND referral_code IS NULL", userID).
+		UpdateColumn("referral_code", code).Error
+}
+
+// GetDownlines - Downline langsung beserta jumlah downline masing-masing
+func (r *userRepository) GetDownlines(uplineID uint) ([]entity.DownlineInfo, error) {
+	var downlines []entity.DownlineInfo
+	err := r.db.Table("users").
+		Select("users.id, users.full_name, users.created_at as joined_at, (SELECT COUNT(*) FROM users d WHERE d.upline_id = users.id) as downlines").
+		Where("users.upline_id = ?", uplineID).
+		Order("users.created_at DESC").
+		Scan(&downlines).Error
+	return downlines, err
+}
+
+// UpdatePinState - Memperbarui kolom PIN (hash, jumlah gagal, waktu kunci) tanpa menyentuh kolom lain
+func (r *userRepository) UpdatePinState(userID uint, fields map[string]interface{}) error {
+	return r.db.Model(&entity.User{}).Where("id = ?", userID).UpdateColumns(fields).Error
+}
+
+// PinLockedError - PIN sedang dikunci atau jatah percobaan sudah habis
+type PinLockedError struct {
+	LockedUntil *time.Time
+}
+
+func (e *PinLockedError) Error() string {
+	return "PIN is locked"
+}
+
+// ReservePinAttempt - Menaikkan jumlah percobaan PIN sebelum PIN dicek, dengan baris user dikunci
+// sehingga percobaan paralel tidak bisa melewati batas. Mengembalikan jumlah percobaan setelah dinaikkan.
+func (r *userRepository) ReservePinAttempt(userID uint, maxAttempts int) (int, error) {
+	attempts := 0
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var user entity.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "pin_failed_attempts", "pin_locked_until").
+			First(&user, userID).Error; err != nil {
+			return err
+		}
+		if user.PinLockedUntil != nil && user.PinLockedUntil.After(time.Now()) {
+			return &PinLockedError{LockedUntil: user.PinLockedUntil}
+		}
+		if user.PinFailedAttempts >= maxAttempts {
+			return &PinLockedError{}
+		}
+
+		attempts = user.PinFailedAttempts + 1
+		return tx.Model(&entity.User{}).Where("id = ?", userID).
+			UpdateColumn("pin_failed_attempts", gorm.Expr("pin_failed_attempts + 1")).Error
+	})
+	return attempts, err
+}
+
+// UpdateProfile - Mengubah kolom profil tertentu saja tanpa menimpa kolom lain (saldo, PIN)
+func (r *userRepository) UpdateProfile(userID uint, fields map[string]interface{}) error {
+	return r.db.Model(&entity.User{}).Where("id = ?", userID).Updates(fields).Error
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrVersionConflict - Data sudah diubah request lain sejak dibaca (optimistic concurrency)
+var ErrVersionConflict = errors.New("resource was modified by another request")
+
+// versionIncrement - Ekspresi untuk menaikkan kolom version pada update massal/parsial
+var versionIncrement = gorm.Expr("version + 1")
+
+// saveVersioned - Menyimpan semua kolom hanya jika version di database masih sama dengan *version,
+// lalu menaikkan version. Relasi tidak ikut disimpan.
+func saveVersioned(db *gorm.DB, value interface{}, version *uint) error {
+	current := *version
+	*version = current + 1
+	result := db.Omit(clause.Associations).Model(value).Where("version = ?", current).Select("*").Updates(value)
+	if result.Error != nil {
+		*version = current
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		*version = current
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+// deleteVersioned - Menghapus baris berdasarkan ID, version 0 berarti tanpa pengecekan version.
+// Mengembalikan gorm.ErrRecordNotFound jika baris tidak ada dan ErrVersionConflict jika version berbeda.
+func deleteVersioned(db *gorm.DB, model interface{}, id uint, version uint) error {
+	query := db.Where("id = ?", id)
+	if version > 0 {
+		query = query.Where("version = ?", version)
+	}
+	result := query.Delete(model)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+
+	var count int64
+	if err := db.Model(model).Where("id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrVersionConflict
+	}
+	return gorm.ErrRecordNotFound
+}
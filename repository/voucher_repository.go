@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"main.go/entity"
+)
+
+// ErrInsufficientVoucherCodes - Kode voucher yang tersedia tidak mencukupi
+var ErrInsufficientVoucherCodes = errors.New("insufficient voucher codes")
+
+type VoucherRepository interface {
+	ExistingHashes(hashes []string) (map[string]bool, error)
+	CreateBatch(batch *entity.VoucherBatch, codes []entity.VoucherCode) (int, error)
+	GetBatchesByProduct(productID uint) ([]entity.VoucherBatch, error)
+	CountAvailable(productID uint) (int64, error)
+	AssignCodes(transactionID uint, userID uint, quantities map[uint]int) (map[uint][]entity.VoucherCode, error)
+	GetAssignments(productID uint, userID uint) ([]entity.VoucherCode, error)
+	MigrateCodes(version int, migrate func(code entity.VoucherCode) (string, string, error)) (int, int, error)
+}
+
+type voucherRepository struct {
+	db *gorm.DB
+}
+
+func NewVoucherRepository(db *gorm.DB) VoucherRepository {
+	return &voucherRepository{db: db}
+}
+
+// ExistingHashes - Mengembalikan hash kode yang sudah tersimpan
+func (r *voucherRepository) ExistingHashes(hashes []string) (map[string]bool, error) {
+	existing := make(map[string]bool)
+	if len(hashes) == 0 {
+		return existing, nil
+	}
+
+	var found []string
+	if err := r.db.Model(&entity.VoucherCode{}).Where("code_hash IN ?", hashes).Pluck("code_hash", &found).Error; err != nil {
+		return nil, err
+	}
+	for _, h := range found {
+		existing[h] = true
+	}
+	return existing, nil
+}
+
+// CreateBatch - Menyimpan batch beserta kode-kodenya dalam satu transaksi database
+func (r *voucherRepository) CreateBatch(batch *entity.VoucherBatch, codes []entity.VoucherCode) (int, error) {
+	imported := 0
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(batch).Error; err != nil {
+			return err
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+
+		for i := range codes {
+			codes[i].BatchID = batch.ID
+		}
+
+		// Kode yang bentrok (diimpor bersamaan oleh admin lain) dilewati, bukan menggagalkan batch
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(codes, 500)
+		if result.Error != nil {
+			return result.Error
+		}
+		imported = int(result.RowsAffected)
+
+		batch.Duplicates += len(codes) - imported
+		batch.TotalCodes = imported
+		return tx.Model(batch).Select("total_codes", "duplicates").Updates(batch).Error
+	})
+	return imported, err
+}
+
+func (r *voucherRepository) GetBatchesByProduct(productID uint) ([]entity.VoucherBatch, error) {
+	var batches []entity.VoucherBatch
+	if err := r.db.Where("product_id = ?", productID).Order("created_at DESC").Find(&batches).Error; err != nil {
+		return nil, err
+	}
+	return batches, nil
+}
+
+func (r *voucherRepository) CountAvailable(productID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&entity.VoucherCode{}).
+		Where("product_id = ? AND status = ?", productID, entity.VoucherCodeAvailable).
+		Count(&count).Error
+	return count, err
+}
+
+// AssignCodes - Mengambil kode yang belum terpakai untuk setiap produk (product_id -> quantity) secara atomik
+func (r *voucherRepository) AssignCodes(transactionID uint, userID uint, quantities map[uint]int) (map[uint][]entity.VoucherCode, error) {
+	assigned := make(map[uint][]entity.VoucherCode)
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		for productID, quantity := range quantities {
+			var codes []entity.VoucherCode
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where("product_id = ? AND status = ?", productID, entity.VoucherCodeAvailable).
+				Order("id").
+				Limit(quantity).
+				Find(&codes).Error; err != nil {
+				return err
+			}
+			if len(codes) < quantity {
+				return ErrInsufficientVoucherCodes
+			}
+
+			ids := make([]uint, len(codes))
+			for i := range codes {
+				ids[i] = codes[i].ID
+				codes[i].Status = entity.VoucherCodeAssigned
+				codes[i].TransactionID = &transactionID
+				codes[i].UserID = &userID
+				codes[i].AssignedAt = &now
+			}
+
+			if err := tx.Model(&entity.VoucherCode{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+				"status":         entity.VoucherCodeAssigned,
+				"transaction_id": transactionID,
+				"user_id":        userID,
+				"assigned_at":    now,
+			}).Error; err != nil {
+				return err
+			}
+			assigned[productID] = codes
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return assigned, nil
+}
+
+// GetAssignments - Jejak audit kode yang sudah diberikan, bisa difilter per produk dan/atau user
+func (r *voucherRepository) GetAssignments(productID uint, userID uint) ([]entity.VoucherCode, error) {
+	query := r.db.Where("status = ?", entity.VoucherCodeAssigned)
+	if productID != 0 {
+		query = query.Where("product_id = ?", productID)
+	}
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	var codes []entity.VoucherCode
+	if err := query.Order("assigned_at DESC").Find(&codes).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// MigrateCodes - Memigrasikan kode dengan hash_version di bawah version, per 500 baris dalam satu transaksi.
+// migrate mengembalikan CodeHash dan MaskedCode baru; baris yang gagal dilewati (tetap di versi lama)
+// dan dihitung di failed, sehingga baris yang sudah dimigrasikan tidak diproses lagi pada startup berikutnya.
+func (r *voucherRepository) MigrateCodes(version int, migrate func(code entity.VoucherCode) (string, string, error)) (int, int, error) {
+	migrated, failed := 0, 0
+	var lastID uint
+	for {
+		var codes []entity.VoucherCode
+		if err := r.db.Select("id", "code", "code_hash", "masked_code").
+			Where("hash_version < ? AND id > ?", version, lastID).
+			Order("id ASC").Limit(500).
+			Find(&codes).Error; err != nil {
+			return migrated, failed, err
+		}
+		if len(codes) == 0 {
+			return migrated, failed, nil
+		}
+		lastID = codes[len(codes)-1].ID
+
+		err := r.db.Transaction(func(tx *gorm.DB) error {
+			for _, code := range codes {
+				hash, masked, err := migrate(code)
+				if err != nil {
+					failed++
+					continue
+				}
+				if err := tx.Model(&entity.VoucherCode{}).Where("id = ?", code.ID).Updates(map[string]interface{}{
+					"code_hash":    hash,
+					"masked_code":  masked,
+					"hash_version": version,
+				}).Error; err != nil {
+					return err
+				}
+				migrated++
+			}
+			return nil
+		})
+		if err != nil {
+			return migrated, failed, err
+		}
+	}
+}
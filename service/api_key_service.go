@@ -0,0 +1,161 @@
+package service
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"main.go/entity"
+	"main.go/middleware"
+	"main.go/repository"
+)
+
+// maxAPIKeysPerUser - Batas API key aktif per user
+const maxAPIKeysPerUser = 10
+
+type APIKeyService interface {
+	CreateKey(userID uint, request entity.APIKeyRequest) (*entity.APIKeyCreated, error)
+	ListKeys(userID uint) ([]entity.APIKey, error)
+	RevokeKey(userID uint, id uint) error
+
+	middleware.APIKeyResolver
+}
+
+type apiKeyService struct {
+	repo               repository.APIKeyRepository
+	activityLogService ActivityLogService
+}
+
+func NewAPIKeyService(repo repository.APIKeyRepository, activityLogService ActivityLogService) APIKeyService {
+	return &apiKeyService{
+		repo:               repo,
+		activityLogService: activityLogService,
+	}
+}
+
+// CreateKey - Membuat API key baru; secret hanya dikembalikan sekali pada response ini
+func (s *apiKeyService) CreateKey(userID uint, request entity.APIKeyRequest) (*entity.APIKeyCreated, error) {
+	request.Name = strings.TrimSpace(request.Name)
+	if request.Name == "" {
+		return nil, middleware.NewAppError(400, "API key name is required", nil)
+	}
+	scopes, err := normalizeScopes(request.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range request.AllowedIPs {
+		if net.ParseIP(entry) == nil {
+			if _, _, err := net.ParseCIDR(entry); err != nil {
+				return nil, middleware.NewAppError(400, fmt.Sprintf("Invalid IP or CIDR %q", entry), nil)
+			}
+		}
+	}
+
+	existing, err := s.repo.GetByUser(userID)
+	if err != nil {
+		return nil, middleware.NewAppError(500, "Failed to fetch API keys", err)
+	}
+	active := 0
+	for _, key := range existing {
+		if key.RevokedAt == nil {
+			active++
+		}
+	}
+	if active >= maxAPIKeysPerUser {
+		return nil, middleware.NewAppError(409, fmt.Sprintf("A user can have at most %d active API keys", maxAPIKeysPerUser), nil)
+	}
+
+	keyID, err := randomHex(12)
+	if err != nil {
+		return nil, middleware.NewAppError(500, "Failed to generate API key", err)
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return nil, middleware.NewAppError(500, "Failed to generate API key", err)
+	}
+	encrypted, err := encryptSecret(secret)
+	if err != nil {
+		return nil, middleware.NewAppError(500, "Failed to secure API key", err)
+	}
+
+	key := entity.APIKey{
+		UserID:     userID,
+		Name:       request.Name,
+		KeyID:      "tk_" + keyID,
+		Secret:     encrypted,
+		Scopes:     scopes,
+		AllowedIPs: request.AllowedIPs,
+	}
+	if err := s.repo.Create(&key); err != nil {
+		return nil, middleware.NewAppError(500, "Failed to create API key", err)
+	}
+
+	details := fmt.Sprintf("API Key ID: %d, Key: %s, Scopes: %s", key.ID, key.KeyID, strings.Join(scopes, ","))
+	if err := s.activityLogService.CreateActivityLog(userID, "API Key Created", details); err != nil {
+		middleware.Logger.Error("Failed to create activity log", zap.Error(err))
+	}
+
+	return &entity.APIKeyCreated{APIKey: key, Secret: secret}, nil
+}
+
+func (s *apiKeyService) ListKeys(userID uint) ([]entity.APIKey, error) {
+	return s.repo.GetByUser(userID)
+}
+
+func (s *apiKeyService) RevokeKey(userID uint, id uint) error {
+	if err := s.repo.Revoke(userID, id); err != nil {
+		return middleware.NewAppError(404, err.Error(), err)
+	}
+
+	details := fmt.Sprintf("API Key ID: %d", id)
+	if err := s.activityLogService.CreateActivityLog(userID, "API Key Revoked", details); err != nil {
+		middleware.Logger.Error("Failed to create activity log", zap.Error(err))
+	}
+	return nil
+}
+
+// ResolveAPIKey - Dipakai middleware.AuthorizeAPIKey untuk memverifikasi signature
+func (s *apiKeyService) ResolveAPIKey(keyID string) (*entity.APIKey, string, error) {
+	key, err := s.repo.GetByKeyID(keyID)
+	if err != nil {
+		return nil, "", err
+	}
+	secret, err := decryptSecret(key.Secret)
+	if err != nil {
+		middleware.Logger.Error("Service: Failed to decrypt API key secret", zap.Uint("api_key_id", key.ID), zap.Error(err))
+		return nil, "", err
+	}
+	return key, secret, nil
+}
+
+func (s *apiKeyService) MarkAPIKeyUsed(key *entity.APIKey) {
+	if err := s.repo.TouchLastUsed(key.ID, time.Now()); err != nil {
+		middleware.Logger.Warn("Service: Failed to update API key last use", zap.Uint("api_key_id", key.ID), zap.Error(err))
+	}
+}
+
+func normalizeScopes(scopes []string) ([]string, error) {
+	valid := map[string]bool{
+		entity.ScopePurchase: true,
+		entity.ScopeStatus:   true,
+		entity.ScopeBalance:  true,
+	}
+	seen := make(map[string]bool)
+	var normalized []string
+	for _, scope := range scopes {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if !valid[scope] {
+			return nil, middleware.NewAppError(400, fmt.Sprintf("Unknown scope %q", scope), nil)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			normalized = append(normalized, scope)
+		}
+	}
+	if len(normalized) == 0 {
+		return nil, middleware.NewAppError(400, "At least one scope is required", nil)
+	}
+	return normalized, nil
+}
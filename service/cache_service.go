@@ -0,0 +1,43 @@
+package service
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+	"main.go/entity"
+	"main.go/middleware"
+	"main.go/repository"
+)
+
+type CacheService interface {
+	GetStats() entity.CacheStats
+	Flush(adminID uint) entity.CacheStats
+}
+
+type cacheService struct {
+	catalogCache       repository.CatalogCache
+	activityLogService ActivityLogService
+}
+
+func NewCacheService(catalogCache repository.CatalogCache, activityLogService ActivityLogService) CacheService {
+	return &cacheService{
+		catalogCache:       catalogCache,
+		activityLogService: activityLogService,
+	}
+}
+
+func (s *cacheService) GetStats() entity.CacheStats {
+	return s.catalogCache.Stats()
+}
+
+// Flush - Mengosongkan cache katalog, mis. setelah data produk diubah langsung di database
+func (s *cacheService) Flush(adminID uint) entity.CacheStats {
+	before := s.catalogCache.Stats()
+	s.catalogCache.Flush()
+
+	details := fmt.Sprintf("Items: %d, Hits: %d, Misses: %d", before.Items, before.Hits, before.Misses)
+	if err := s.activityLogService.CreateActivityLog(adminID, "Catalog Cache Flushed", details); err != nil {
+		middleware.Logger.Error("Failed to create activity log", zap.Error(err))
+	}
+	return s.catalogCache.Stats()
+}
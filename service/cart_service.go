@@ -0,0 +1,228 @@
+package service
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+	"main.go/entity"
+	"main.go/middleware"
+	"main.go/repository"
+)
+
+// maxCartQuantity - Batas jumlah satu produk di keranjang
+const maxCartQuantity = 100
+
+type CartService interface {
+	GetCart(userID uint) (*entity.CartView, error)
+	AddItem(userID uint, request entity.CartItemRequest) (*entity.CartView, error)
+	UpdateItem(userID uint, request entity.CartItemRequest) (*entity.CartView, error)
+	RemoveItem(userID uint, productID uint) (*entity.CartView, error)
+	Clear(userID uint) error
+	Checkout(userID uint, request entity.CartCheckoutRequest) (*entity.Transaction, error)
+}
+
+type cartService struct {
+	repo               repository.CartRepository
+	productRepo        repository.ProductRepository
+	priceGroupService  PriceGroupService
+	transactionService TransactionsService
+}
+
+func NewCartService(repo repository.CartRepository, productRepo repository.ProductRepository, priceGroupService PriceGroupService, transactionService TransactionsService) CartService {
+	return &cartService{
+		repo:               repo,
+		productRepo:        productRepo,
+		priceGroupService:  priceGroupService,
+		transactionService: transactionService,
+	}
+}
+
+func (s *cartService) GetCart(userID uint) (*entity.CartView, error) {
+	cart, err := s.repo.GetOrCreate(userID)
+	if err != nil {
+		return nil, middleware.NewAppError(500, "Failed to fetch cart", err)
+	}
+	return s.buildView(userID, cart)
+}
+
+// AddItem - Menambah jumlah produk di keranjang (produk baru dimasukkan)
+func (s *cartService) AddItem(userID uint, request entity.CartItemRequest) (*entity.CartView, error) {
+	if request.Quantity <= 0 {
+		return nil, middleware.NewAppError(400, "Quantity must be greater than 0", nil)
+	}
+	cart, err := s.repo.GetOrCreate(userID)
+	if err != nil {
+		return nil, middleware.NewAppError(500, "Failed to fetch cart", err)
+	}
+
+	quantity := request.Quantity
+	for _, item := range cart.Items {
+		if item.ProductID == request.ProductID {
+			quantity += item.Quantity
+		}
+	}
+	return s.setQuantity(userID, cart, request.ProductID, quantity)
+}
+
+// UpdateItem - Mengganti jumlah produk di keranjang; jumlah 0 menghapus item
+func (s *cartService) UpdateItem(userID uint, request entity.CartItemRequest) (*entity.CartView, error) {
+	if request.Quantity < 0 {
+		return nil, middleware.NewAppError(400, "Quantity cannot be negative", nil)
+	}
+	if request.Quantity == 0 {
+		return s.RemoveItem(userID, request.ProductID)
+	}
+	cart, err := s.repo.GetOrCreate(userID)
+	if err != nil {
+		return nil, middleware.NewAppError(500, "Failed to fetch cart", err)
+	}
+
+	inCart := false
+	for _, item := range cart.Items {
+		if item.ProductID == request.ProductID {
+			inCart = true
+		}
+	}
+	if !inCart {
+		return nil, middleware.NewAppError(404, "Product is not in the cart", nil)
+	}
+	return s.setQuantity(userID, cart, request.ProductID, request.Quantity)
+}
+
+func (s *cartService) RemoveItem(userID uint, productID uint) (*entity.CartView, error) {
+	cart, err := s.repo.GetOrCreate(userID)
+	if err != nil {
+		return nil, middleware.NewAppError(500, "Failed to fetch cart", err)
+	}
+	if err := s.repo.RemoveItem(cart.ID, productID); err != nil {
+		return nil, middleware.NewAppError(500, "Failed to remove cart item", err)
+	}
+	return s.GetCart(userID)
+}
+
+func (s *cartService) Clear(userID uint) error {
+	cart, err := s.repo.GetOrCreate(userID)
+	if err != nil {
+		return middleware.NewAppError(500, "Failed to fetch cart", err)
+	}
+	if err := s.repo.Clear(cart.ID); err != nil {
+		return middleware.NewAppError(500, "Failed to clear cart", err)
+	}
+	return nil
+}
+
+// Checkout - Memvalidasi ulang keranjang lalu membuat transaksi lewat TransactionsService.
+// Keranjang dikosongkan hanya jika transaksi berhasil dibuat.
+func (s *cartService) Checkout(userID uint, request entity.CartCheckoutRequest) (*entity.Transaction, error) {
+	cart, err := s.repo.GetOrCreate(userID)
+	if err != nil {
+		return nil, middleware.NewAppError(500, "Failed to fetch cart", err)
+	}
+	if len(cart.Items) == 0 {
+		return nil, middleware.NewAppError(400, "Cart is empty", nil)
+	}
+
+	view, err := s.buildView(userID, cart)
+	if err != nil {
+		return nil, err
+	}
+	if !view.CanCheckout {
+		for _, line := range view.Items {
+			if !line.Available {
+				return nil, middleware.NewAppError(409, fmt.Sprintf("%s: %s", line.Name, line.Message), nil)
+			}
+		}
+	}
+
+	transactionRequest := entity.TransactionRequest{
+		UserID:            userID,
+		DestinationNumber: request.DestinationNumber,
+		FavoriteID:        request.FavoriteID,
+		CustomerInputs:    request.CustomerInputs,
+		PromoCode:         request.PromoCode,
+		ReferenceID:       request.ReferenceID,
+		Pin:               request.Pin,
+	}
+	for _, item := range cart.Items {
+		transactionRequest.Items = append(transactionRequest.Items, entity.TransactionItemRequest{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+		})
+	}
+
+	transaction, err := s.transactionService.CreateTransaction(&transactionRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Clear(cart.ID); err != nil {
+		middleware.Logger.Error("Service: Failed to clear cart after checkout", zap.Uint("user_id", userID), zap.Error(err))
+	}
+	middleware.Logger.Info("Service: Cart checked out", zap.Uint("user_id", userID), zap.Uint("transaction_id", transaction.ID))
+	return transaction, nil
+}
+
+func (s *cartService) setQuantity(userID uint, cart *entity.Cart, productID uint, quantity int) (*entity.CartView, error) {
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, middleware.NewAppError(404, "Product not found", err)
+	}
+	if !product.IsActive {
+		return nil, middleware.NewAppError(400, fmt.Sprintf("%s is not available", product.Name), nil)
+	}
+	if quantity > maxCartQuantity {
+		return nil, middleware.NewAppError(400, fmt.Sprintf("Maximum quantity per product is %d", maxCartQuantity), nil)
+	}
+	if quantity > product.Stock {
+		return nil, middleware.NewAppError(409, fmt.Sprintf("Only %d %s left in stock", product.Stock, product.Name), nil)
+	}
+
+	if err := s.repo.SetItemQuantity(cart.ID, productID, quantity); err != nil {
+		return nil, middleware.NewAppError(500, "Failed to update cart", err)
+	}
+	return s.GetCart(userID)
+}
+
+// buildView - Menghitung harga terkini (sesuai price group user) dan memeriksa stok setiap item
+func (s *cartService) buildView(userID uint, cart *entity.Cart) (*entity.CartView, error) {
+	products := make([]entity.Product, len(cart.Items))
+	for i, item := range cart.Items {
+		products[i] = item.Product
+	}
+	if err := s.priceGroupService.ApplyUserPrices(userID, products); err != nil {
+		return nil, err
+	}
+
+	view := &entity.CartView{Items: []entity.CartLine{}, CanCheckout: len(cart.Items) > 0}
+	for i, item := range cart.Items {
+		product := products[i]
+		line := entity.CartLine{
+			ProductID: item.ProductID,
+			Name:      product.Name,
+			Quantity:  item.Quantity,
+			UnitPrice: product.Price,
+			Subtotal:  product.Price * float64(item.Quantity),
+			Stock:     product.Stock,
+			Available: true,
+		}
+
+		switch {
+		case product.ID == 0 || !product.IsActive:
+			line.Available = false
+			line.Message = "product is no longer available"
+			line.UnitPrice, line.Subtotal = 0, 0
+		case product.Stock < item.Quantity:
+			line.Available = false
+			line.Message = fmt.Sprintf("only %d left in stock", product.Stock)
+		}
+
+		if !line.Available {
+			view.CanCheckout = false
+		} else {
+			view.Total += line.Subtotal
+			view.TotalItems += line.Quantity
+		}
+		view.Items = append(view.Items, line)
+	}
+	return view, nil
+}
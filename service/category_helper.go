@@ -0,0 +1,10 @@
+package service
+
+// categoryChain - Kategori beserta induk-induknya (terdekat lebih dulu) dari hasil GetCategoryAncestorIDs,
+// sehingga aturan pada kategori induk ikut berlaku untuk produk di sub-kategorinya
+func categoryChain(ancestors map[uint][]uint, categoryID uint) []uint {
+	if chain, ok := ancestors[categoryID]; ok {
+		return chain
+	}
+	return []uint{categoryID}
+}
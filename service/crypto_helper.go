@@ -0,0 +1,118 @@
+package service
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"os"
+	"strings"
+)
+
+// dataEncryptionKey - Mengambil kunci AES-256 dari DATA_ENCRYPTION_KEY (64 karakter hex, atau passphrase yang di-hash)
+func dataEncryptionKey() ([]byte, error) {
+	raw := os.Getenv("DATA_ENCRYPTION_KEY")
+	if raw == "" {
+		return nil, errors.New("DATA_ENCRYPTION_KEY is not set")
+	}
+	if key, err := hex.DecodeString(raw); err == nil && len(key) == 32 {
+		return key, nil
+	}
+	sum := sha256.Sum256([]byte(raw))
+	return sum[:], nil
+}
+
+// encryptSecret - Mengenkripsi data sensitif dengan AES-GCM, hasilnya base64(nonce || ciphertext)
+func encryptSecret(plaintext string) (string, error) {
+	gcm, err := newGCM()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decryptSecret - Kebalikan dari encryptSecret
+func decryptSecret(encoded string) (string, error) {
+	gcm, err := newGCM()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", errors.New("ciphertext too short")
+	}
+
+	plaintext, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// hashSecret - HMAC-SHA256 (hex) untuk pencarian/deteksi duplikat tanpa menyimpan plaintext.
+// Dikunci dengan turunan DATA_ENCRYPTION_KEY agar kode pendek tidak bisa di-brute force dari dump database.
+func hashSecret(value string) (string, error) {
+	key, err := dataEncryptionKey()
+	if err != nil {
+		return "", err
+	}
+	// Kunci HMAC diturunkan terpisah dari kunci AES
+	derive := hmac.New(sha256.New, key)
+	derive.Write([]byte("secret-hash"))
+
+	mac := hmac.New(sha256.New, derive.Sum(nil))
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+func newGCM() (cipher.AEAD, error) {
+	key, err := dataEncryptionKey()
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// randomCode - Kode acak huruf besar dan angka (tanpa karakter mirip seperti O/0, I/1) dengan prefix
+func randomCode(prefix string, length int) (string, error) {
+	const alphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	var sb strings.Builder
+	sb.WriteString(prefix)
+	for i := 0; i < length; i++ {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(alphabet))))
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(alphabet[n.Int64()])
+	}
+	return sb.String(), nil
+}
+
+// randomHex - String hex acak dari n byte
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
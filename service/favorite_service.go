@@ -0,0 +1,129 @@
+package service
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"main.go/entity"
+	"main.go/middleware"
+	"main.go/repository"
+)
+
+const favoriteSuggestionLimit = 10
+
+// destinationRegex - Nomor HP, ID pelanggan PLN, dan sejenisnya: hanya angka
+var destinationRegex = regexp.MustCompile(`^[0-9]{6,20}$`)
+
+type FavoriteService interface {
+	GetFavorites(userID uint) ([]entity.Favorite, error)
+	CreateFavorite(userID uint, request entity.FavoriteRequest) (*entity.Favorite, error)
+	UpdateFavorite(userID uint, id uint, request entity.FavoriteRequest) (*entity.Favorite, error)
+	DeleteFavorite(userID uint, id uint) error
+	GetSuggestions(userID uint) ([]entity.FavoriteSuggestion, error)
+
+	ResolveDestination(userID uint, favoriteID uint) (string, error)
+	MarkUsed(userID uint, destination string)
+}
+
+type favoriteService struct {
+	repo repository.FavoriteRepository
+}
+
+func NewFavoriteService(repo repository.FavoriteRepository) FavoriteService {
+	return &favoriteService{repo: repo}
+}
+
+func (s *favoriteService) GetFavorites(userID uint) ([]entity.Favorite, error) {
+	return s.repo.GetByUser(userID)
+}
+
+func (s *favoriteService) CreateFavorite(userID uint, request entity.FavoriteRequest) (*entity.Favorite, error) {
+	favorite := entity.Favorite{UserID: userID}
+	if err := applyFavoriteRequest(&favorite, request); err != nil {
+		return nil, err
+	}
+	if existing, _ := s.repo.FindByNumber(userID, favorite.DestinationNumber); existing != nil {
+		return nil, middleware.NewAppError(409, "Destination number is already saved as "+existing.Label, nil)
+	}
+	if err := s.repo.Create(&favorite); err != nil {
+		return nil, middleware.NewAppError(500, "Failed to save favorite", err)
+	}
+	return &favorite, nil
+}
+
+func (s *favoriteService) UpdateFavorite(userID uint, id uint, request entity.FavoriteRequest) (*entity.Favorite, error) {
+	favorite, err := s.repo.GetByID(userID, id)
+	if err != nil {
+		return nil, middleware.NewAppError(404, err.Error(), err)
+	}
+	if err := applyFavoriteRequest(favorite, request); err != nil {
+		return nil, err
+	}
+	if existing, _ := s.repo.FindByNumber(userID, favorite.DestinationNumber); existing != nil && existing.ID != favorite.ID {
+		return nil, middleware.NewAppError(409, "Destination number is already saved as "+existing.Label, nil)
+	}
+	if err := s.repo.Update(favorite); err != nil {
+		return nil, middleware.NewAppError(500, "Failed to update favorite", err)
+	}
+	return favorite, nil
+}
+
+func (s *favoriteService) DeleteFavorite(userID uint, id uint) error {
+	if err := s.repo.Delete(userID, id); err != nil {
+		return middleware.NewAppError(404, err.Error(), err)
+	}
+	return nil
+}
+
+// GetSuggestions - Nomor yang sering dipakai di riwayat transaksi tetapi belum disimpan
+func (s *favoriteService) GetSuggestions(userID uint) ([]entity.FavoriteSuggestion, error) {
+	suggestions, err := s.repo.Suggest(userID, favoriteSuggestionLimit)
+	if err != nil {
+		return nil, middleware.NewAppError(500, "Failed to fetch suggestions", err)
+	}
+	for i := range suggestions {
+		suggestions[i].Operator = DetectOperator(suggestions[i].DestinationNumber)
+	}
+	return suggestions, nil
+}
+
+// ResolveDestination - Nomor tujuan dari favorit milik user
+func (s *favoriteService) ResolveDestination(userID uint, favoriteID uint) (string, error) {
+	favorite, err := s.repo.GetByID(userID, favoriteID)
+	if err != nil {
+		return "", middleware.NewAppError(404, err.Error(), err)
+	}
+	return favorite.DestinationNumber, nil
+}
+
+// MarkUsed - Memperbarui waktu terakhir dipakai jika nomor tujuan tersimpan sebagai favorit
+func (s *favoriteService) MarkUsed(userID uint, destination string) {
+	if destination == "" {
+		return
+	}
+	favorite, err := s.repo.FindByNumber(userID, destination)
+	if err != nil {
+		return
+	}
+	if err := s.repo.TouchLastUsed(favorite.ID, time.Now()); err != nil {
+		middleware.Logger.Warn("Service: Failed to update favorite last use", zap.Uint("favorite_id", favorite.ID), zap.Error(err))
+	}
+}
+
+func applyFavoriteRequest(favorite *entity.Favorite, request entity.FavoriteRequest) error {
+	label := strings.TrimSpace(request.Label)
+	number := normalizeDestination(request.DestinationNumber)
+	if label == "" {
+		return middleware.NewAppError(400, "Label is required", nil)
+	}
+	if !destinationRegex.MatchString(number) {
+		return middleware.NewAppError(400, "Destination number must be 6 to 20 digits", nil)
+	}
+
+	favorite.Label = label
+	favorite.DestinationNumber = number
+	favorite.Operator = DetectOperator(number)
+	return nil
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"main.go/entity"
+	"main.go/middleware"
+	"main.go/repository"
+)
+
+// H2HService - Endpoint host-to-host untuk server reseller dengan kontrak response tetap
+type H2HService interface {
+	Purchase(userID uint, request entity.H2HPurchaseRequest) (*entity.H2HResponse, error)
+	Status(userID uint, refID string) (*entity.H2HResponse, error)
+	Balance(userID uint) (*entity.H2HBalanceResponse, error)
+}
+
+type h2hService struct {
+	transactionService TransactionsService
+	userRepo           repository.UserRepository
+}
+
+func NewH2HService(transactionService TransactionsService, userRepo repository.UserRepository) H2HService {
+	return &h2hService{
+		transactionService: transactionService,
+		userRepo:           userRepo,
+	}
+}
+
+// Purchase - Membuat transaksi dengan ref_id reseller. Ref_id yang sama tidak membuat transaksi
+// baru, melainkan mengembalikan transaksi yang sudah ada sehingga retry aman.
+func (s *h2hService) Purchase(userID uint, request entity.H2HPurchaseRequest) (*entity.H2HResponse, error) {
+	request.RefID = strings.TrimSpace(request.RefID)
+	if request.RefID == "" {
+		return nil, middleware.NewAppError(400, "ref_id is required", nil)
+	}
+	if request.ProductID == 0 && strings.TrimSpace(request.ProductCode) == "" {
+		return nil, middleware.NewAppError(400, "product_id or product_code is required", nil)
+	}
+	if request.Quantity <= 0 {
+		request.Quantity = 1
+	}
+
+	if response := s.existingPurchase(userID, request.RefID); response != nil {
+		return response, nil
+	}
+
+	transaction, err := s.transactionService.CreateTransaction(&entity.TransactionRequest{
+		UserID:            userID,
+		DestinationNumber: request.Destination,
+		CustomerInputs:    request.CustomerInputs,
+		ReferenceID:       request.RefID,
+		Pin:               request.Pin,
+		Items: []entity.TransactionItemRequest{
+			{ProductID: request.ProductID, ProductCode: request.ProductCode, Quantity: request.Quantity},
+		},
+	})
+	if err != nil {
+		// Retry yang berjalan bersamaan kalah di index unik: kembalikan transaksi pemenangnya
+		var appErr *middleware.AppError
+		if errors.As(err, &appErr) && errors.Is(appErr.Err, repository.ErrDuplicateReference) {
+			if response := s.existingPurchase(userID, request.RefID); response != nil {
+				return response, nil
+			}
+		}
+		return nil, err
+	}
+
+	response := toH2HResponse(transaction, request.RefID)
+	response.Message = "Transaction is being processed"
+	return response, nil
+}
+
+// existingPurchase - Response untuk transaksi yang sudah memakai ref_id, nil jika belum ada
+func (s *h2hService) existingPurchase(userID uint, refID string) *entity.H2HResponse {
+	existing, err := s.transactionService.GetTransactionByReference(userID, refID)
+	if err != nil {
+		return nil
+	}
+	response := toH2HResponse(existing, refID)
+	response.Message = "Transaction with this ref_id already exists"
+	return response
+}
+
+func (s *h2hService) Status(userID uint, refID string) (*entity.H2HResponse, error) {
+	transaction, err := s.transactionService.GetTransactionByReference(userID, strings.TrimSpace(refID))
+	if err != nil {
+		return nil, err
+	}
+
+	response := toH2HResponse(transaction, refID)
+	response.Message = "Transaction " + transaction.Status
+	return response, nil
+}
+
+func (s *h2hService) Balance(userID uint) (*entity.H2HBalanceResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, middleware.NewAppError(404, "User not found", err)
+	}
+	return &entity.H2HBalanceResponse{
+		Success: true,
+		Balance: user.Balance,
+		Message: "Balance fetched successfully",
+	}, nil
+}
+
+func toH2HResponse(transaction *entity.Transaction, refID string) *entity.H2HResponse {
+	response := &entity.H2HResponse{
+		Success:       true,
+		RefID:         refID,
+		TransactionID: transaction.ID,
+		Status:        transaction.Status,
+		Destination:   transaction.DestinationNumber,
+		Price:         transaction.TotalPrice,
+		SerialNumber:  transaction.SerialNumber,
+		CreatedAt:     &transaction.CreatedAt,
+	}
+	if len(transaction.Items) > 0 {
+		response.ProductID = transaction.Items[0].ProductID
+		if code := transaction.Items[0].Product.Code; code != nil {
+			response.ProductCode = *code
+		}
+	}
+	return response
+}
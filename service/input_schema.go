@@ -0,0 +1,125 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"main.go/entity"
+	"main.go/middleware"
+)
+
+var inputFieldNameRegex = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// ValidateInputSchema - Memastikan definisi InputSchema sebuah produk valid sebelum disimpan
+func ValidateInputSchema(schema []entity.InputField) error {
+	seen := make(map[string]bool)
+	for i, field := range schema {
+		if !inputFieldNameRegex.MatchString(field.Name) {
+			return middleware.NewAppError(400, fmt.Sprintf("input_schema[%d]: name must be lowercase letters, digits or underscore", i), nil)
+		}
+		if seen[field.Name] {
+			return middleware.NewAppError(400, fmt.Sprintf("input_schema[%d]: duplicate field name %q", i, field.Name), nil)
+		}
+		seen[field.Name] = true
+
+		switch field.Type {
+		case entity.InputFieldText, entity.InputFieldNumber, entity.InputFieldPhone, entity.InputFieldEmail:
+		default:
+			return middleware.NewAppError(400, fmt.Sprintf("input_schema[%d]: unsupported type %q", i, field.Type), nil)
+		}
+
+		if field.Pattern != "" {
+			if _, err := regexp.Compile(field.Pattern); err != nil {
+				return middleware.NewAppError(400, fmt.Sprintf("input_schema[%d]: invalid pattern", i), err)
+			}
+		}
+
+		if field.MinLength < 0 || field.MaxLength < 0 || (field.MaxLength > 0 && field.MinLength > field.MaxLength) {
+			return middleware.NewAppError(400, fmt.Sprintf("input_schema[%d]: invalid length limits", i), nil)
+		}
+	}
+	return nil
+}
+
+// validateCustomerInputs - Memvalidasi nilai input pelanggan terhadap InputSchema satu produk
+func validateCustomerInputs(schema []entity.InputField, inputs map[string]string) error {
+	for _, field := range schema {
+		value := strings.TrimSpace(inputs[field.Name])
+		if value == "" {
+			if field.Required {
+				return middleware.NewAppError(400, fmt.Sprintf("%s is required", fieldLabel(field)), nil)
+			}
+			continue
+		}
+
+		if field.MinLength > 0 && len(value) < field.MinLength {
+			return middleware.NewAppError(400, fmt.Sprintf("%s must be at least %d characters", fieldLabel(field), field.MinLength), nil)
+		}
+		if field.MaxLength > 0 && len(value) > field.MaxLength {
+			return middleware.NewAppError(400, fmt.Sprintf("%s must be at most %d characters", fieldLabel(field), field.MaxLength), nil)
+		}
+
+		switch field.Type {
+		case entity.InputFieldNumber:
+			if _, err := strconv.ParseUint(value, 10, 64); err != nil {
+				return middleware.NewAppError(400, fmt.Sprintf("%s must contain digits only", fieldLabel(field)), nil)
+			}
+		case entity.InputFieldPhone:
+			if !isValidPhoneNumber(value) {
+				return middleware.NewAppError(400, fmt.Sprintf("%s is not a valid phone number", fieldLabel(field)), nil)
+			}
+		case entity.InputFieldEmail:
+			if !isValidEmail(value) {
+				return middleware.NewAppError(400, fmt.Sprintf("%s is not a valid email", fieldLabel(field)), nil)
+			}
+		}
+
+		if field.Pattern != "" {
+			re, err := regexp.Compile(field.Pattern)
+			if err != nil || !re.MatchString(value) {
+				return middleware.NewAppError(400, fmt.Sprintf("%s has an invalid format", fieldLabel(field)), err)
+			}
+		}
+	}
+	return nil
+}
+
+// collectCustomerInputs - Menyaring input pelanggan, hanya menyimpan field yang dideklarasikan produk
+func collectCustomerInputs(schemas [][]entity.InputField, inputs map[string]string) (map[string]string, error) {
+	declared := make(map[string]bool)
+	for _, schema := range schemas {
+		for _, field := range schema {
+			declared[field.Name] = true
+		}
+	}
+
+	collected := make(map[string]string)
+	for name, value := range inputs {
+		if !declared[name] {
+			return nil, middleware.NewAppError(400, fmt.Sprintf("unknown input field %q", name), nil)
+		}
+		if value = strings.TrimSpace(value); value != "" {
+			collected[name] = value
+		}
+	}
+
+	if len(collected) == 0 {
+		return nil, nil
+	}
+	return collected, nil
+}
+
+func fieldLabel(field entity.InputField) string {
+	if field.Label != "" {
+		return field.Label
+	}
+	return field.Name
+}
+
+func isValidPhoneNumber(phone string) bool {
+	const phoneRegex = `^(\+62|62|0)[0-9]{8,13}$`
+	re := regexp.MustCompile(phoneRegex)
+	return re.MatchString(phone)
+}
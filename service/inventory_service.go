@@ -0,0 +1,321 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"main.go/entity"
+	"main.go/middleware"
+	"main.go/repository"
+)
+
+// inventoryHistoryLimit - Jumlah mutasi stok terbaru yang ditampilkan per produk
+const inventoryHistoryLimit = 100
+
+// StockAlertHook - Titik ekstensi untuk notifikasi stok menipis (mis. webhook, email, Telegram)
+type StockAlertHook interface {
+	OnLowStock(alert entity.LowStockAlert)
+}
+
+type InventoryService interface {
+	AdjustStock(productID uint, adminID uint, request entity.StockAdjustmentRequest) (*entity.InventoryMovement, error)
+	GetMovements(productID uint) ([]entity.InventoryMovement, error)
+	GetLowStockProducts() ([]entity.Product, error)
+
+	RecordPurchase(transaction *entity.Transaction, quantities map[uint]int) error
+	SetStock(productID uint, stock int, movement entity.InventoryMovement) error
+	CheckLowStock(product *entity.Product, movement *entity.InventoryMovement)
+	RegisterAlertHook(hook StockAlertHook)
+
+	TransactionHook
+}
+
+type inventoryService struct {
+	repo               repository.InventoryRepository
+	productRepo        repository.ProductRepository
+	activityLogService ActivityLogService
+	alertHooks         []StockAlertHook
+}
+
+func NewInventoryService(repo repository.InventoryRepository, productRepo repository.ProductRepository, activityLogService ActivityLogService) InventoryService {
+	return &inventoryService{
+		repo:               repo,
+		productRepo:        productRepo,
+		activityLogService: activityLogService,
+	}
+}
+
+// RegisterAlertHook - Mendaftarkan penerima notifikasi stok menipis
+func (s *inventoryService) RegisterAlertHook(hook StockAlertHook) {
+	s.alertHooks = append(s.alertHooks, hook)
+}
+
+// AdjustStock - Penyesuaian stok manual oleh admin, alasan wajib diisi agar tercatat di ledger
+func (s *inventoryService) AdjustStock(productID uint, adminID uint, request entity.StockAdjustmentRequest) (*entity.InventoryMovement, error) {
+	reason := strings.TrimSpace(request.Reason)
+	if reason == "" {
+		return nil, middleware.NewAppError(400, "reason is required", nil)
+	}
+	if len(reason) > 255 {
+		return nil, middleware.NewAppError(400, "reason must be at most 255 characters", nil)
+	}
+	if request.Quantity == 0 {
+		return nil, middleware.NewAppError(400, "quantity must not be zero", nil)
+	}
+
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, middleware.NewAppError(404, "Product not found", err)
+	}
+	if product.IsVoucher {
+		return nil, middleware.NewAppError(400, "stock of voucher products follows the imported codes", nil)
+	}
+
+	movements := []entity.InventoryMovement{{
+		ProductID: productID,
+		Type:      entity.InventoryAdjustment,
+		Quantity:  request.Quantity,
+		Reason:    reason,
+		CreatedBy: &adminID,
+	}}
+	products, err := s.repo.ApplyMovements(movements)
+	if err != nil {
+		if errors.Is(err, repository.ErrInsufficientStock) {
+			return nil, middleware.NewAppError(409, fmt.Sprintf("Adjustment would make stock negative (current stock %d)", product.Stock), err)
+		}
+		middleware.Logger.Error("Service: Failed to adjust stock", zap.Uint("product_id", productID), zap.Error(err))
+		return nil, err
+	}
+	movement := &movements[0]
+
+	details := fmt.Sprintf("Product ID: %d, Quantity: %+d, Stock: %d -> %d, Reason: %s", productID, movement.Quantity, movement.StockBefore, movement.StockAfter, reason)
+	if err := s.activityLogService.CreateActivityLog(adminID, "Stock Adjusted", details); err != nil {
+		middleware.Logger.Error("Failed to create activity log", zap.Error(err))
+	}
+
+	s.CheckLowStock(&products[0], movement)
+	return movement, nil
+}
+
+func (s *inventoryService) GetMovements(productID uint) ([]entity.InventoryMovement, error) {
+	if _, err := s.productRepo.GetByID(productID); err != nil {
+		return nil, middleware.NewAppError(404, "Product not found", err)
+	}
+	return s.repo.GetMovements(productID, inventoryHistoryLimit)
+}
+
+func (s *inventoryService) GetLowStockProducts() ([]entity.Product, error) {
+	return s.repo.GetLowStockProducts()
+}
+
+// RecordPurchase - Mengurangi stok produk non-voucher yang dibeli (quantities: product ID -> jumlah).
+// Mengembalikan repository.ErrInsufficientStock jika stok salah satu produk tidak cukup.
+func (s *inventoryService) RecordPurchase(transaction *entity.Transaction, quantities map[uint]int) error {
+	if len(quantities) == 0 {
+		return nil
+	}
+
+	deltas := make(map[uint]int, len(quantities))
+	for productID, quantity := range quantities {
+		if quantity <= 0 {
+			return middleware.NewAppError(400, "quantity must be greater than 0", nil)
+		}
+		deltas[productID] = -quantity
+	}
+	movements := transactionMovements(transaction.ID, entity.InventoryPurchase, fmt.Sprintf("Transaction #%d", transaction.ID), deltas)
+
+	products, err := s.repo.ApplyMovements(movements)
+	if err != nil {
+		return err
+	}
+	for i := range movements {
+		s.CheckLowStock(&products[i], &movements[i])
+	}
+	return nil
+}
+
+// SetStock - Menetapkan stok ke angka tertentu, dipakai untuk produk voucher yang stoknya mengikuti sisa kode
+func (s *inventoryService) SetStock(productID uint, stock int, movement entity.InventoryMovement) error {
+	product, err := s.repo.SetStock(productID, stock, &movement)
+	if err != nil {
+		return err
+	}
+	if movement.ID != 0 {
+		s.CheckLowStock(product, &movement)
+	}
+	return nil
+}
+
+// CheckLowStock - Mengirim alert jika mutasi membuat stok turun melewati batas minimum produk.
+// Alert hanya dikirim sekali saat batas dilewati, bukan setiap mutasi selama stok masih di bawah batas.
+func (s *inventoryService) CheckLowStock(product *entity.Product, movement *entity.InventoryMovement) {
+	threshold := product.LowStockThreshold
+	if threshold <= 0 || movement.StockBefore <= threshold || movement.StockAfter > threshold {
+		return
+	}
+
+	alert := entity.LowStockAlert{
+		ProductID: product.ID,
+		Name:      product.Name,
+		Code:      product.Code,
+		Stock:     movement.StockAfter,
+		Threshold: threshold,
+		Movement:  *movement,
+		AlertedAt: time.Now(),
+	}
+	middleware.Logger.Warn("Service: Product stock is low",
+		zap.Uint("product_id", product.ID),
+		zap.Int("stock", alert.Stock),
+		zap.Int("threshold", threshold),
+	)
+
+	var actorID uint
+	if movement.CreatedBy != nil {
+		actorID = *movement.CreatedBy
+	}
+	details := fmt.Sprintf("Product ID: %d, Name: %s, Stock: %d, Threshold: %d, Movement: %s", product.ID, product.Name, alert.Stock, threshold, movement.Type)
+	if err := s.activityLogService.CreateActivityLog(actorID, "Low Stock Alert", details); err != nil {
+		middleware.Logger.Error("Failed to create activity log", zap.Error(err))
+	}
+
+	for _, hook := range s.alertHooks {
+		hook.OnLowStock(alert)
+	}
+}
+
+// OnTransactionSuccess - Stok biasanya sudah dikurangi saat transaksi dibuat. Jika transaksi sempat gagal
+// (stok dikembalikan) lalu diubah menjadi sukses, pembeliannya dicatat ulang agar stok tidak bertambah.
+func (s *inventoryService) OnTransactionSuccess(transaction *entity.Transaction) {
+	net, purchased, err := s.netPurchases(transaction.ID)
+	if err != nil {
+		middleware.Logger.Error("Service: Failed to load inventory movements", zap.Uint("transaction_id", transaction.ID), zap.Error(err))
+		return
+	}
+
+	// Hanya produk yang pembeliannya tercatat di ledger; transaksi lama tanpa mutasi tidak disentuh
+	required := make(map[uint]int)
+	for _, item := range transaction.Items {
+		if purchased[item.ProductID] {
+			required[item.ProductID] += item.Quantity
+		}
+	}
+	deltas := make(map[uint]int)
+	for productID, quantity := range required {
+		delta := -quantity - net[productID]
+		if delta >= 0 {
+			continue
+		}
+		// Stok produk voucher mengikuti sisa kode, bukan ledger pembelian
+		product, err := s.productRepo.GetByID(productID)
+		if err != nil || product.IsVoucher {
+			continue
+		}
+		deltas[productID] = delta
+	}
+	if len(deltas) == 0 {
+		return
+	}
+
+	movements := transactionMovements(transaction.ID, entity.InventoryPurchase, fmt.Sprintf("Transaction #%d %s", transaction.ID, transaction.Status), deltas)
+	products, err := s.repo.ApplyMovements(movements)
+	if err != nil {
+		middleware.Logger.Error("Service: Failed to re-apply purchase stock", zap.Uint("transaction_id", transaction.ID), zap.Error(err))
+		details := fmt.Sprintf("Transaction ID: %d, Error: %s", transaction.ID, err.Error())
+		if err := s.activityLogService.CreateActivityLog(transaction.UserID, "Stock Reapply Failed", details); err != nil {
+			middleware.Logger.Error("Failed to create activity log", zap.Error(err))
+		}
+		return
+	}
+	for i := range movements {
+		s.CheckLowStock(&products[i], &movements[i])
+	}
+
+	details := fmt.Sprintf("Transaction ID: %d, Products: %d, Status: %s", transaction.ID, len(movements), transaction.Status)
+	if err := s.activityLogService.CreateActivityLog(transaction.UserID, "Stock Reapplied", details); err != nil {
+		middleware.Logger.Error("Failed to create activity log", zap.Error(err))
+	}
+}
+
+// OnTransactionReversed - Mengembalikan stok produk non-voucher dari transaksi yang gagal atau di-refund.
+// Kode voucher yang sudah diberikan tidak kembali ke inventori sehingga stok voucher tidak diubah.
+func (s *inventoryService) OnTransactionReversed(transaction *entity.Transaction, previousStatus string) {
+	// Selisih bersih purchase dan refund per produk, sehingga stok tidak dikembalikan dua kali
+	net, _, err := s.netPurchases(transaction.ID)
+	if err != nil {
+		middleware.Logger.Error("Service: Failed to load inventory movements", zap.Uint("transaction_id", transaction.ID), zap.Error(err))
+		return
+	}
+
+	restore := make(map[uint]int)
+	for productID, quantity := range net {
+		if quantity >= 0 {
+			continue
+		}
+		product, err := s.productRepo.GetByID(productID)
+		if err != nil || product.IsVoucher {
+			continue
+		}
+		restore[productID] = -quantity
+	}
+	if len(restore) == 0 {
+		return
+	}
+
+	movements := transactionMovements(transaction.ID, entity.InventoryRefund, fmt.Sprintf("Transaction #%d %s", transaction.ID, transaction.Status), restore)
+	if _, err := s.repo.ApplyMovements(movements); err != nil {
+		middleware.Logger.Error("Service: Failed to restore stock", zap.Uint("transaction_id", transaction.ID), zap.Error(err))
+		return
+	}
+
+	details := fmt.Sprintf("Transaction ID: %d, Products: %d, Status: %s", transaction.ID, len(movements), transaction.Status)
+	if err := s.activityLogService.CreateActivityLog(transaction.UserID, "Stock Restored", details); err != nil {
+		middleware.Logger.Error("Failed to create activity log", zap.Error(err))
+	}
+}
+
+// netPurchases - Jumlah bersih mutasi purchase dan refund sebuah transaksi per produk (negatif = stok masih
+// terpotong), beserta produk yang pernah tercatat mutasi purchase-nya
+func (s *inventoryService) netPurchases(transactionID uint) (map[uint]int, map[uint]bool, error) {
+	recorded, err := s.repo.GetTransactionMovements(transactionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	net := make(map[uint]int)
+	purchased := make(map[uint]bool)
+	for _, movement := range recorded {
+		switch movement.Type {
+		case entity.InventoryPurchase:
+			purchased[movement.ProductID] = true
+			net[movement.ProductID] += movement.Quantity
+		case entity.InventoryRefund:
+			net[movement.ProductID] += movement.Quantity
+		}
+	}
+	return net, purchased, nil
+}
+
+// transactionMovements - Mutasi stok sebuah transaksi (deltas: product ID -> selisih stok), diurutkan
+// berdasarkan ID produk agar transaksi yang berjalan bersamaan mengunci produk dengan urutan yang sama
+func transactionMovements(transactionID uint, movementType string, reason string, deltas map[uint]int) []entity.InventoryMovement {
+	productIDs := make([]uint, 0, len(deltas))
+	for productID := range deltas {
+		productIDs = append(productIDs, productID)
+	}
+	sort.Slice(productIDs, func(i, j int) bool { return productIDs[i] < productIDs[j] })
+
+	movements := make([]entity.InventoryMovement, 0, len(productIDs))
+	for _, productID := range productIDs {
+		movements = append(movements, entity.InventoryMovement{
+			ProductID:     productID,
+			Type:          movementType,
+			Quantity:      deltas[productID],
+			Reason:        reason,
+			TransactionID: &transactionID,
+		})
+	}
+	return movements
+}
@@ -0,0 +1,369 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"main.go/entity"
+	"main.go/middleware"
+	"main.go/repository"
+)
+
+const (
+	// defaultPointValue - Nilai rupiah satu poin jika LOYALTY_POINT_VALUE tidak diatur
+	defaultPointValue = 1.0
+	// loyaltyPromoValidity - Masa berlaku kode diskon hasil tukar poin
+	loyaltyPromoValidity = 30 * 24 * time.Hour
+	loyaltyHistoryLimit  = 50
+)
+
+type LoyaltyService interface {
+	CreateRule(rule *entity.LoyaltyRule) error
+	GetAllRules() ([]entity.LoyaltyRule, error)
+	UpdateRule(rule *entity.LoyaltyRule) error
+	DeleteRule(id uint) error
+
+	GetSummary(userID uint) (*entity.LoyaltySummary, error)
+	Redeem(userID uint, request entity.LoyaltyRedeemRequest) (*entity.LoyaltyRedeemResult, error)
+
+	TransactionHook
+}
+
+type loyaltyService struct {
+	repo               repository.LoyaltyRepository
+	userRepo           repository.UserRepository
+	productRepo        repository.ProductRepository
+	promotionService   PromotionService
+	activityLogService ActivityLogService
+}
+
+func NewLoyaltyService(repo repository.LoyaltyRepository, userRepo repository.UserRepository, productRepo repository.ProductRepository, promotionService PromotionService, activityLogService ActivityLogService) LoyaltyService {
+	return &loyaltyService{
+		repo:               repo,
+		userRepo:           userRepo,
+		productRepo:        productRepo,
+		promotionService:   promotionService,
+		activityLogService: activityLogService,
+	}
+}
+
+func (s *loyaltyService) CreateRule(rule *entity.LoyaltyRule) error {
+	if err := validateLoyaltyRule(rule); err != nil {
+		return err
+	}
+	return s.repo.CreateRule(rule)
+}
+
+func (s *loyaltyService) GetAllRules() ([]entity.LoyaltyRule, error) {
+	return s.repo.GetAllRules()
+}
+
+func (s *loyaltyService) UpdateRule(rule *entity.LoyaltyRule) error {
+	if _, err := s.repo.GetRuleByID(rule.ID); err != nil {
+		return middleware.NewAppError(404, err.Error(), err)
+	}
+	if err := validateLoyaltyRule(rule); err != nil {
+		return err
+	}
+	return s.repo.UpdateRule(rule)
+}
+
+func (s *loyaltyService) DeleteRule(id uint) error {
+	if _, err := s.repo.GetRuleByID(id); err != nil {
+		return middleware.NewAppError(404, err.Error(), err)
+	}
+	return s.repo.DeleteRule(id)
+}
+
+// GetSummary - Saldo poin, saldo rupiah dan riwayat mutasi poin user
+func (s *loyaltyService) GetSummary(userID uint) (*entity.LoyaltySummary, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, middleware.NewAppError(404, "User not found", err)
+	}
+
+	s.expirePoints(userID)
+
+	points, err := s.repo.AvailablePoints(userID)
+	if err != nil {
+		return nil, middleware.NewAppError(500, "Failed to fetch loyalty points", err)
+	}
+	expiring, err := s.repo.ExpiringPoints(userID, time.Now().AddDate(0, 0, 30))
+	if err != nil {
+		return nil, middleware.NewAppError(500, "Failed to fetch loyalty points", err)
+	}
+	history, err := s.repo.GetLedger(userID, loyaltyHistoryLimit)
+	if err != nil {
+		return nil, middleware.NewAppError(500, "Failed to fetch loyalty history", err)
+	}
+
+	return &entity.LoyaltySummary{
+		Points:       points,
+		PointValue:   pointValue(),
+		Balance:      user.Balance,
+		ExpiringSoon: expiring,
+		History:      history,
+	}, nil
+}
+
+// Redeem - Menukar poin menjadi saldo (cashback) atau kode diskon pribadi sekali pakai
+func (s *loyaltyService) Redeem(userID uint, request entity.LoyaltyRedeemRequest) (*entity.LoyaltyRedeemResult, error) {
+	if request.Points <= 0 {
+		return nil, middleware.NewAppError(400, "Points must be greater than 0", nil)
+	}
+	if request.Type != entity.RedeemAsBalance && request.Type != entity.RedeemAsDiscount {
+		return nil, middleware.NewAppError(400, "Redeem type must be balance or discount", nil)
+	}
+
+	amount := math.Floor(float64(request.Points) * pointValue())
+	if amount < 1 {
+		return nil, middleware.NewAppError(400, "Points are worth less than Rp1", nil)
+	}
+
+	s.expirePoints(userID)
+
+	entry, consumed, err := s.repo.Redeem(userID, request.Points, fmt.Sprintf("Redeemed as %s (Rp%.0f)", request.Type, amount))
+	if err != nil {
+		if errors.Is(err, repository.ErrInsufficientPoints) {
+			return nil, middleware.NewAppError(400, err.Error(), err)
+		}
+		return nil, middleware.NewAppError(500, "Failed to redeem points", err)
+	}
+
+	result := &entity.LoyaltyRedeemResult{
+		Points: request.Points,
+		Type:   request.Type,
+		Amount: amount,
+	}
+
+	switch request.Type {
+	case entity.RedeemAsBalance:
+		if err := s.userRepo.AddBalance(userID, amount); err != nil {
+			s.refundRedeem(userID, entry, consumed)
+			return nil, middleware.NewAppError(500, "Failed to credit balance", err)
+		}
+	case entity.RedeemAsDiscount:
+		code, err := s.createPersonalPromo(userID, amount)
+		if err != nil {
+			s.refundRedeem(userID, entry, consumed)
+			return nil, middleware.NewAppError(500, "Failed to create discount code", err)
+		}
+		result.PromoCode = code
+	}
+
+	if user, err := s.userRepo.FindByID(userID); err == nil {
+		result.Balance = user.Balance
+	}
+	if remaining, err := s.repo.AvailablePoints(userID); err == nil {
+		result.Remaining = remaining
+	}
+
+	details := fmt.Sprintf("Points: %d, Type: %s, Amount: %.2f", request.Points, request.Type, amount)
+	if err := s.activityLogService.CreateActivityLog(userID, "Loyalty Points Redeemed", details); err != nil {
+		middleware.Logger.Error("Failed to create activity log", zap.Error(err))
+	}
+
+	middleware.Logger.Info("Service: Loyalty points redeemed", zap.Uint("user_id", userID), zap.Int("points", request.Points), zap.String("type", request.Type))
+	return result, nil
+}
+
+// OnTransactionSuccess - Memberikan poin sesuai aturan aktif untuk setiap item transaksi
+func (s *loyaltyService) OnTransactionSuccess(transaction *entity.Transaction) {
+	exists, err := s.repo.HasEntry(transaction.ID, entity.LoyaltyEarn)
+	if err != nil || exists {
+		return
+	}
+
+	rules, err := s.repo.GetActiveRules()
+	if err != nil {
+		middleware.Logger.Error("Service: Failed to fetch loyalty rules", zap.Error(err))
+		return
+	}
+	if len(rules) == 0 {
+		return
+	}
+
+	// Poin dihitung dari nilai yang benar-benar dibayar, diskon promo dibagi rata ke semua item
+	gross := transaction.TotalPrice + transaction.DiscountAmount
+	paidRatio := 1.0
+	if gross > 0 {
+		paidRatio = transaction.TotalPrice / gross
+	}
+
+	ancestors, err := s.productRepo.GetCategoryAncestorIDs()
+	if err != nil {
+		middleware.Logger.Error("Service: Failed to fetch category tree", zap.Error(err))
+		return
+	}
+
+	points := 0
+	var expiresAt *time.Time
+	for _, item := range transaction.Items {
+		product, err := s.productRepo.GetByID(item.ProductID)
+		if err != nil {
+			continue
+		}
+		rule := matchLoyaltyRule(rules, categoryChain(ancestors, product.CategoryID))
+		if rule == nil {
+			continue
+		}
+
+		subtotal := item.Price * float64(item.Quantity) * paidRatio
+		if subtotal < rule.MinTransaction {
+			continue
+		}
+		earned := int(math.Floor(subtotal/rule.SpendUnit)) * rule.PointsPerUnit
+		if earned <= 0 {
+			continue
+		}
+		points += earned
+
+		// Masa berlaku terpendek dari aturan yang dipakai menentukan kedaluwarsa entri
+		if rule.ExpiryDays > 0 {
+			expiry := time.Now().AddDate(0, 0, rule.ExpiryDays)
+			if expiresAt == nil || expiry.Before(*expiresAt) {
+				expiresAt = &expiry
+			}
+		}
+	}
+	if points == 0 {
+		return
+	}
+
+	transactionID := transaction.ID
+	entry := entity.LoyaltyLedger{
+		UserID:        transaction.UserID,
+		TransactionID: &transactionID,
+		Type:          entity.LoyaltyEarn,
+		Points:        points,
+		Remaining:     points,
+		ExpiresAt:     expiresAt,
+		Description:   fmt.Sprintf("Earned from transaction #%d", transaction.ID),
+	}
+	if err := s.repo.CreateEntry(&entry); err != nil {
+		middleware.Logger.Error("Service: Failed to record loyalty points", zap.Uint("transaction_id", transaction.ID), zap.Error(err))
+		return
+	}
+
+	middleware.Logger.Info("Service: Loyalty points earned", zap.Uint("transaction_id", transaction.ID), zap.Int("points", points))
+}
+
+// OnTransactionReversed - Menarik kembali poin dari transaksi yang gagal atau di-refund
+func (s *loyaltyService) OnTransactionReversed(transaction *entity.Transaction, previousStatus string) {
+	entry, err := s.repo.ReverseEarn(transaction.ID, fmt.Sprintf("Reversed: transaction #%d %s", transaction.ID, transaction.Status))
+	if err != nil {
+		middleware.Logger.Error("Service: Failed to reverse loyalty points", zap.Uint("transaction_id", transaction.ID), zap.Error(err))
+		return
+	}
+	if entry == nil {
+		return
+	}
+
+	details := fmt.Sprintf("Transaction ID: %d, Points: %d, Status: %s", transaction.ID, -entry.Points, transaction.Status)
+	if err := s.activityLogService.CreateActivityLog(transaction.UserID, "Loyalty Points Reversed", details); err != nil {
+		middleware.Logger.Error("Failed to create activity log", zap.Error(err))
+	}
+}
+
+func (s *loyaltyService) expirePoints(userID uint) {
+	expired, err := s.repo.ExpireDue(userID, time.Now())
+	if err != nil {
+		middleware.Logger.Error("Service: Failed to expire loyalty points", zap.Uint("user_id", userID), zap.Error(err))
+		return
+	}
+	if expired > 0 {
+		middleware.Logger.Info("Service: Loyalty points expired", zap.Uint("user_id", userID), zap.Int("points", expired))
+	}
+}
+
+// refundRedeem - Mengembalikan poin ke entri asalnya (masa berlaku tetap) jika penukaran gagal diselesaikan
+func (s *loyaltyService) refundRedeem(userID uint, redeemed *entity.LoyaltyLedger, consumed map[uint]int) {
+	if err := s.repo.CancelRedeem(redeemed, consumed, "Refund of failed redemption"); err != nil {
+		middleware.Logger.Error("Service: Failed to refund redeemed points", zap.Uint("user_id", userID), zap.Error(err))
+	}
+}
+
+func (s *loyaltyService) createPersonalPromo(userID uint, amount float64) (string, error) {
+	for attempt := 0; attempt < 3; attempt++ {
+		code, err := randomCode("LOY", 8)
+		if err != nil {
+			return "", err
+		}
+
+		now := time.Now()
+		owner := userID
+		promotion := entity.Promotion{
+			Code:          code,
+			Name:          "Loyalty reward",
+			Description:   fmt.Sprintf("Discount Rp%.0f from loyalty points", amount),
+			DiscountType:  entity.DiscountTypeFixed,
+			DiscountValue: amount,
+			StartAt:       now,
+			EndAt:         now.Add(loyaltyPromoValidity),
+			GlobalLimit:   1,
+			PerUserLimit:  1,
+			UserID:        &owner,
+			IsActive:      true,
+		}
+		err = s.promotionService.CreatePromotion(&promotion)
+		if err == nil {
+			return code, nil
+		}
+		var appErr *middleware.AppError
+		if !errors.As(err, &appErr) || appErr.Code != 409 {
+			return "", err
+		}
+	}
+	return "", errors.New("failed to generate unique promo code")
+}
+
+// matchLoyaltyRule - Aturan kategori mengalahkan aturan umum. categoryIDs berisi kategori produk lalu
+// induk-induknya, aturan kategori terdekat yang dipakai.
+func matchLoyaltyRule(rules []entity.LoyaltyRule, categoryIDs []uint) *entity.LoyaltyRule {
+	for _, categoryID := range categoryIDs {
+		for i := range rules {
+			if rules[i].CategoryID != nil && *rules[i].CategoryID == categoryID {
+				return &rules[i]
+			}
+		}
+	}
+	for i := range rules {
+		if rules[i].CategoryID == nil {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// pointValue - Nilai rupiah satu poin dari env LOYALTY_POINT_VALUE
+func pointValue() float64 {
+	if raw := os.Getenv("LOYALTY_POINT_VALUE"); raw != "" {
+		if value, err := strconv.ParseFloat(raw, 64); err == nil && value > 0 {
+			return value
+		}
+	}
+	return defaultPointValue
+}
+
+func validateLoyaltyRule(rule *entity.LoyaltyRule) error {
+	rule.Name = strings.TrimSpace(rule.Name)
+	if rule.Name == "" {
+		return middleware.NewAppError(400, "Loyalty rule name is required", nil)
+	}
+	if rule.SpendUnit <= 0 || rule.PointsPerUnit <= 0 {
+		return middleware.NewAppError(400, "spend_unit and points_per_unit must be greater than 0", nil)
+	}
+	if rule.ExpiryDays < 0 || rule.MinTransaction < 0 {
+		return middleware.NewAppError(400, "expiry_days and min_transaction cannot be negative", nil)
+	}
+	if rule.CategoryID != nil && *rule.CategoryID == 0 {
+		rule.CategoryID = nil
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"main.go/entity"
+	"main.go/middleware"
+	"main.go/repository"
+)
+
+// maxMaintenanceChain - Batas penggabungan jadwal yang bersambung saat menghitung waktu tersedia kembali
+const maxMaintenanceChain = 10
+
+type MaintenanceService interface {
+	CreateWindow(window *entity.MaintenanceWindow) error
+	GetAllWindows() ([]entity.MaintenanceWindow, error)
+	GetWindowByID(id uint) (*entity.MaintenanceWindow, error)
+	UpdateWindow(window *entity.MaintenanceWindow) error
+	DeleteWindow(id uint) error
+
+	CheckProduct(product *entity.Product) error
+	AnnotateProducts(products []entity.Product) error
+}
+
+type maintenanceService struct {
+	repo        repository.MaintenanceRepository
+	productRepo repository.ProductRepository
+}
+
+func NewMaintenanceService(repo repository.MaintenanceRepository, productRepo repository.ProductRepository) MaintenanceService {
+	return &maintenanceService{
+		repo:        repo,
+		productRepo: productRepo,
+	}
+}
+
+func (s *maintenanceService) CreateWindow(window *entity.MaintenanceWindow) error {
+	if err := s.validateWindow(window); err != nil {
+		return err
+	}
+	return s.repo.Create(window)
+}
+
+func (s *maintenanceService) GetAllWindows() ([]entity.MaintenanceWindow, error) {
+	return s.repo.GetAll()
+}
+
+func (s *maintenanceService) GetWindowByID(id uint) (*entity.MaintenanceWindow, error) {
+	window, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, middleware.NewAppError(404, err.Error(), err)
+	}
+	return window, nil
+}
+
+func (s *maintenanceService) UpdateWindow(window *entity.MaintenanceWindow) error {
+	if _, err := s.GetWindowByID(window.ID); err != nil {
+		return err
+	}
+	if err := s.validateWindow(window); err != nil {
+		return err
+	}
+	return s.repo.Update(window)
+}
+
+func (s *maintenanceService) DeleteWindow(id uint) error {
+	if _, err := s.GetWindowByID(id); err != nil {
+		return err
+	}
+	return s.repo.Delete(id)
+}
+
+// CheckProduct - Menolak pembelian produk yang sedang dalam jadwal maintenance
+func (s *maintenanceService) CheckProduct(product *entity.Product) error {
+	windows, err := s.repo.GetActive()
+	if err != nil {
+		middleware.Logger.Error("Failed to fetch maintenance windows", zap.Error(err))
+		return err
+	}
+
+	ancestors, err := s.productRepo.GetCategoryAncestorIDs()
+	if err != nil {
+		middleware.Logger.Error("Failed to fetch category tree", zap.Error(err))
+		return err
+	}
+
+	now := time.Now()
+	until, window := unavailableUntil(windows, product, categoryChain(ancestors, product.CategoryID), now)
+	if until == nil {
+		return nil
+	}
+
+	message := fmt.Sprintf("%s is unavailable until %s", product.Name, formatUntil(*until, now))
+	if window.Reason != "" {
+		message += " (" + window.Reason + ")"
+	}
+	return middleware.NewAppError(503, message, nil)
+}
+
+// AnnotateProducts - Mengisi status available dan unavailable_until untuk daftar produk
+func (s *maintenanceService) AnnotateProducts(products []entity.Product) error {
+	windows, err := s.repo.GetActive()
+	if err != nil {
+		middleware.Logger.Error("Failed to fetch maintenance windows", zap.Error(err))
+		return err
+	}
+
+	ancestors, err := s.productRepo.GetCategoryAncestorIDs()
+	if err != nil {
+		middleware.Logger.Error("Failed to fetch category tree", zap.Error(err))
+		return err
+	}
+
+	now := time.Now()
+	for i := range products {
+		until, _ := unavailableUntil(windows, &products[i], categoryChain(ancestors, products[i].CategoryID), now)
+		products[i].UnavailableUntil = until
+		products[i].Available = products[i].IsActive && until == nil
+	}
+	return nil
+}
+
+func (s *maintenanceService) validateWindow(window *entity.MaintenanceWindow) error {
+	window.Name = strings.TrimSpace(window.Name)
+	if window.Name == "" {
+		return middleware.NewAppError(400, "name is required", nil)
+	}
+
+	switch window.Scope {
+	case entity.MaintenanceScopeProduct:
+		if _, err := s.productRepo.GetByID(window.TargetID); err != nil {
+			return middleware.NewAppError(400, "target_id must be an existing product", err)
+		}
+		window.Operator = ""
+	case entity.MaintenanceScopeCategory:
+		if _, err := s.productRepo.GetCategoryByID(window.TargetID); err != nil {
+			return middleware.NewAppError(400, "target_id must be an existing category", err)
+		}
+		window.Operator = ""
+	case entity.MaintenanceScopeOperator:
+		window.Operator = strings.TrimSpace(window.Operator)
+		if window.Operator == "" {
+			return middleware.NewAppError(400, "operator is required for operator scope", nil)
+		}
+		window.TargetID = 0
+	default:
+		return middleware.NewAppError(400, "scope must be product, category or operator", nil)
+	}
+
+	switch window.Type {
+	case entity.MaintenanceDaily:
+		start, okStart := parseClock(window.StartTime)
+		end, okEnd := parseClock(window.EndTime)
+		if !okStart || !okEnd {
+			return middleware.NewAppError(400, "start_time and end_time must use HH:MM format", nil)
+		}
+		if start == end {
+			return middleware.NewAppError(400, "start_time and end_time cannot be equal", nil)
+		}
+		window.StartAt, window.EndAt = nil, nil
+	case entity.MaintenanceOneTime:
+		if window.StartAt == nil || window.EndAt == nil || !window.EndAt.After(*window.StartAt) {
+			return middleware.NewAppError(400, "start_at and end_at are required and end_at must be after start_at", nil)
+		}
+		window.StartTime, window.EndTime = "", ""
+	default:
+		return middleware.NewAppError(400, "type must be daily or one_time", nil)
+	}
+	return nil
+}
+
+// unavailableUntil - Waktu produk tersedia kembali, atau nil jika tidak sedang maintenance.
+// Jadwal yang bersambung (mis. cutoff harian lalu gangguan supplier) digabung.
+// categoryIDs berisi kategori produk beserta induknya, jadwal kategori induk ikut berlaku.
+func unavailableUntil(windows []entity.MaintenanceWindow, product *entity.Product, categoryIDs []uint, now time.Time) (*time.Time, *entity.MaintenanceWindow) {
+	var until time.Time
+	var first *entity.MaintenanceWindow
+	at := now
+	for i := 0; i < maxMaintenanceChain; i++ {
+		extended := false
+		for j := range windows {
+			if !maintenanceApplies(&windows[j], product, categoryIDs) {
+				continue
+			}
+			end, ok := maintenanceEnd(&windows[j], at)
+			if !ok || !end.After(until) {
+				continue
+			}
+			if first == nil {
+				first = &windows[j]
+			}
+			until = end
+			extended = true
+		}
+		if !extended {
+			break
+		}
+		at = until
+	}
+	if first == nil {
+		return nil, nil
+	}
+	return &until, first
+}
+
+func maintenanceApplies(window *entity.MaintenanceWindow, product *entity.Product, categoryIDs []uint) bool {
+	switch window.Scope {
+	case entity.MaintenanceScopeProduct:
+		return window.TargetID == product.ID
+	case entity.MaintenanceScopeCategory:
+		return slices.Contains(categoryIDs, window.TargetID)
+	case entity.MaintenanceScopeOperator:
+		return strings.EqualFold(window.Operator, product.Operator)
+	}
+	return false
+}
+
+// maintenanceEnd - Akhir jadwal jika jadwal sedang berlaku pada waktu at
+func maintenanceEnd(window *entity.MaintenanceWindow, at time.Time) (time.Time, bool) {
+	if window.Type == entity.MaintenanceOneTime {
+		if window.StartAt == nil || window.EndAt == nil {
+			return time.Time{}, false
+		}
+		if at.Before(*window.StartAt) || !at.Before(*window.EndAt) {
+			return time.Time{}, false
+		}
+		return *window.EndAt, true
+	}
+
+	start, okStart := parseClock(window.StartTime)
+	end, okEnd := parseClock(window.EndTime)
+	if !okStart || !okEnd {
+		return time.Time{}, false
+	}
+	midnight := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, at.Location())
+	minute := at.Hour()*60 + at.Minute()
+	switch {
+	case start < end && minute >= start && minute < end:
+		return midnight.Add(time.Duration(end) * time.Minute), true
+	case start > end && minute >= start:
+		return midnight.AddDate(0, 0, 1).Add(time.Duration(end) * time.Minute), true
+	case start > end && minute < end:
+		return midnight.Add(time.Duration(end) * time.Minute), true
+	}
+	return time.Time{}, false
+}
+
+// parseClock - Mengubah "HH:MM" menjadi menit sejak tengah malam
+func parseClock(value string) (int, bool) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+// formatUntil - Jam saja jika masih hari yang sama, lengkap dengan tanggal jika berbeda hari
+func formatUntil(until time.Time, now time.Time) string {
+	if until.Year() == now.Year() && until.YearDay() == now.YearDay() {
+		return until.Format("15:04")
+	}
+	return until.Format("02 Jan 2006 15:04")
+}
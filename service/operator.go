@@ -0,0 +1,39 @@
+package service
+
+import "strings"
+
+// operatorPrefixes - Prefix 4 digit nomor seluler Indonesia per operator
+var operatorPrefixes = map[string]string{
+	"0811": "Telkomsel", "0812": "Telkomsel", "0813": "Telkomsel",
+	"0821": "Telkomsel", "0822": "Telkomsel", "0823": "Telkomsel",
+	"0851": "Telkomsel", "0852": "Telkomsel", "0853": "Telkomsel",
+	"0814": "Indosat", "0815": "Indosat", "0816": "Indosat",
+	"0855": "Indosat", "0856": "Indosat", "0857": "Indosat", "0858": "Indosat",
+	"0817": "XL", "0818": "XL", "0819": "XL",
+	"0859": "XL", "0877": "XL", "0878": "XL",
+	"0831": "Axis", "0832": "Axis", "0833": "Axis", "0838": "Axis",
+	"0895": "Tri", "0896": "Tri", "0897": "Tri", "0898": "Tri", "0899": "Tri",
+	"0881": "Smartfren", "0882": "Smartfren", "0883": "Smartfren", "0884": "Smartfren",
+	"0885": "Smartfren", "0886": "Smartfren", "0887": "Smartfren", "0888": "Smartfren", "0889": "Smartfren",
+}
+
+// normalizeDestination - Menghapus spasi/tanda hubung dan mengubah awalan +62/62 menjadi 0
+func normalizeDestination(number string) string {
+	number = strings.NewReplacer(" ", "", "-", "", ".", "").Replace(strings.TrimSpace(number))
+	switch {
+	case strings.HasPrefix(number, "+62"):
+		return "0" + number[3:]
+	case strings.HasPrefix(number, "62") && len(number) > 10:
+		return "0" + number[2:]
+	}
+	return number
+}
+
+// DetectOperator - Nama operator seluler dari prefix nomor; kosong jika bukan nomor seluler yang dikenal
+func DetectOperator(number string) string {
+	number = normalizeDestination(number)
+	if len(number) < 4 {
+		return ""
+	}
+	return operatorPrefixes[number[:4]]
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"strings"
+
+	"main.go/entity"
+	"main.go/middleware"
+)
+
+// patchFieldState - Nama dan status satu field PATCH untuk validasi keberadaan/null
+type patchFieldState struct {
+	name string
+	set  bool
+	null bool
+}
+
+func fieldState[T any](name string, field entity.PatchField[T]) patchFieldState {
+	return patchFieldState{name: name, set: field.Set, null: field.Null}
+}
+
+// requireFields - PUT harus mengirim objek lengkap, field wajib yang tidak dikirim ditolak
+func requireFields(fields ...patchFieldState) error {
+	var missing []string
+	for _, field := range fields {
+		if !field.set {
+			missing = append(missing, field.name)
+		}
+	}
+	if len(missing) > 0 {
+		return middleware.NewAppError(400, "Missing required fields: "+strings.Join(missing, ", "), nil)
+	}
+	return nil
+}
+
+// rejectNulls - Field wajib tidak boleh dikosongkan dengan null
+func rejectNulls(fields ...patchFieldState) error {
+	var nulls []string
+	for _, field := range fields {
+		if field.null {
+			nulls = append(nulls, field.name)
+		}
+	}
+	if len(nulls) > 0 {
+		return middleware.NewAppError(400, "Fields cannot be null: "+strings.Join(nulls, ", "), nil)
+	}
+	return nil
+}
+
+// clearIfMissing - Pada PUT, field opsional yang tidak dikirim diperlakukan sebagai null (dikosongkan)
+func clearIfMissing[T any](field *entity.PatchField[T]) {
+	if !field.Set {
+		field.Set = true
+		field.Null = true
+	}
+}
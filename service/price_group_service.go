@@ -0,0 +1,228 @@
+package service
+
+import (
+	"math"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+	"main.go/entity"
+	"main.go/middleware"
+	"main.go/repository"
+)
+
+type PriceGroupService interface {
+	CreatePriceGroup(group *entity.PriceGroup) error
+	GetAllPriceGroups() ([]entity.PriceGroup, error)
+	GetPriceGroupByID(id uint) (*entity.PriceGroup, error)
+	UpdatePriceGroup(group *entity.PriceGroup) error
+	DeletePriceGroup(id uint) error
+
+	SetProductPrice(price *entity.PriceGroupPrice) error
+	DeleteProductPrice(groupID uint, productID uint) error
+	SetCategoryRule(rule *entity.PriceGroupRule) error
+	DeleteCategoryRule(groupID uint, categoryID uint) error
+	AssignUser(userID uint, groupID *uint) error
+
+	ResolvePrice(userID uint, product *entity.Product) (float64, error)
+	ApplyUserPrices(userID uint, products []entity.Product) error
+}
+
+type priceGroupService struct {
+	repo        repository.PriceGroupRepository
+	userRepo    repository.UserRepository
+	productRepo repository.ProductRepository
+}
+
+func NewPriceGroupService(repo repository.PriceGroupRepository, userRepo repository.UserRepository, productRepo repository.ProductRepository) PriceGroupService {
+	return &priceGroupService{
+		repo:        repo,
+		userRepo:    userRepo,
+		productRepo: productRepo,
+	}
+}
+
+func (s *priceGroupService) CreatePriceGroup(group *entity.PriceGroup) error {
+	group.Name = strings.TrimSpace(group.Name)
+	if group.Name == "" {
+		return middleware.NewAppError(400, "Price group name is required", nil)
+	}
+	if err := s.repo.Create(group); err != nil {
+		return middleware.NewAppError(500, "Failed to create price group", err)
+	}
+	if group.IsDefault {
+		return s.repo.ClearDefault(group.ID)
+	}
+	return nil
+}
+
+func (s *priceGroupService) GetAllPriceGroups() ([]entity.PriceGroup, error) {
+	return s.repo.GetAll()
+}
+
+func (s *priceGroupService) GetPriceGroupByID(id uint) (*entity.PriceGroup, error) {
+	group, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, middleware.NewAppError(404, err.Error(), err)
+	}
+	return group, nil
+}
+
+func (s *priceGroupService) UpdatePriceGroup(group *entity.PriceGroup) error {
+	if _, err := s.GetPriceGroupByID(group.ID); err != nil {
+		return err
+	}
+	group.Name = strings.TrimSpace(group.Name)
+	if group.Name == "" {
+		return middleware.NewAppError(400, "Price group name is required", nil)
+	}
+	if err := s.repo.Update(group); err != nil {
+		return middleware.NewAppError(500, "Failed to update price group", err)
+	}
+	if group.IsDefault {
+		return s.repo.ClearDefault(group.ID)
+	}
+	return nil
+}
+
+// DeletePriceGroup - Menghapus price group; user di dalamnya kembali ke price group default
+func (s *priceGroupService) DeletePriceGroup(id uint) error {
+	if _, err := s.GetPriceGroupByID(id); err != nil {
+		return err
+	}
+	return s.repo.Delete(id)
+}
+
+func (s *priceGroupService) SetProductPrice(price *entity.PriceGroupPrice) error {
+	if price.Price <= 0 {
+		return middleware.NewAppError(400, "Price must be greater than 0", nil)
+	}
+	if _, err := s.GetPriceGroupByID(price.PriceGroupID); err != nil {
+		return err
+	}
+	if _, err := s.productRepo.GetByID(price.ProductID); err != nil {
+		return middleware.NewAppError(404, "Product not found", err)
+	}
+	return s.repo.UpsertPrice(price)
+}
+
+func (s *priceGroupService) DeleteProductPrice(groupID uint, productID uint) error {
+	return s.repo.DeletePrice(groupID, productID)
+}
+
+func (s *priceGroupService) SetCategoryRule(rule *entity.PriceGroupRule) error {
+	if rule.Type != entity.MarkupTypeFixed && rule.Type != entity.MarkupTypePercentage {
+		return middleware.NewAppError(400, "Type must be fixed or percentage", nil)
+	}
+	if rule.Type == entity.MarkupTypePercentage && rule.Value <= -100 {
+		return middleware.NewAppError(400, "Percentage adjustment must be greater than -100", nil)
+	}
+	if _, err := s.GetPriceGroupByID(rule.PriceGroupID); err != nil {
+		return err
+	}
+	if _, err := s.productRepo.GetCategoryByID(rule.CategoryID); err != nil {
+		return middleware.NewAppError(404, "Category not found", err)
+	}
+	return s.repo.UpsertRule(rule)
+}
+
+func (s *priceGroupService) DeleteCategoryRule(groupID uint, categoryID uint) error {
+	return s.repo.DeleteRule(groupID, categoryID)
+}
+
+// AssignUser - Memasukkan user ke price group (nil = kembali ke default)
+func (s *priceGroupService) AssignUser(userID uint, groupID *uint) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return middleware.NewAppError(404, "User not found", err)
+	}
+	if groupID != nil {
+		if _, err := s.GetPriceGroupByID(*groupID); err != nil {
+			return err
+		}
+	}
+
+	user.PriceGroupID = groupID
+	if err := s.userRepo.Update(user); err != nil {
+		return middleware.NewAppError(500, "Failed to assign price group", err)
+	}
+
+	middleware.Logger.Info("Service: Price group assigned", zap.Uint("user_id", userID))
+	return nil
+}
+
+// ResolvePrice - Harga produk yang berlaku untuk user saat membeli
+func (s *priceGroupService) ResolvePrice(userID uint, product *entity.Product) (float64, error) {
+	group, err := s.groupForUser(userID)
+	if err != nil {
+		return 0, err
+	}
+	ancestors, err := s.productRepo.GetCategoryAncestorIDs()
+	if err != nil {
+		return 0, err
+	}
+	return priceInGroup(group, product, categoryChain(ancestors, product.CategoryID)), nil
+}
+
+// ApplyUserPrices - Mengganti Price setiap produk dengan harga milik user
+func (s *priceGroupService) ApplyUserPrices(userID uint, products []entity.Product) error {
+	group, err := s.groupForUser(userID)
+	if err != nil {
+		return err
+	}
+	ancestors, err := s.productRepo.GetCategoryAncestorIDs()
+	if err != nil {
+		return err
+	}
+	for i := range products {
+		products[i].Price = priceInGroup(group, &products[i], categoryChain(ancestors, products[i].CategoryID))
+	}
+	return nil
+}
+
+func (s *priceGroupService) groupForUser(userID uint) (*entity.PriceGroup, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, middleware.NewAppError(404, "User not found", err)
+	}
+	if user.PriceGroupID != nil {
+		group, err := s.repo.GetByID(*user.PriceGroupID)
+		if err == nil {
+			return group, nil
+		}
+		middleware.Logger.Warn("Service: User price group not found, using default", zap.Uint("user_id", userID), zap.Error(err))
+	}
+	return s.repo.GetDefault()
+}
+
+// priceInGroup - Harga khusus produk > aturan kategori > harga dasar produk.
+// Aturan kategori terdekat di categoryIDs (kategori produk lalu induknya) yang dipakai.
+func priceInGroup(group *entity.PriceGroup, product *entity.Product, categoryIDs []uint) float64 {
+	if group == nil {
+		return product.Price
+	}
+
+	for _, price := range group.Prices {
+		if price.ProductID == product.ID {
+			return price.Price
+		}
+	}
+
+	for _, categoryID := range categoryIDs {
+		index := slices.IndexFunc(group.Rules, func(rule entity.PriceGroupRule) bool { return rule.CategoryID == categoryID })
+		if index < 0 {
+			continue
+		}
+		rule := group.Rules[index]
+		adjusted := product.Price + rule.Value
+		if rule.Type == entity.MarkupTypePercentage {
+			adjusted = product.Price + product.Price*rule.Value/100
+		}
+		if adjusted <= 0 {
+			return product.Price
+		}
+		return math.Ceil(adjusted)
+	}
+
+	return product.Price
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+	"main.go/entity"
+	"main.go/middleware"
+	"main.go/repository"
+)
+
+// priceScheduleInterval - Seberapa sering worker memeriksa jadwal harga yang jatuh tempo
+const priceScheduleInterval = time.Minute
+
+type PriceScheduleService interface {
+	CreateSchedule(adminID uint, request entity.PriceScheduleRequest) (*entity.PriceSchedule, error)
+	GetSchedules(status string) ([]entity.PriceSchedule, error)
+	CancelSchedule(id uint) error
+	GetProductPrices(productID uint) (*entity.ProductPriceInfo, error)
+
+	RecordChange(productID uint, oldPrice float64, newPrice float64, source string, adminID uint)
+	ApplyDueSchedules()
+	StartWorker()
+}
+
+type priceScheduleService struct {
+	repo               repository.PriceScheduleRepository
+	productRepo        repository.ProductRepository
+	activityLogService ActivityLogService
+}
+
+func NewPriceScheduleService(repo repository.PriceScheduleRepository, productRepo repository.ProductRepository, activityLogService ActivityLogService) PriceScheduleService {
+	return &priceScheduleService{
+		repo:               repo,
+		productRepo:        productRepo,
+		activityLogService: activityLogService,
+	}
+}
+
+func (s *priceScheduleService) CreateSchedule(adminID uint, request entity.PriceScheduleRequest) (*entity.PriceSchedule, error) {
+	switch request.Scope {
+	case entity.PriceScheduleProduct:
+		if _, err := s.productRepo.GetByID(request.TargetID); err != nil {
+			return nil, middleware.NewAppError(400, "target_id must be an existing product", err)
+		}
+	case entity.PriceScheduleCategory:
+		if _, err := s.productRepo.GetCategoryByID(request.TargetID); err != nil {
+			return nil, middleware.NewAppError(400, "target_id must be an existing category", err)
+		}
+		if request.ChangeType == entity.PriceChangeFixed {
+			return nil, middleware.NewAppError(400, "Category schedules must use percentage or amount", nil)
+		}
+	default:
+		return nil, middleware.NewAppError(400, "scope must be product or category", nil)
+	}
+
+	switch request.ChangeType {
+	case entity.PriceChangeFixed:
+		if request.Value <= 0 {
+			return nil, middleware.NewAppError(400, "Fixed price must be greater than 0", nil)
+		}
+	case entity.PriceChangePercentage:
+		if request.Value == 0 || request.Value <= -100 {
+			return nil, middleware.NewAppError(400, "Percentage must be non-zero and greater than -100", nil)
+		}
+	case entity.PriceChangeAmount:
+		if request.Value == 0 {
+			return nil, middleware.NewAppError(400, "Amount must be non-zero", nil)
+		}
+	default:
+		return nil, middleware.NewAppError(400, "change_type must be fixed, percentage or amount", nil)
+	}
+
+	if !request.EffectiveAt.After(time.Now()) {
+		return nil, middleware.NewAppError(400, "effective_at must be in the future", nil)
+	}
+
+	schedule := &entity.PriceSchedule{
+		Scope:       request.Scope,
+		TargetID:    request.TargetID,
+		ChangeType:  request.ChangeType,
+		Value:       request.Value,
+		EffectiveAt: request.EffectiveAt,
+		Status:      entity.PriceSchedulePending,
+		CreatedBy:   adminID,
+	}
+	if err := s.repo.CreateSchedule(schedule); err != nil {
+		return nil, middleware.NewAppError(500, "Failed to create price schedule", err)
+	}
+	return schedule, nil
+}
+
+func (s *priceScheduleService) GetSchedules(status string) ([]entity.PriceSchedule, error) {
+	return s.repo.GetSchedules(status)
+}
+
+func (s *priceScheduleService) CancelSchedule(id uint) error {
+	if _, err := s.repo.GetScheduleByID(id); err != nil {
+		return middleware.NewAppError(404, err.Error(), err)
+	}
+	if err := s.repo.CancelSchedule(id); err != nil {
+		if errors.Is(err, repository.ErrScheduleNotPending) {
+			return middleware.NewAppError(409, "Only pending schedules can be cancelled", err)
+		}
+		return err
+	}
+	return nil
+}
+
+// GetProductPrices - Harga dasar saat ini, riwayat perubahan dan jadwal yang akan berlaku
+func (s *priceScheduleService) GetProductPrices(productID uint) (*entity.ProductPriceInfo, error) {
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, middleware.NewAppError(404, "Product not found", err)
+	}
+	history, err := s.repo.GetHistory(productID)
+	if err != nil {
+		return nil, err
+	}
+	ancestors, err := s.productRepo.GetCategoryAncestorIDs()
+	if err != nil {
+		return nil, err
+	}
+	upcoming, err := s.repo.GetUpcoming(product.ID, categoryChain(ancestors, product.CategoryID))
+	if err != nil {
+		return nil, err
+	}
+	return &entity.ProductPriceInfo{
+		ProductID:    product.ID,
+		CurrentPrice: product.Price,
+		History:      history,
+		Upcoming:     upcoming,
+	}, nil
+}
+
+// RecordChange - Mencatat perubahan harga dari update manual atau impor. Kegagalan hanya dicatat di log.
+func (s *priceScheduleService) RecordChange(productID uint, oldPrice float64, newPrice float64, source string, adminID uint) {
+	if oldPrice == newPrice {
+		return
+	}
+	entry := entity.ProductPriceHistory{
+		ProductID: productID,
+		OldPrice:  oldPrice,
+		NewPrice:  newPrice,
+		Source:    source,
+	}
+	if adminID != 0 {
+		entry.ChangedBy = &adminID
+	}
+	if err := s.repo.RecordHistory([]entity.ProductPriceHistory{entry}); err != nil {
+		middleware.Logger.Error("Failed to record price history", zap.Uint("product_id", productID), zap.Error(err))
+	}
+}
+
+// ApplyDueSchedules - Menerapkan semua jadwal pending yang waktunya sudah tiba, urut dari yang paling awal
+func (s *priceScheduleService) ApplyDueSchedules() {
+	now := time.Now()
+	ids, err := s.repo.GetDueScheduleIDs(now)
+	if err != nil {
+		middleware.Logger.Error("Failed to fetch due price schedules", zap.Error(err))
+		return
+	}
+
+	for _, id := range ids {
+		schedule, err := s.repo.ApplySchedule(id, now, calculateScheduledPrice)
+		if err != nil {
+			if !errors.Is(err, repository.ErrScheduleNotPending) {
+				middleware.Logger.Error("Failed to apply price schedule", zap.Uint("schedule_id", id), zap.Error(err))
+			}
+			continue
+		}
+
+		details := fmt.Sprintf("Schedule #%d (%s %d, %s %.2f): %s, %d products updated",
+			schedule.ID, schedule.Scope, schedule.TargetID, schedule.ChangeType, schedule.Value, schedule.Status, schedule.ProductsUpdated)
+		if schedule.FailReason != "" {
+			details += ", reason: " + schedule.FailReason
+		}
+		middleware.Logger.Info("Price schedule processed", zap.Uint("schedule_id", schedule.ID), zap.String("status", schedule.Status))
+		if err := s.activityLogService.CreateActivityLog(schedule.CreatedBy, "Price Schedule Applied", details); err != nil {
+			middleware.Logger.Warn("Failed to log price schedule", zap.Error(err))
+		}
+	}
+}
+
+// StartWorker - Menjalankan pemeriksaan jadwal harga secara berkala di background
+func (s *priceScheduleService) StartWorker() {
+	go func() {
+		ticker := time.NewTicker(priceScheduleInterval)
+		defer ticker.Stop()
+		for {
+			s.ApplyDueSchedules()
+			<-ticker.C
+		}
+	}()
+}
+
+// calculateScheduledPrice - Harga baru dibulatkan ke 2 desimal dan harus tetap lebih dari 0
+func calculateScheduledPrice(schedule *entity.PriceSchedule, product *entity.Product) (float64, error) {
+	var price float64
+	switch schedule.ChangeType {
+	case entity.PriceChangeFixed:
+		price = schedule.Value
+	case entity.PriceChangePercentage:
+		price = product.Price * (1 + schedule.Value/100)
+	case entity.PriceChangeAmount:
+		price = product.Price + schedule.Value
+	default:
+		return 0, fmt.Errorf("unsupported change type %q", schedule.ChangeType)
+	}
+
+	price = math.Round(price*100) / 100
+	if price <= 0 {
+		return 0, fmt.Errorf("new price for %s would be %.2f", product.Name, price)
+	}
+	return price, nil
+}
@@ -0,0 +1,296 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"main.go/entity"
+	"main.go/middleware"
+	"main.go/repository"
+)
+
+type PricingService interface {
+	CreateMarkupRule(rule *entity.MarkupRule) error
+	GetAllMarkupRules() ([]entity.MarkupRule, error)
+	UpdateMarkupRule(rule *entity.MarkupRule) error
+	DeleteMarkupRule(id uint) error
+
+	SetProductCost(cost *entity.ProductCost) error
+	GetProductCosts(productID uint) ([]entity.ProductCost, error)
+	EffectiveCost(product *entity.Product) float64
+
+	PreviewMarkup(candidate *entity.MarkupRule) ([]entity.MarkupPreviewItem, error)
+	ApplyMarkup() ([]entity.MarkupPreviewItem, error)
+}
+
+type pricingService struct {
+	repo        repository.PricingRepository
+	productRepo repository.ProductRepository
+}
+
+func NewPricingService(repo repository.PricingRepository, productRepo repository.ProductRepository) PricingService {
+	return &pricingService{
+		repo:        repo,
+		productRepo: productRepo,
+	}
+}
+
+func (s *pricingService) CreateMarkupRule(rule *entity.MarkupRule) error {
+	if err := validateMarkupRule(rule); err != nil {
+		return err
+	}
+	return s.repo.CreateMarkupRule(rule)
+}
+
+func (s *pricingService) GetAllMarkupRules() ([]entity.MarkupRule, error) {
+	return s.repo.GetAllMarkupRules()
+}
+
+func (s *pricingService) UpdateMarkupRule(rule *entity.MarkupRule) error {
+	if _, err := s.repo.GetMarkupRuleByID(rule.ID); err != nil {
+		return middleware.NewAppError(404, err.Error(), err)
+	}
+	if err := validateMarkupRule(rule); err != nil {
+		return err
+	}
+	return s.repo.UpdateMarkupRule(rule)
+}
+
+func (s *pricingService) DeleteMarkupRule(id uint) error {
+	if _, err := s.repo.GetMarkupRuleByID(id); err != nil {
+		return middleware.NewAppError(404, err.Error(), err)
+	}
+	return s.repo.DeleteMarkupRule(id)
+}
+
+// SetProductCost - Menyimpan harga modal produk untuk supplier tertentu
+func (s *pricingService) SetProductCost(cost *entity.ProductCost) error {
+	cost.Supplier = strings.TrimSpace(cost.Supplier)
+	if cost.Supplier == "" {
+		return middleware.NewAppError(400, "Supplier is required", nil)
+	}
+	if cost.CostPrice <= 0 {
+		return middleware.NewAppError(400, "Cost price must be greater than 0", nil)
+	}
+	if _, err := s.productRepo.GetByID(cost.ProductID); err != nil {
+		return middleware.NewAppError(404, "Product not found", err)
+	}
+	return s.repo.UpsertProductCost(cost)
+}
+
+func (s *pricingService) GetProductCosts(productID uint) ([]entity.ProductCost, error) {
+	return s.repo.GetProductCosts(productID)
+}
+
+// EffectiveCost - Harga modal dari supplier aktif, atau harga modal default produk
+func (s *pricingService) EffectiveCost(product *entity.Product) float64 {
+	if product.Supplier != "" {
+		cost, err := s.repo.GetProductCost(product.ID, product.Supplier)
+		if err == nil {
+			return cost.CostPrice
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			middleware.Logger.Error("Service: Failed to fetch supplier cost", zap.Uint("product_id", product.ID), zap.Error(err))
+		}
+	}
+	return product.CostPrice
+}
+
+// PreviewMarkup - Menghitung harga baru semua produk jika aturan kandidat diterapkan, tanpa menyimpan apa pun.
+// Kandidat dengan ID menggantikan aturan yang ada; kandidat tanpa ID ditambahkan; nil = pratinjau aturan saat ini.
+func (s *pricingService) PreviewMarkup(candidate *entity.MarkupRule) ([]entity.MarkupPreviewItem, error) {
+	rules, err := s.repo.GetActiveMarkupRules()
+	if err != nil {
+		return nil, err
+	}
+
+	if candidate != nil {
+		if err := validateMarkupRule(candidate); err != nil {
+			return nil, err
+		}
+		replaced := false
+		for i := range rules {
+			if candidate.ID != 0 && rules[i].ID == candidate.ID {
+				rules[i] = *candidate
+				replaced = true
+			}
+		}
+		if !replaced {
+			rules = append(rules, *candidate)
+		}
+	}
+
+	return s.computeMarkup(rules)
+}
+
+// ApplyMarkup - Menerapkan aturan markup aktif ke harga jual produk yang memiliki harga modal
+func (s *pricingService) ApplyMarkup() ([]entity.MarkupPreviewItem, error) {
+	rules, err := s.repo.GetActiveMarkupRules()
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := s.computeMarkup(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	// Semua harga diubah dalam satu transaksi; harga yang berubah sejak dihitung membatalkan seluruh penerapan
+	if err := s.repo.ApplyMarkup(changes); err != nil {
+		middleware.Logger.Error("Service: Failed to apply markup", zap.Int("changed_products", len(changes)), zap.Error(err))
+		return nil, versionConflict(err)
+	}
+
+	middleware.Logger.Info("Service: Markup applied", zap.Int("changed_products", len(changes)))
+	return changes, nil
+}
+
+// computeMarkup - Mengembalikan produk yang harga jualnya berubah menurut kumpulan aturan
+func (s *pricingService) computeMarkup(rules []entity.MarkupRule) ([]entity.MarkupPreviewItem, error) {
+	products, err := s.productRepo.GetAllProducts()
+	if err != nil {
+		return nil, err
+	}
+
+	allCosts, err := s.repo.GetAllProductCosts()
+	if err != nil {
+		return nil, err
+	}
+	supplierCosts := make(map[uint]map[string]float64)
+	for _, cost := range allCosts {
+		if supplierCosts[cost.ProductID] == nil {
+			supplierCosts[cost.ProductID] = make(map[string]float64)
+		}
+		supplierCosts[cost.ProductID][cost.Supplier] = cost.CostPrice
+	}
+
+	ancestors, err := s.productRepo.GetCategoryAncestorIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []entity.MarkupPreviewItem
+	for _, product := range products {
+		cost := product.CostPrice
+		if supplierCost, ok := supplierCosts[product.ID][product.Supplier]; ok {
+			cost = supplierCost
+		}
+		if cost <= 0 {
+			continue
+		}
+
+		rule := matchMarkupRule(rules, &product, categoryChain(ancestors, product.CategoryID))
+		if rule == nil {
+			continue
+		}
+
+		newPrice := applyMarkupRule(rule, cost)
+		if newPrice == product.Price {
+			continue
+		}
+
+		changes = append(changes, entity.MarkupPreviewItem{
+			ProductID:     product.ID,
+			ProductName:   product.Name,
+			CostPrice:     cost,
+			CurrentPrice:  product.Price,
+			NewPrice:      newPrice,
+			CurrentMargin: product.Price - cost,
+			NewMargin:     newPrice - cost,
+			RuleID:        rule.ID,
+			RuleName:      rule.Name,
+		})
+	}
+
+	return changes, nil
+}
+
+// matchMarkupRule - Aturan paling spesifik menang: operator > kategori > global, lalu prioritas tertinggi.
+// Aturan kategori berlaku juga untuk sub-kategori; kategori yang lebih dekat dengan produk lebih spesifik.
+func matchMarkupRule(rules []entity.MarkupRule, product *entity.Product, categoryIDs []uint) *entity.MarkupRule {
+	specificity := map[string]int{
+		entity.MarkupScopeOperator: 3,
+		entity.MarkupScopeCategory: 2,
+		entity.MarkupScopeGlobal:   1,
+	}
+
+	var best *entity.MarkupRule
+	bestDistance := 0
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.IsActive {
+			continue
+		}
+
+		// distance - Jarak kategori aturan ke kategori produk (0 = kategori produk itu sendiri)
+		distance := 0
+		switch rule.Scope {
+		case entity.MarkupScopeOperator:
+			if product.Operator == "" || !strings.EqualFold(rule.Operator, product.Operator) {
+				continue
+			}
+		case entity.MarkupScopeCategory:
+			if rule.CategoryID == nil {
+				continue
+			}
+			distance = slices.Index(categoryIDs, *rule.CategoryID)
+			if distance < 0 {
+				continue
+			}
+		}
+
+		if best == nil ||
+			specificity[rule.Scope] > specificity[best.Scope] ||
+			(specificity[rule.Scope] == specificity[best.Scope] && distance < bestDistance) ||
+			(specificity[rule.Scope] == specificity[best.Scope] && distance == bestDistance && rule.Priority > best.Priority) {
+			best = rule
+			bestDistance = distance
+		}
+	}
+	return best
+}
+
+// applyMarkupRule - Harga jual dibulatkan ke atas ke rupiah penuh
+func applyMarkupRule(rule *entity.MarkupRule, cost float64) float64 {
+	if rule.Type == entity.MarkupTypePercentage {
+		return math.Ceil(cost + cost*rule.Value/100)
+	}
+	return math.Ceil(cost + rule.Value)
+}
+
+func validateMarkupRule(rule *entity.MarkupRule) error {
+	rule.Name = strings.TrimSpace(rule.Name)
+	if rule.Name == "" {
+		return middleware.NewAppError(400, "Markup rule name is required", nil)
+	}
+
+	switch rule.Scope {
+	case entity.MarkupScopeGlobal:
+		rule.CategoryID = nil
+		rule.Operator = ""
+	case entity.MarkupScopeCategory:
+		if rule.CategoryID == nil || *rule.CategoryID == 0 {
+			return middleware.NewAppError(400, "category_id is required for category scope", nil)
+		}
+		rule.Operator = ""
+	case entity.MarkupScopeOperator:
+		if strings.TrimSpace(rule.Operator) == "" {
+			return middleware.NewAppError(400, "operator is required for operator scope", nil)
+		}
+		rule.CategoryID = nil
+	default:
+		return middleware.NewAppError(400, "Scope must be global, category or operator", nil)
+	}
+
+	if rule.Type != entity.MarkupTypeFixed && rule.Type != entity.MarkupTypePercentage {
+		return middleware.NewAppError(400, "Type must be fixed or percentage", nil)
+	}
+	if rule.Value < 0 {
+		return middleware.NewAppError(400, "Markup value cannot be negative", nil)
+	}
+	return nil
+}
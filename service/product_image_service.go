@@ -0,0 +1,202 @@
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"path"
+	"strings"
+
+	"go.uber.org/zap"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+	"main.go/entity"
+	"main.go/middleware"
+	"main.go/repository"
+)
+
+// Batas gambar produk yang diunggah
+const (
+	MaxProductImageBytes = 5 << 20 // 5 MB
+	maxImageDimension    = 4096
+	minImageDimension    = 64
+)
+
+// productImageKeyPrefix - Prefix key storage untuk gambar produk
+const productImageKeyPrefix = "products/"
+
+// productImageCacheControl - Nama file berbasis hash konten sehingga isinya tidak pernah berubah
+const productImageCacheControl = "public, max-age=31536000, immutable"
+
+// thumbnailSizes - Sisi terpanjang thumbnail per ukuran
+var thumbnailSizes = map[string]int{
+	"sm": 150,
+	"md": 400,
+}
+
+// allowedImageTypes - Content type hasil sniffing yang diterima beserta ekstensi file-nya
+var allowedImageTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+type ProductImageService interface {
+	UploadImage(productID uint, data []byte) (*entity.ProductImageResult, error)
+}
+
+type productImageService struct {
+	productRepo repository.ProductRepository
+	storage     repository.StorageRepository
+}
+
+func NewProductImageService(productRepo repository.ProductRepository, storage repository.StorageRepository) ProductImageService {
+	return &productImageService{productRepo: productRepo, storage: storage}
+}
+
+// UploadImage - Memvalidasi gambar, menyimpannya dengan nama berbasis hash konten, membuat thumbnail,
+// lalu menghapus gambar lama produk jika tidak dipakai produk lain
+func (s *productImageService) UploadImage(productID uint, data []byte) (*entity.ProductImageResult, error) {
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, middleware.NewAppError(404, "Product not found", err)
+	}
+
+	if len(data) == 0 {
+		return nil, middleware.NewAppError(400, "Image file is empty", nil)
+	}
+	if len(data) > MaxProductImageBytes {
+		return nil, middleware.NewAppError(413, fmt.Sprintf("Image must be at most %d MB", MaxProductImageBytes>>20), nil)
+	}
+
+	// Jenis file ditentukan dari isi file, bukan dari nama atau header yang dikirim klien
+	contentType := http.DetectContentType(data)
+	ext, ok := allowedImageTypes[contentType]
+	if !ok {
+		return nil, middleware.NewAppError(415, "Image must be JPEG, PNG, GIF or WebP", nil)
+	}
+
+	// Dimensi diperiksa sebelum decode penuh agar gambar raksasa tidak menghabiskan memori
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, middleware.NewAppError(400, "Invalid image file", err)
+	}
+	if config.Width > maxImageDimension || config.Height > maxImageDimension {
+		return nil, middleware.NewAppError(400, fmt.Sprintf("Image dimensions must be at most %dx%d", maxImageDimension, maxImageDimension), nil)
+	}
+	if config.Width < minImageDimension || config.Height < minImageDimension {
+		return nil, middleware.NewAppError(400, fmt.Sprintf("Image dimensions must be at least %dx%d", minImageDimension, minImageDimension), nil)
+	}
+
+	source, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, middleware.NewAppError(400, "Invalid image file", err)
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	result := &entity.ProductImageResult{
+		ImageURL:   s.storage.PublicURL(productImageKeyPrefix + hash + ext),
+		Thumbnails: make(map[string]string, len(thumbnailSizes)),
+	}
+
+	if err := s.putImage(hash+ext, data, contentType); err != nil {
+		return nil, middleware.NewAppError(500, "Failed to save image", err)
+	}
+
+	// JPEG tetap JPEG, format lain (mungkin transparan) dijadikan PNG
+	thumbExt, thumbType := ".png", "image/png"
+	if contentType == "image/jpeg" {
+		thumbExt, thumbType = ".jpg", "image/jpeg"
+	}
+	for size, maxSide := range thumbnailSizes {
+		thumbnail, err := encodeThumbnail(source, maxSide, thumbExt)
+		if err != nil {
+			return nil, middleware.NewAppError(500, "Failed to create thumbnail", err)
+		}
+		name := fmt.Sprintf("%s_%s%s", hash, size, thumbExt)
+		if err := s.putImage(name, thumbnail, thumbType); err != nil {
+			return nil, middleware.NewAppError(500, "Failed to save thumbnail", err)
+		}
+		result.Thumbnails[size] = s.storage.PublicURL(productImageKeyPrefix + name)
+	}
+
+	if err := s.productRepo.UpdateImage(product.ID, result.ImageURL, result.Thumbnails); err != nil {
+		return nil, middleware.NewAppError(500, "Failed to update product image", err)
+	}
+
+	if product.ImageURL != "" && product.ImageURL != result.ImageURL {
+		s.deleteUnusedImage(product.ImageURL, product.Thumbnails)
+	}
+
+	middleware.Logger.Info("Service: Product image uploaded", zap.Uint("product_id", product.ID), zap.String("image_url", result.ImageURL))
+	return result, nil
+}
+
+// deleteUnusedImage - Menghapus file gambar lama beserta thumbnail-nya jika tidak ada produk lain yang memakainya
+func (s *productImageService) deleteUnusedImage(imageURL string, thumbnails map[string]string) {
+	count, err := s.productRepo.CountByImageURL(imageURL)
+	if err != nil || count > 0 {
+		return
+	}
+
+	// Hanya URL milik storage ini yang dihapus, URL eksternal dibiarkan
+	urlPrefix := s.storage.PublicURL(productImageKeyPrefix)
+	urls := []string{imageURL}
+	for _, url := range thumbnails {
+		urls = append(urls, url)
+	}
+	for _, url := range urls {
+		if !strings.HasPrefix(url, urlPrefix) {
+			continue
+		}
+		key := productImageKeyPrefix + path.Base(url)
+		if err := s.storage.Delete(key); err != nil {
+			middleware.Logger.Warn("Failed to delete old product image", zap.String("key", key), zap.Error(err))
+		}
+	}
+}
+
+func (s *productImageService) putImage(name string, data []byte, contentType string) error {
+	return s.storage.Put(productImageKeyPrefix+name, data, repository.PutOptions{
+		ContentType:  contentType,
+		CacheControl: productImageCacheControl,
+	})
+}
+
+// encodeThumbnail - Memperkecil gambar dengan sisi terpanjang maxSide (tidak pernah memperbesar)
+func encodeThumbnail(source image.Image, maxSide int, ext string) ([]byte, error) {
+	bounds := source.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > maxSide || height > maxSide {
+		if width >= height {
+			height = max(1, height*maxSide/width)
+			width = maxSide
+		} else {
+			width = max(1, width*maxSide/height)
+			height = maxSide
+		}
+	}
+
+	thumbnail := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(thumbnail, thumbnail.Bounds(), source, bounds, draw.Over, nil)
+
+	var buffer bytes.Buffer
+	var err error
+	if ext == ".jpg" {
+		err = jpeg.Encode(&buffer, thumbnail, &jpeg.Options{Quality: 85})
+	} else {
+		err = png.Encode(&buffer, thumbnail)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
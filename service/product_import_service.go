@@ -0,0 +1,411 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+	"go.uber.org/zap"
+	"main.go/entity"
+	"main.go/middleware"
+	"main.go/repository"
+)
+
+// Format file impor/ekspor katalog
+const (
+	CatalogFormatCSV  = "csv"
+	CatalogFormatXLSX = "xlsx"
+)
+
+// maxImportRows - Batas jumlah baris data dalam satu file impor
+const maxImportRows = 5000
+
+// requiredImportColumns - Kolom yang wajib ada pada header file impor
+var requiredImportColumns = []string{"code", "name", "category", "price"}
+
+type ProductImportService interface {
+	Import(adminID uint, filename string, file io.Reader, dryRun bool) (*entity.ProductImportResult, error)
+	Export(format string) ([]byte, error)
+}
+
+type productImportService struct {
+	productRepo          repository.ProductRepository
+	activityLogService   ActivityLogService
+	priceScheduleService PriceScheduleService
+	inventoryService     InventoryService
+}
+
+func NewProductImportService(productRepo repository.ProductRepository, activityLogService ActivityLogService, priceScheduleService PriceScheduleService, inventoryService InventoryService) ProductImportService {
+	return &productImportService{
+		productRepo:          productRepo,
+		activityLogService:   activityLogService,
+		priceScheduleService: priceScheduleService,
+		inventoryService:     inventoryService,
+	}
+}
+
+// importRecord - Satu baris data yang sudah lolos validasi. Kolom kosong atau tidak ada tidak masuk ke fields.
+type importRecord struct {
+	row    int
+	code   string
+	fields map[string]string
+}
+
+// Import - Upsert produk dan kategori berdasarkan kode produk. Baris tidak valid dilewati dan dilaporkan.
+// Saat dryRun tidak ada yang disimpan, hanya diff yang dikembalikan.
+func (s *productImportService) Import(adminID uint, filename string, file io.Reader, dryRun bool) (*entity.ProductImportResult, error) {
+	middleware.Logger.Info("Service: Import products called", zap.String("file", filename), zap.Bool("dry_run", dryRun))
+
+	rows, err := readCatalogFile(filename, file)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) < 2 {
+		return nil, middleware.NewAppError(400, "File has no data rows", nil)
+	}
+	if len(rows)-1 > maxImportRows {
+		return nil, middleware.NewAppError(400, fmt.Sprintf("File exceeds the maximum of %d rows", maxImportRows), nil)
+	}
+
+	header := make(map[string]int)
+	for i, name := range rows[0] {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if _, exists := header[name]; !exists {
+			header[name] = i
+		}
+	}
+	for _, column := range requiredImportColumns {
+		if _, ok := header[column]; !ok {
+			return nil, middleware.NewAppError(400, fmt.Sprintf("Missing required column %q", column), nil)
+		}
+	}
+
+	result := &entity.ProductImportResult{
+		DryRun:            dryRun,
+		Created:           []entity.ProductImportRow{},
+		Updated:           []entity.ProductImportRow{},
+		Invalid:           []entity.ProductImportError{},
+		CategoriesCreated: []string{},
+	}
+
+	var records []importRecord
+	seenCodes := make(map[string]int)
+	for i, row := range rows[1:] {
+		rowNumber := i + 2
+		record := importRecord{row: rowNumber, fields: make(map[string]string)}
+		empty := true
+		for column, index := range header {
+			if index < len(row) {
+				if value := strings.TrimSpace(row[index]); value != "" {
+					record.fields[column] = value
+					empty = false
+				}
+			}
+		}
+		if empty {
+			continue
+		}
+
+		record.code = strings.ToUpper(record.fields["code"])
+		if err := validateImportRecord(record); err != nil {
+			result.Invalid = append(result.Invalid, entity.ProductImportError{Row: rowNumber, Code: record.code, Error: err.Error()})
+			continue
+		}
+		if firstRow, duplicate := seenCodes[record.code]; duplicate {
+			result.Invalid = append(result.Invalid, entity.ProductImportError{
+				Row:   rowNumber,
+				Code:  record.code,
+				Error: fmt.Sprintf("duplicate code, already used at row %d", firstRow),
+			})
+			continue
+		}
+		seenCodes[record.code] = rowNumber
+		records = append(records, record)
+	}
+
+	codes := make([]string, 0, len(records))
+	for _, record := range records {
+		codes = append(codes, record.code)
+	}
+	existingProducts, err := s.productRepo.GetByCodes(codes)
+	if err != nil {
+		return nil, middleware.NewAppError(500, "Failed to load existing products", err)
+	}
+	productsByCode := make(map[string]entity.Product, len(existingProducts))
+	for _, product := range existingProducts {
+		productsByCode[*product.Code] = product
+	}
+
+	categories, err := s.productRepo.GetAllCategories()
+	if err != nil {
+		return nil, middleware.NewAppError(500, "Failed to load categories", err)
+	}
+	categoriesByName := make(map[string]entity.Category, len(categories))
+	categoryNames := make(map[uint]string, len(categories))
+	for _, category := range categories {
+		categoriesByName[strings.ToLower(category.Name)] = category
+		categoryNames[category.ID] = category.Name
+	}
+
+	oldPrices := make(map[string]float64)
+	newCategories := make(map[string]*entity.Category)
+	var newCategoryList []*entity.Category
+	var products []*entity.Product
+	for _, record := range records {
+		categoryName := record.fields["category"]
+		categoryID := uint(0)
+		if category, ok := categoriesByName[strings.ToLower(categoryName)]; ok {
+			categoryID = category.ID
+			categoryName = category.Name
+		} else if category, ok := newCategories[strings.ToLower(categoryName)]; ok {
+			categoryName = category.Name
+		} else {
+			category := &entity.Category{Name: categoryName}
+			newCategories[strings.ToLower(categoryName)] = category
+			newCategoryList = append(newCategoryList, category)
+			result.CategoriesCreated = append(result.CategoriesCreated, categoryName)
+		}
+
+		existing, exists := productsByCode[record.code]
+		if !exists {
+			code := record.code
+			product := &entity.Product{Code: &code, IsActive: true}
+			applyImportRecord(product, record)
+			product.CategoryID = categoryID
+			product.Category = entity.Category{Name: categoryName}
+			products = append(products, product)
+			result.Created = append(result.Created, entity.ProductImportRow{
+				Row:    record.row,
+				Code:   record.code,
+				Name:   product.Name,
+				Action: entity.ImportActionCreate,
+			})
+			continue
+		}
+
+		before := catalogValues(&existing, categoryNames[existing.CategoryID])
+		product := existing
+		applyImportRecord(&product, record)
+		product.CategoryID = categoryID
+		product.Category = entity.Category{Name: categoryName}
+		after := catalogValues(&product, categoryName)
+
+		changes := make(map[string]entity.FieldChange)
+		for i, column := range entity.ProductImportColumns {
+			if before[i] != after[i] {
+				changes[column] = entity.FieldChange{From: before[i], To: after[i]}
+			}
+		}
+		if len(changes) == 0 {
+			result.Unchanged++
+			continue
+		}
+		products = append(products, &product)
+		oldPrices[record.code] = existing.Price
+		result.Updated = append(result.Updated, entity.ProductImportRow{
+			Row:     record.row,
+			Code:    record.code,
+			Name:    product.Name,
+			Action:  entity.ImportActionUpdate,
+			Changes: changes,
+		})
+	}
+
+	if dryRun || len(products) == 0 {
+		return result, nil
+	}
+
+	movements, err := s.productRepo.ImportProducts(newCategoryList, products)
+	if err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return nil, middleware.NewAppError(412, "Products were modified during import, please retry", err)
+		}
+		if errors.Is(err, repository.ErrCategoryNameTaken) {
+			return nil, middleware.NewAppError(409, "A category in the import was created by another request, please retry", err)
+		}
+		middleware.Logger.Error("Failed to import products", zap.Error(err))
+		return nil, middleware.NewAppError(500, "Failed to import products", err)
+	}
+	for _, product := range products {
+		if oldPrice, updated := oldPrices[*product.Code]; updated {
+			s.priceScheduleService.RecordChange(product.ID, oldPrice, product.Price, entity.PriceSourceImport, adminID)
+		}
+	}
+	importedByID := make(map[uint]*entity.Product, len(products))
+	for _, product := range products {
+		importedByID[product.ID] = product
+	}
+	for i := range movements {
+		if product, ok := importedByID[movements[i].ProductID]; ok {
+			s.inventoryService.CheckLowStock(product, &movements[i])
+		}
+	}
+
+	details := fmt.Sprintf("File %s: %d created, %d updated, %d unchanged, %d invalid, %d new categories",
+		filename, len(result.Created), len(result.Updated), result.Unchanged, len(result.Invalid), len(result.CategoriesCreated))
+	if err := s.activityLogService.CreateActivityLog(adminID, "Products Imported", details); err != nil {
+		middleware.Logger.Warn("Failed to log product import", zap.Error(err))
+	}
+	return result, nil
+}
+
+// Export - Seluruh katalog dengan kolom yang sama seperti file impor
+func (s *productImportService) Export(format string) ([]byte, error) {
+	products, err := s.productRepo.GetAllProducts()
+	if err != nil {
+		return nil, middleware.NewAppError(500, "Failed to fetch products", err)
+	}
+
+	rows := [][]string{entity.ProductImportColumns}
+	for i := range products {
+		rows = append(rows, catalogValues(&products[i], products[i].Category.Name))
+	}
+
+	switch format {
+	case CatalogFormatCSV:
+		var buffer bytes.Buffer
+		writer := csv.NewWriter(&buffer)
+		if err := writer.WriteAll(rows); err != nil {
+			return nil, middleware.NewAppError(500, "Failed to write CSV", err)
+		}
+		return buffer.Bytes(), nil
+	case CatalogFormatXLSX:
+		workbook := excelize.NewFile()
+		defer workbook.Close()
+		sheet := workbook.GetSheetName(0)
+		for i, row := range rows {
+			cells := make([]interface{}, len(row))
+			for j, value := range row {
+				cells[j] = value
+			}
+			cell, _ := excelize.CoordinatesToCellName(1, i+1)
+			if err := workbook.SetSheetRow(sheet, cell, &cells); err != nil {
+				return nil, middleware.NewAppError(500, "Failed to write XLSX", err)
+			}
+		}
+		buffer, err := workbook.WriteToBuffer()
+		if err != nil {
+			return nil, middleware.NewAppError(500, "Failed to write XLSX", err)
+		}
+		return buffer.Bytes(), nil
+	}
+	return nil, middleware.NewAppError(400, "Invalid format. Use 'csv' or 'xlsx'", nil)
+}
+
+// readCatalogFile - Membaca seluruh baris CSV atau sheet pertama XLSX berdasarkan ekstensi file
+func readCatalogFile(filename string, file io.Reader) ([][]string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case "." + CatalogFormatCSV:
+		reader := csv.NewReader(file)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		rows, err := reader.ReadAll()
+		if err != nil {
+			return nil, middleware.NewAppError(400, "Invalid CSV file", err)
+		}
+		return rows, nil
+	case "." + CatalogFormatXLSX:
+		workbook, err := excelize.OpenReader(file)
+		if err != nil {
+			return nil, middleware.NewAppError(400, "Invalid XLSX file", err)
+		}
+		defer workbook.Close()
+		rows, err := workbook.GetRows(workbook.GetSheetName(0))
+		if err != nil {
+			return nil, middleware.NewAppError(400, "Invalid XLSX file", err)
+		}
+		return rows, nil
+	}
+	return nil, middleware.NewAppError(400, "Unsupported file format, use .csv or .xlsx", nil)
+}
+
+func validateImportRecord(record importRecord) error {
+	if record.code == "" {
+		return fmt.Errorf("code is required")
+	}
+	if !productCodeRegex.MatchString(record.code) {
+		return fmt.Errorf("code must be 2-32 characters of A-Z, 0-9, '_' or '-'")
+	}
+	if record.fields["name"] == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(record.fields["name"]) > 150 {
+		return fmt.Errorf("name must be at most 150 characters")
+	}
+	if record.fields["category"] == "" {
+		return fmt.Errorf("category is required")
+	}
+	if len(record.fields["operator"]) > 50 || len(record.fields["supplier"]) > 50 {
+		return fmt.Errorf("operator and supplier must be at most 50 characters")
+	}
+	if price, err := strconv.ParseFloat(record.fields["price"], 64); err != nil || price <= 0 {
+		return fmt.Errorf("price must be a number greater than 0")
+	}
+	if value, ok := record.fields["cost_price"]; ok {
+		if cost, err := strconv.ParseFloat(value, 64); err != nil || cost < 0 {
+			return fmt.Errorf("cost_price must be a number of at least 0")
+		}
+	}
+	if value, ok := record.fields["stock"]; ok {
+		if stock, err := strconv.Atoi(value); err != nil || stock < 0 {
+			return fmt.Errorf("stock must be a whole number of at least 0")
+		}
+	}
+	if value, ok := record.fields["is_active"]; ok {
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("is_active must be true or false")
+		}
+	}
+	return nil
+}
+
+// applyImportRecord - Menyalin kolom yang terisi ke produk (record sudah divalidasi).
+// Stok produk voucher tetap mengikuti jumlah kode sehingga kolom stock diabaikan.
+func applyImportRecord(product *entity.Product, record importRecord) {
+	product.Name = record.fields["name"]
+	product.Price, _ = strconv.ParseFloat(record.fields["price"], 64)
+	if value, ok := record.fields["description"]; ok {
+		product.Description = value
+	}
+	if value, ok := record.fields["operator"]; ok {
+		product.Operator = value
+	}
+	if value, ok := record.fields["supplier"]; ok {
+		product.Supplier = value
+	}
+	if value, ok := record.fields["cost_price"]; ok {
+		product.CostPrice, _ = strconv.ParseFloat(value, 64)
+	}
+	if value, ok := record.fields["stock"]; ok && !product.IsVoucher {
+		product.Stock, _ = strconv.Atoi(value)
+	}
+	if value, ok := record.fields["is_active"]; ok {
+		product.IsActive, _ = strconv.ParseBool(value)
+	}
+}
+
+// catalogValues - Nilai produk sesuai urutan entity.ProductImportColumns
+func catalogValues(product *entity.Product, categoryName string) []string {
+	code := ""
+	if product.Code != nil {
+		code = *product.Code
+	}
+	return []string{
+		code,
+		product.Name,
+		categoryName,
+		product.Operator,
+		strconv.FormatFloat(product.Price, 'f', -1, 64),
+		strconv.FormatFloat(product.CostPrice, 'f', -1, 64),
+		product.Supplier,
+		strconv.Itoa(product.Stock),
+		strconv.FormatBool(product.IsActive),
+		product.Description,
+	}
+}
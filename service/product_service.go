@@ -1,73 +1,568 @@
 package service
 
 import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
 	"main.go/entity"
+	"main.go/middleware"
 	"main.go/repository"
 )
 
+// productCodeRegex - Kode SKU huruf besar, angka, "_" atau "-"
+var productCodeRegex = regexp.MustCompile(`^[A-Z0-9_-]{2,32}$`)
+
+const (
+	defaultProductPageSize = 20
+	maxProductPageSize     = 100
+)
+
 type ProductService interface {
 	CreateCategory(category *entity.Category) error
 	GetAllCategories() ([]entity.Category, error)
+	GetCategoryTree() ([]entity.Category, error)
 	GetCategoryByID(id uint) (*entity.Category, error)
-	UpdateCategory(category *entity.Category) error
-	DeleteCategory(id uint) error
+	PatchCategory(id uint, request entity.CategoryPatchRequest, version uint) (*entity.Category, error)
+	ReplaceCategory(id uint, request entity.CategoryPatchRequest, version uint) (*entity.Category, error)
+	DeleteCategory(id uint, policy string, targetID uint, adminID uint, version uint) (*entity.CategoryDeleteResult, error)
 
 	CreateProduct(product *entity.Product) error
-	GetAllProducts() ([]entity.Product, error)
+	SearchProducts(filter *entity.ProductFilter) ([]entity.Product, int64, error)
+	SearchProductsForUser(userID uint, filter *entity.ProductFilter) ([]entity.Product, int64, error)
 	GetProductByID(id uint) (*entity.Product, error)
-	UpdateProduct(product *entity.Product) error
-	DeleteProduct(id uint) error
-	UpdateProductImage(productID string, imageURL string) error
+	GetProductByIDForUser(id uint, userID uint) (*entity.Product, error)
+	GetProductByCode(code string) (*entity.Product, error)
+	GetProductByCodeForUser(code string, userID uint) (*entity.Product, error)
+	PatchProduct(id uint, request entity.ProductPatchRequest, adminID uint, version uint) (*entity.Product, error)
+	ReplaceProduct(id uint, request entity.ProductPatchRequest, adminID uint, version uint) (*entity.Product, error)
+	DeleteProduct(id uint, version uint) error
 }
 
 type productService struct {
-	repo repository.ProductRepository
+	repo                 repository.ProductRepository
+	priceGroupService    PriceGroupService
+	maintenanceService   MaintenanceService
+	priceScheduleService PriceScheduleService
+	activityLogService   ActivityLogService
 }
 
-func NewProductService(repo repository.ProductRepository) ProductService {
-	return &productService{repo: repo}
+func NewProductService(repo repository.ProductRepository, priceGroupService PriceGroupService, maintenanceService MaintenanceService, priceScheduleService PriceScheduleService, activityLogService ActivityLogService) ProductService {
+	return &productService{
+		repo:                 repo,
+		priceGroupService:    priceGroupService,
+		maintenanceService:   maintenanceService,
+		priceScheduleService: priceScheduleService,
+		activityLogService:   activityLogService,
+	}
 }
 
 func (s *productService) CreateCategory(category *entity.Category) error {
-	return s.repo.CreateCategory(category)
+	category.ID = 0
+	if err := s.validateCategoryName(0, category.Name); err != nil {
+		return err
+	}
+	if err := s.validateCategoryParent(category); err != nil {
+		return err
+	}
+	if err := s.repo.CreateCategory(category); err != nil {
+		if errors.Is(err, repository.ErrCategoryNameTaken) {
+			return middleware.NewAppError(409, fmt.Sprintf("Category name %s is already used", category.Name), err)
+		}
+		return err
+	}
+	return nil
 }
 
 func (s *productService) GetAllCategories() ([]entity.Category, error) {
 	return s.repo.GetAllCategories()
 }
 
+// GetCategoryTree - Semua kategori tersusun sebagai tree, diurutkan berdasarkan nama di setiap tingkat
+func (s *productService) GetCategoryTree() ([]entity.Category, error) {
+	categories, err := s.repo.GetAllCategories()
+	if err != nil {
+		return nil, err
+	}
+
+	exists := make(map[uint]bool, len(categories))
+	children := make(map[uint][]entity.Category)
+	for _, category := range categories {
+		exists[category.ID] = true
+	}
+	var roots []entity.Category
+	for _, category := range categories {
+		// Induk yang sudah tidak ada diperlakukan sebagai kategori tingkat atas
+		if category.ParentID == nil || !exists[*category.ParentID] {
+			roots = append(roots, category)
+			continue
+		}
+		children[*category.ParentID] = append(children[*category.ParentID], category)
+	}
+
+	var build func(nodes []entity.Category) []entity.Category
+	build = func(nodes []entity.Category) []entity.Category {
+		sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+		for i := range nodes {
+			nodes[i].Children = build(children[nodes[i].ID])
+		}
+		return nodes
+	}
+	tree := build(roots)
+	if tree == nil {
+		tree = []entity.Category{}
+	}
+	return tree, nil
+}
+
 func (s *productService) GetCategoryByID(id uint) (*entity.Category, error) {
 	return s.repo.GetCategoryByID(id)
 }
 
-func (s *productService) UpdateCategory(category *entity.Category) error {
-	return s.repo.UpdateCategory(category)
+// PatchCategory - Hanya field yang dikirim yang diubah, parent_id null menjadikan kategori tingkat atas
+func (s *productService) PatchCategory(id uint, request entity.CategoryPatchRequest, version uint) (*entity.Category, error) {
+	category, err := s.repo.GetCategoryByID(id)
+	if err != nil {
+		return nil, middleware.NewAppError(404, "Category not found", err)
+	}
+	if err := checkVersion(version, category.Version); err != nil {
+		return nil, err
+	}
+	if err := rejectNulls(fieldState("name", request.Name)); err != nil {
+		return nil, err
+	}
+
+	if request.Name.Set {
+		name := strings.TrimSpace(request.Name.Value)
+		if name == "" {
+			return nil, middleware.NewAppError(400, "Category name cannot be empty", nil)
+		}
+		if err := s.validateCategoryName(id, name); err != nil {
+			return nil, err
+		}
+		category.Name = name
+	}
+	if request.Description.Set {
+		category.Description = request.Description.Value
+	}
+	if request.ParentID.Set {
+		category.ParentID = nil
+		if request.ParentID.HasValue() {
+			category.ParentID = &request.ParentID.Value
+		}
+		if err := s.validateCategoryParent(category); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.UpdateCategory(category); err != nil {
+		// Nama bisa dipakai request lain di antara validateCategoryName dan update
+		if errors.Is(err, repository.ErrCategoryNameTaken) {
+			return nil, middleware.NewAppError(409, fmt.Sprintf("Category name %s is already used", category.Name), err)
+		}
+		return nil, versionConflict(err)
+	}
+	return category, nil
+}
+
+// ReplaceCategory - PUT membutuhkan name, description dan parent_id yang tidak dikirim dikosongkan
+func (s *productService) ReplaceCategory(id uint, request entity.CategoryPatchRequest, version uint) (*entity.Category, error) {
+	if err := requireFields(fieldState("name", request.Name)); err != nil {
+		return nil, err
+	}
+	clearIfMissing(&request.Description)
+	clearIfMissing(&request.ParentID)
+	return s.PatchCategory(id, request, version)
+}
+
+// validateCategoryName - Nama kategori unik di seluruh tree
+func (s *productService) validateCategoryName(id uint, name string) error {
+	categories, err := s.repo.GetAllCategories()
+	if err != nil {
+		return err
+	}
+	for _, category := range categories {
+		if category.ID != id && strings.EqualFold(category.Name, name) {
+			return middleware.NewAppError(409, fmt.Sprintf("Category name %s is already used", name), nil)
+		}
+	}
+	return nil
+}
+
+// validateCategoryParent - Induk harus ada dan tidak boleh kategori itu sendiri atau turunannya (mencegah siklus)
+func (s *productService) validateCategoryParent(category *entity.Category) error {
+	if category.ParentID == nil {
+		return nil
+	}
+	if *category.ParentID == 0 {
+		category.ParentID = nil
+		return nil
+	}
+	if _, err := s.repo.GetCategoryByID(*category.ParentID); err != nil {
+		return middleware.NewAppError(400, "Parent category not found", err)
+	}
+	if category.ID == 0 {
+		return nil
+	}
+
+	descendantIDs, err := s.repo.GetCategoryDescendantIDs(category.ID)
+	if err != nil {
+		return err
+	}
+	for _, id := range descendantIDs {
+		if id == *category.ParentID {
+			return middleware.NewAppError(400, "Category cannot be moved under itself or its sub-category", nil)
+		}
+	}
+	return nil
+}
+
+// DeleteCategory - Menghapus kategori sesuai policy (kosong, move atau deactivate) lalu mencatat hasilnya.
+// Version selain 0 (dari If-Match) harus sama dengan version kategori saat ini.
+func (s *productService) DeleteCategory(id uint, policy string, targetID uint, adminID uint, version uint) (*entity.CategoryDeleteResult, error) {
+	switch policy {
+	case "", entity.CategoryDeleteDeactivate:
+	case entity.CategoryDeleteMove:
+		if targetID == 0 || targetID == id {
+			return nil, middleware.NewAppError(400, "target_id must be another category when policy is move", nil)
+		}
+		if _, err := s.repo.GetCategoryByID(targetID); err != nil {
+			return nil, middleware.NewAppError(400, "Target category not found", err)
+		}
+	default:
+		return nil, middleware.NewAppError(400, "policy must be move or deactivate", nil)
+	}
+
+	result, err := s.repo.DeleteCategory(id, policy, targetID, version)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrVersionConflict):
+			return nil, middleware.NewAppError(412, "Category has been modified, reload and try again", err)
+		case errors.Is(err, repository.ErrCategoryNotFound):
+			return nil, middleware.NewAppError(404, "Category not found", err)
+		case errors.Is(err, repository.ErrCategoryInUse):
+			return nil, middleware.NewAppError(409, fmt.Sprintf(
+				"Category still has %d products and %d sub-categories; use policy=move&target_id=<id> or policy=deactivate",
+				result.ProductCount, result.SubcategoryCount), err)
+		}
+		return nil, err
+	}
+
+	details := fmt.Sprintf("Category #%d deleted (policy: %s): %d products moved, %d products deactivated, %d sub-categories reparented",
+		id, policyLabel(policy), result.ProductsMoved, result.ProductsDeactivated, result.SubcategoriesReparented)
+	if result.TargetCategoryID != nil {
+		details += fmt.Sprintf(", target category #%d", *result.TargetCategoryID)
+	}
+	middleware.Logger.Info("Service: Category deleted", zap.Uint("category_id", id), zap.String("policy", policy),
+		zap.Int64("products_moved", result.ProductsMoved), zap.Int64("products_deactivated", result.ProductsDeactivated))
+	if err := s.activityLogService.CreateActivityLog(adminID, "Delete Category", details); err != nil {
+		middleware.Logger.Warn("Failed to log category deletion", zap.Error(err))
+	}
+	return result, nil
 }
 
-func (s *productService) DeleteCategory(id uint) error {
-	return s.repo.DeleteCategory(id)
+func policyLabel(policy string) string {
+	if policy == "" {
+		return "none"
+	}
+	return policy
 }
 
 func (s *productService) CreateProduct(product *entity.Product) error {
+	if err := ValidateInputSchema(product.InputSchema); err != nil {
+		return err
+	}
+	if err := s.validateProductCode(product); err != nil {
+		return err
+	}
 	return s.repo.CreateProduct(product)
 }
 
-func (s *productService) GetAllProducts() ([]entity.Product, error) {
-	return s.repo.GetAllProducts()
+// SearchProducts - Daftar produk dengan harga dasar (administrator). Filter dinormalisasi in-place
+func (s *productService) SearchProducts(filter *entity.ProductFilter) ([]entity.Product, int64, error) {
+	if err := normalizeProductFilter(filter); err != nil {
+		return nil, 0, err
+	}
+	return s.searchProducts(*filter)
+}
+
+// SearchProductsForUser - Daftar produk aktif dengan harga sesuai price group user
+func (s *productService) SearchProductsForUser(userID uint, filter *entity.ProductFilter) ([]entity.Product, int64, error) {
+	if err := normalizeProductFilter(filter); err != nil {
+		return nil, 0, err
+	}
+	active := true
+	filter.IsActive = &active
+
+	products, total, err := s.searchProducts(*filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := s.priceGroupService.ApplyUserPrices(userID, products); err != nil {
+		return nil, 0, err
+	}
+	return products, total, nil
+}
+
+func (s *productService) searchProducts(filter entity.ProductFilter) ([]entity.Product, int64, error) {
+	products, total, err := s.repo.SearchProducts(filter)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidProductSort) {
+			return nil, 0, middleware.NewAppError(400, "Invalid sort field", err)
+		}
+		return nil, 0, err
+	}
+	if err := s.maintenanceService.AnnotateProducts(products); err != nil {
+		return nil, 0, err
+	}
+	return products, total, nil
+}
+
+// annotateProduct - Mengisi status ketersediaan satu produk
+func (s *productService) annotateProduct(product *entity.Product) error {
+	products := []entity.Product{*product}
+	if err := s.maintenanceService.AnnotateProducts(products); err != nil {
+		return err
+	}
+	*product = products[0]
+	return nil
+}
+
+// normalizeProductFilter - Nilai default pagination dan validasi rentang harga
+func normalizeProductFilter(filter *entity.ProductFilter) error {
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultProductPageSize
+	}
+	if filter.Limit > maxProductPageSize {
+		filter.Limit = maxProductPageSize
+	}
+	if (filter.MinPrice != nil && *filter.MinPrice < 0) || (filter.MaxPrice != nil && *filter.MaxPrice < 0) {
+		return middleware.NewAppError(400, "Price range cannot be negative", nil)
+	}
+	if filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice {
+		return middleware.NewAppError(400, "min_price cannot be greater than max_price", nil)
+	}
+	return nil
 }
 
 func (s *productService) GetProductByID(id uint) (*entity.Product, error) {
-	return s.repo.GetProductByID(id)
+	product, err := s.repo.GetProductByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.annotateProduct(product); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// GetProductByIDForUser - Detail produk dengan harga sesuai price group user
+func (s *productService) GetProductByIDForUser(id uint, userID uint) (*entity.Product, error) {
+	product, err := s.repo.GetProductByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !product.IsActive {
+		return nil, errors.New("product not found")
+	}
+	price, err := s.priceGroupService.ResolvePrice(userID, product)
+	if err != nil {
+		return nil, err
+	}
+	product.Price = price
+	if err := s.annotateProduct(product); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// GetProductByCode - Detail produk berdasarkan kode SKU (tidak peka huruf besar/kecil)
+func (s *productService) GetProductByCode(code string) (*entity.Product, error) {
+	product, err := s.repo.GetByCode(strings.ToUpper(strings.TrimSpace(code)))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.annotateProduct(product); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// GetProductByCodeForUser - Detail produk aktif berdasarkan kode dengan harga sesuai price group user
+func (s *productService) GetProductByCodeForUser(code string, userID uint) (*entity.Product, error) {
+	product, err := s.repo.GetByCode(strings.ToUpper(strings.TrimSpace(code)))
+	if err != nil {
+		return nil, err
+	}
+	return s.GetProductByIDForUser(product.ID, userID)
 }
 
-func (s *productService) UpdateProduct(product *entity.Product) error {
-	return s.repo.UpdateProduct(product)
+// PatchProduct - Hanya field yang dikirim yang diubah, null mengosongkan field opsional.
+// Version selain 0 (dari If-Match) harus sama dengan version produk saat ini.
+func (s *productService) PatchProduct(id uint, request entity.ProductPatchRequest, adminID uint, version uint) (*entity.Product, error) {
+	existing, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, middleware.NewAppError(404, "Product not found", err)
+	}
+	if err := checkVersion(version, existing.Version); err != nil {
+		return nil, err
+	}
+	oldPrice := existing.Price
+	if err := s.applyProductPatch(existing, request); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateProduct(existing); err != nil {
+		return nil, versionConflict(err)
+	}
+	s.priceScheduleService.RecordChange(existing.ID, oldPrice, existing.Price, entity.PriceSourceManual, adminID)
+	return s.GetProductByID(existing.ID)
+}
+
+// ReplaceProduct - PUT membutuhkan objek lengkap, field opsional yang tidak dikirim dikosongkan.
+// Stok tidak termasuk karena diubah lewat ledger inventori.
+func (s *productService) ReplaceProduct(id uint, request entity.ProductPatchRequest, adminID uint, version uint) (*entity.Product, error) {
+	if err := requireFields(
+		fieldState("name", request.Name),
+		fieldState("price", request.Price),
+		fieldState("category_id", request.CategoryID),
+		fieldState("is_voucher", request.IsVoucher),
+		fieldState("is_active", request.IsActive),
+	); err != nil {
+		return nil, err
+	}
+	clearIfMissing(&request.Code)
+	clearIfMissing(&request.Description)
+	clearIfMissing(&request.CostPrice)
+	clearIfMissing(&request.Supplier)
+	clearIfMissing(&request.Operator)
+	clearIfMissing(&request.InputSchema)
+	clearIfMissing(&request.LowStockThreshold)
+	return s.PatchProduct(id, request, adminID, version)
+}
+
+// applyProductPatch - Validasi per field lalu menerapkannya ke produk
+func (s *productService) applyProductPatch(product *entity.Product, request entity.ProductPatchRequest) error {
+	if err := rejectNulls(
+		fieldState("name", request.Name),
+		fieldState("price", request.Price),
+		fieldState("stock", request.Stock),
+		fieldState("category_id", request.CategoryID),
+		fieldState("is_voucher", request.IsVoucher),
+		fieldState("is_active", request.IsActive),
+	); err != nil {
+		return err
+	}
+
+	if request.Name.Set {
+		name := strings.TrimSpace(request.Name.Value)
+		if name == "" || len(name) > 150 {
+			return middleware.NewAppError(400, "name must be 1-150 characters", nil)
+		}
+		product.Name = name
+	}
+	if request.Code.Set {
+		product.Code = nil
+		if request.Code.HasValue() {
+			product.Code = &request.Code.Value
+		}
+		if err := s.validateProductCode(product); err != nil {
+			return err
+		}
+	}
+	if request.Description.Set {
+		product.Description = request.Description.Value
+	}
+	if request.Price.Set {
+		if request.Price.Value <= 0 {
+			return middleware.NewAppError(400, "price must be greater than 0", nil)
+		}
+		product.Price = request.Price.Value
+	}
+	if request.CostPrice.Set {
+		if request.CostPrice.Value < 0 {
+			return middleware.NewAppError(400, "cost_price cannot be negative", nil)
+		}
+		product.CostPrice = request.CostPrice.Value
+	}
+	if request.Supplier.Set {
+		if len(request.Supplier.Value) > 50 {
+			return middleware.NewAppError(400, "supplier must be at most 50 characters", nil)
+		}
+		product.Supplier = request.Supplier.Value
+	}
+	if request.Operator.Set {
+		if len(request.Operator.Value) > 50 {
+			return middleware.NewAppError(400, "operator must be at most 50 characters", nil)
+		}
+		product.Operator = request.Operator.Value
+	}
+	if request.CategoryID.Set && request.CategoryID.Value != product.CategoryID {
+		if _, err := s.repo.GetCategoryByID(request.CategoryID.Value); err != nil {
+			return middleware.NewAppError(400, "category_id must be an existing category", err)
+		}
+		product.CategoryID = request.CategoryID.Value
+	}
+	if request.IsVoucher.Set {
+		product.IsVoucher = request.IsVoucher.Value
+	}
+	if request.Stock.Set && request.Stock.Value != product.Stock {
+		// Stok produk voucher mengikuti jumlah kode yang tersisa
+		if product.IsVoucher {
+			return middleware.NewAppError(400, "stock of voucher products follows the imported codes", nil)
+		}
+		// Setiap perubahan stok harus tercatat di ledger inventori beserta alasannya
+		return middleware.NewAppError(400, "stock can only be changed through POST /api/products/:id/inventory with a reason", nil)
+	}
+	if request.LowStockThreshold.Set {
+		if request.LowStockThreshold.Value < 0 {
+			return middleware.NewAppError(400, "low_stock_threshold cannot be negative", nil)
+		}
+		product.LowStockThreshold = request.LowStockThreshold.Value
+	}
+	if request.InputSchema.Set {
+		if err := ValidateInputSchema(request.InputSchema.Value); err != nil {
+			return err
+		}
+		product.InputSchema = request.InputSchema.Value
+	}
+	if request.IsActive.Set {
+		product.IsActive = request.IsActive.Value
+	}
+	return nil
 }
 
-func (s *productService) DeleteProduct(id uint) error {
-	return s.repo.DeleteProduct(id)
+func (s *productService) DeleteProduct(id uint, version uint) error {
+	if err := s.repo.DeleteProduct(id, version); err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return middleware.NewAppError(412, "Product has been modified, reload and try again", err)
+		}
+		return err
+	}
+	return nil
 }
 
-func (s *productService) UpdateProductImage(productID string, imageURL string) error {
-	return s.repo.UpdateImage(productID, imageURL)
+// validateProductCode - Menormalkan kode ke huruf besar, kode kosong berarti produk tanpa kode
+func (s *productService) validateProductCode(product *entity.Product) error {
+	if product.Code == nil {
+		return nil
+	}
+	code := strings.ToUpper(strings.TrimSpace(*product.Code))
+	if code == "" {
+		product.Code = nil
+		return nil
+	}
+	if !productCodeRegex.MatchString(code) {
+		return middleware.NewAppError(400, "code must be 2-32 characters of A-Z, 0-9, '_' or '-'", nil)
+	}
+	if existing, err := s.repo.GetByCode(code); err == nil && existing.ID != product.ID {
+		return middleware.NewAppError(409, fmt.Sprintf("code %s is already used by another product", code), nil)
+	}
+	product.Code = &code
+	return nil
 }
@@ -0,0 +1,265 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"main.go/entity"
+	"main.go/middleware"
+	"main.go/repository"
+)
+
+// PromotionLine - Satu baris belanja yang dinilai kelayakannya untuk promo
+type PromotionLine struct {
+	ProductID   uint
+	CategoryIDs []uint // Kategori produk beserta induk-induknya
+	Subtotal    float64
+	Discount    float64 // Bagian diskon untuk baris ini, diisi oleh ReserveDiscount
+}
+
+type PromotionService interface {
+	CreatePromotion(promotion *entity.Promotion) error
+	GetAllPromotions() ([]entity.Promotion, error)
+	GetPromotionByID(id uint) (*entity.Promotion, error)
+	UpdatePromotion(promotion *entity.Promotion) error
+	DeletePromotion(id uint) error
+	GetUsages(promotionID uint) ([]entity.PromotionUsage, error)
+
+	ReserveDiscount(code string, userID uint, lines []PromotionLine) (*entity.PromotionUsage, error)
+	ConfirmUsage(usageID uint, transactionID uint) error
+	ReleaseUsage(usageID uint)
+
+	TransactionHook
+}
+
+type promotionService struct {
+	repo               repository.PromotionRepository
+	activityLogService ActivityLogService
+}
+
+func NewPromotionService(repo repository.PromotionRepository, activityLogService ActivityLogService) PromotionService {
+	return &promotionService{
+		repo:               repo,
+		activityLogService: activityLogService,
+	}
+}
+
+func (s *promotionService) CreatePromotion(promotion *entity.Promotion) error {
+	if err := validatePromotion(promotion); err != nil {
+		return err
+	}
+	if existing, _ := s.repo.GetByCode(promotion.Code); existing != nil {
+		return middleware.NewAppError(409, "Promo code already exists", nil)
+	}
+	promotion.UsedCount = 0
+	return s.repo.Create(promotion)
+}
+
+func (s *promotionService) GetAllPromotions() ([]entity.Promotion, error) {
+	return s.repo.GetAll()
+}
+
+func (s *promotionService) GetPromotionByID(id uint) (*entity.Promotion, error) {
+	promotion, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, middleware.NewAppError(404, err.Error(), err)
+	}
+	return promotion, nil
+}
+
+func (s *promotionService) UpdatePromotion(promotion *entity.Promotion) error {
+	if _, err := s.GetPromotionByID(promotion.ID); err != nil {
+		return err
+	}
+	if err := validatePromotion(promotion); err != nil {
+		return err
+	}
+	if existing, _ := s.repo.GetByCode(promotion.Code); existing != nil && existing.ID != promotion.ID {
+		return middleware.NewAppError(409, "Promo code already exists", nil)
+	}
+	return s.repo.Update(promotion)
+}
+
+func (s *promotionService) DeletePromotion(id uint) error {
+	if _, err := s.GetPromotionByID(id); err != nil {
+		return err
+	}
+	return s.repo.Delete(id)
+}
+
+func (s *promotionService) GetUsages(promotionID uint) ([]entity.PromotionUsage, error) {
+	return s.repo.GetUsages(promotionID)
+}
+
+// ReserveDiscount - Memvalidasi kode promo untuk keranjang dan memotong kuotanya.
+// Diskon yang didapat dibagi ke lines (field Discount) untuk perhitungan margin per item.
+// Reservasi harus dikonfirmasi (ConfirmUsage) atau dilepas (ReleaseUsage) oleh pemanggil.
+func (s *promotionService) ReserveDiscount(code string, userID uint, lines []PromotionLine) (*entity.PromotionUsage, error) {
+	promotion, err := s.repo.GetByCode(strings.ToUpper(strings.TrimSpace(code)))
+	if err != nil {
+		return nil, middleware.NewAppError(400, "Invalid promo code", err)
+	}
+
+	if promotion.UserID != nil && *promotion.UserID != userID {
+		return nil, middleware.NewAppError(400, "Invalid promo code", nil)
+	}
+
+	now := time.Now()
+	if !promotion.IsActive || now.Before(promotion.StartAt) || now.After(promotion.EndAt) {
+		return nil, middleware.NewAppError(400, "Promo code is not active", nil)
+	}
+
+	eligible := 0.0
+	for _, line := range lines {
+		if promotionCovers(promotion, line) {
+			eligible += line.Subtotal
+		}
+	}
+	if eligible == 0 {
+		return nil, middleware.NewAppError(400, "Promo code does not apply to these products", nil)
+	}
+	if eligible < promotion.MinPurchase {
+		return nil, middleware.NewAppError(400, fmt.Sprintf("Minimum purchase for this promo is %.0f", promotion.MinPurchase), nil)
+	}
+
+	usage, err := s.repo.ReserveUsage(promotion.ID, userID, calculateDiscount(promotion, eligible))
+	if err != nil {
+		if errors.Is(err, repository.ErrPromotionInactive) {
+			return nil, middleware.NewAppError(400, "Promo code is not active", err)
+		}
+		if errors.Is(err, repository.ErrPromotionQuotaExhausted) || errors.Is(err, repository.ErrPromotionUserLimitReached) {
+			return nil, middleware.NewAppError(409, err.Error(), err)
+		}
+		return nil, middleware.NewAppError(500, "Failed to apply promo code", err)
+	}
+
+	allocateDiscount(promotion, lines, usage.Discount)
+
+	middleware.Logger.Info("Service: Promo code reserved", zap.String("code", promotion.Code), zap.Uint("user_id", userID), zap.Float64("discount", usage.Discount))
+	return usage, nil
+}
+
+func (s *promotionService) ConfirmUsage(usageID uint, transactionID uint) error {
+	return s.repo.AttachTransaction(usageID, transactionID)
+}
+
+func (s *promotionService) ReleaseUsage(usageID uint) {
+	if err := s.repo.ReleaseUsage(usageID); err != nil {
+		middleware.Logger.Error("Service: Failed to release promo usage", zap.Uint("usage_id", usageID), zap.Error(err))
+	}
+}
+
+// OnTransactionSuccess - Tidak ada yang perlu dilakukan, diskon sudah tercatat saat checkout
+func (s *promotionService) OnTransactionSuccess(transaction *entity.Transaction) {}
+
+// OnTransactionReversed - Mengembalikan kuota promo saat transaksi gagal atau di-refund
+func (s *promotionService) OnTransactionReversed(transaction *entity.Transaction, previousStatus string) {
+	usage, err := s.repo.ReverseByTransaction(transaction.ID)
+	if err != nil {
+		middleware.Logger.Error("Service: Failed to reverse promo usage", zap.Uint("transaction_id", transaction.ID), zap.Error(err))
+		return
+	}
+	if usage == nil {
+		return
+	}
+
+	details := fmt.Sprintf("Transaction ID: %d, Promo Code: %s, Discount: %.2f, Status: %s", transaction.ID, transaction.PromoCode, usage.Discount, transaction.Status)
+	if err := s.activityLogService.CreateActivityLog(transaction.UserID, "Promo Reversed", details); err != nil {
+		middleware.Logger.Error("Failed to create activity log", zap.Error(err))
+	}
+}
+
+// promotionCovers - Produk/kategori kosong berarti promo berlaku untuk semua
+func promotionCovers(promotion *entity.Promotion, line PromotionLine) bool {
+	if len(promotion.ProductIDs) == 0 && len(promotion.CategoryIDs) == 0 {
+		return true
+	}
+	for _, id := range promotion.ProductIDs {
+		if id == line.ProductID {
+			return true
+		}
+	}
+	for _, id := range promotion.CategoryIDs {
+		if slices.Contains(line.CategoryIDs, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// allocateDiscount - Membagi diskon ke baris yang memenuhi syarat sebanding subtotalnya,
+// sisa pembulatan masuk ke baris terakhir agar jumlahnya tepat sama dengan diskon
+func allocateDiscount(promotion *entity.Promotion, lines []PromotionLine, discount float64) {
+	eligible := 0.0
+	last := -1
+	for i, line := range lines {
+		if promotionCovers(promotion, line) {
+			eligible += line.Subtotal
+			last = i
+		}
+	}
+	if last < 0 || eligible == 0 {
+		return
+	}
+
+	remaining := discount
+	for i := range lines {
+		if !promotionCovers(promotion, lines[i]) {
+			continue
+		}
+		if i == last {
+			lines[i].Discount = math.Round(remaining*100) / 100
+			break
+		}
+		lines[i].Discount = math.Round(discount*lines[i].Subtotal/eligible*100) / 100
+		remaining -= lines[i].Discount
+	}
+}
+
+// calculateDiscount - Diskon dibulatkan ke bawah dan tidak melebihi subtotal yang memenuhi syarat
+func calculateDiscount(promotion *entity.Promotion, eligible float64) float64 {
+	discount := promotion.DiscountValue
+	if promotion.DiscountType == entity.DiscountTypePercentage {
+		discount = eligible * promotion.DiscountValue / 100
+	}
+	if promotion.MaxDiscount > 0 && discount > promotion.MaxDiscount {
+		discount = promotion.MaxDiscount
+	}
+	if discount > eligible {
+		discount = eligible
+	}
+	return math.Floor(discount)
+}
+
+func validatePromotion(promotion *entity.Promotion) error {
+	promotion.Code = strings.ToUpper(strings.TrimSpace(promotion.Code))
+	if promotion.Code == "" || strings.TrimSpace(promotion.Name) == "" {
+		return middleware.NewAppError(400, "Promo code and name are required", nil)
+	}
+
+	switch promotion.DiscountType {
+	case entity.DiscountTypePercentage:
+		if promotion.DiscountValue <= 0 || promotion.DiscountValue > 100 {
+			return middleware.NewAppError(400, "Percentage discount must be between 0 and 100", nil)
+		}
+	case entity.DiscountTypeFixed:
+		if promotion.DiscountValue <= 0 {
+			return middleware.NewAppError(400, "Fixed discount must be greater than 0", nil)
+		}
+	default:
+		return middleware.NewAppError(400, "Discount type must be percentage or fixed", nil)
+	}
+
+	if promotion.StartAt.IsZero() || promotion.EndAt.IsZero() || !promotion.EndAt.After(promotion.StartAt) {
+		return middleware.NewAppError(400, "Promo validity window is invalid", nil)
+	}
+	if promotion.MaxDiscount < 0 || promotion.MinPurchase < 0 || promotion.GlobalLimit < 0 || promotion.PerUserLimit < 0 {
+		return middleware.NewAppError(400, "Promo limits cannot be negative", nil)
+	}
+	return nil
+}
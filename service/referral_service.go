@@ -0,0 +1,220 @@
+package service
+
+import (
+	"fmt"
+	"math"
+
+	"go.uber.org/zap"
+	"main.go/entity"
+	"main.go/middleware"
+	"main.go/repository"
+)
+
+// maxCommissionLevel - Batas kedalaman pohon referral yang dapat diberi komisi
+const maxCommissionLevel = 10
+
+type ReferralService interface {
+	GetReferralInfo(userID uint) (*entity.ReferralInfo, error)
+
+	GetLevels() ([]entity.CommissionLevel, error)
+	SetLevel(level *entity.CommissionLevel) error
+	DeleteLevel(level int) error
+
+	GetCommissionReport(filters entity.CommissionFilters) (*entity.CommissionReport, error)
+
+	TransactionHook
+}
+
+type referralService struct {
+	repo               repository.ReferralRepository
+	userRepo           repository.UserRepository
+	activityLogService ActivityLogService
+}
+
+func NewReferralService(repo repository.ReferralRepository, userRepo repository.UserRepository, activityLogService ActivityLogService) ReferralService {
+	return &referralService{
+		repo:               repo,
+		userRepo:           userRepo,
+		activityLogService: activityLogService,
+	}
+}
+
+// GetReferralInfo - Kode referral user (dibuatkan jika user lama belum punya), upline dan downline langsung
+func (s *referralService) GetReferralInfo(userID uint) (*entity.ReferralInfo, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, middleware.NewAppError(404, "User not found", err)
+	}
+
+	if user.ReferralCode == nil {
+		code, err := randomCode("", 8)
+		if err != nil {
+			return nil, middleware.NewAppError(500, "Failed to generate referral code", err)
+		}
+		if err := s.userRepo.SetReferralCode(userID, code); err != nil {
+			return nil, middleware.NewAppError(500, "Failed to generate referral code", err)
+		}
+		if user, err = s.userRepo.FindByID(userID); err != nil || user.ReferralCode == nil {
+			return nil, middleware.NewAppError(500, "Failed to generate referral code", err)
+		}
+	}
+
+	downlines, err := s.userRepo.GetDownlines(userID)
+	if err != nil {
+		return nil, middleware.NewAppError(500, "Failed to fetch downlines", err)
+	}
+
+	return &entity.ReferralInfo{
+		ReferralCode: *user.ReferralCode,
+		UplineID:     user.UplineID,
+		Downlines:    downlines,
+	}, nil
+}
+
+func (s *referralService) GetLevels() ([]entity.CommissionLevel, error) {
+	return s.repo.GetLevels()
+}
+
+func (s *referralService) SetLevel(level *entity.CommissionLevel) error {
+	if level.Level < 1 || level.Level > maxCommissionLevel {
+		return middleware.NewAppError(400, fmt.Sprintf("Level must be between 1 and %d", maxCommissionLevel), nil)
+	}
+	if level.Percentage <= 0 || level.Percentage > 100 {
+		return middleware.NewAppError(400, "Percentage must be between 0 and 100", nil)
+	}
+	return s.repo.UpsertLevel(level)
+}
+
+func (s *referralService) DeleteLevel(level int) error {
+	if err := s.repo.DeleteLevel(level); err != nil {
+		return middleware.NewAppError(404, err.Error(), err)
+	}
+	return nil
+}
+
+// GetCommissionReport - Rincian komisi dan total per level sesuai filter
+func (s *referralService) GetCommissionReport(filters entity.CommissionFilters) (*entity.CommissionReport, error) {
+	commissions, err := s.repo.GetCommissions(filters)
+	if err != nil {
+		return nil, middleware.NewAppError(500, "Failed to fetch commissions", err)
+	}
+	byLevel, err := s.repo.SummarizeByLevel(filters)
+	if err != nil {
+		return nil, middleware.NewAppError(500, "Failed to summarize commissions", err)
+	}
+
+	report := &entity.CommissionReport{
+		ByLevel:     byLevel,
+		Commissions: commissions,
+	}
+	for _, commission := range commissions {
+		switch commission.Status {
+		case entity.CommissionAccrued:
+			report.TotalAccrued += commission.Amount
+		case entity.CommissionReversed:
+			report.TotalReversed += commission.Amount
+		}
+	}
+	return report, nil
+}
+
+// OnTransactionSuccess - Mengkreditkan komisi ke setiap upline sesuai level yang dikonfigurasi
+func (s *referralService) OnTransactionSuccess(transaction *entity.Transaction) {
+	levels, err := s.repo.GetLevels()
+	if err != nil {
+		middleware.Logger.Error("Service: Failed to fetch commission levels", zap.Error(err))
+		return
+	}
+	if len(levels) == 0 || transaction.TotalPrice <= 0 {
+		return
+	}
+	percentages := make(map[int]float64)
+	deepest := 0
+	for _, level := range levels {
+		percentages[level.Level] = level.Percentage
+		if level.Level > deepest {
+			deepest = level.Level
+		}
+	}
+
+	buyer, err := s.userRepo.FindByID(transaction.UserID)
+	if err != nil {
+		return
+	}
+
+	visited := map[uint]bool{buyer.ID: true}
+	uplineID := buyer.UplineID
+	for depth := 1; depth <= deepest && uplineID != nil; depth++ {
+		if visited[*uplineID] {
+			middleware.Logger.Warn("Service: Referral cycle detected", zap.Uint("user_id", *uplineID))
+			return
+		}
+		visited[*uplineID] = true
+
+		upline, err := s.userRepo.FindByID(*uplineID)
+		if err != nil {
+			return
+		}
+
+		if percentage, ok := percentages[depth]; ok {
+			s.accrue(transaction, upline.ID, depth, percentage)
+		}
+		uplineID = upline.UplineID
+	}
+}
+
+// OnTransactionReversed - Membatalkan komisi dan menarik kembali saldo upline
+func (s *referralService) OnTransactionReversed(transaction *entity.Transaction, previousStatus string) {
+	reversed, err := s.repo.ReverseByTransaction(transaction.ID)
+	if err != nil {
+		middleware.Logger.Error("Service: Failed to reverse commissions", zap.Uint("transaction_id", transaction.ID), zap.Error(err))
+		return
+	}
+
+	for _, commission := range reversed {
+		if err := s.userRepo.AddBalance(commission.UplineID, -commission.Amount); err != nil {
+			middleware.Logger.Error("Service: Failed to deduct reversed commission", zap.Uint("upline_id", commission.UplineID), zap.Error(err))
+		}
+
+		details := fmt.Sprintf("Transaction ID: %d, From User ID: %d, Level: %d, Amount: %.2f", transaction.ID, commission.FromUserID, commission.Level, commission.Amount)
+		if err := s.activityLogService.CreateActivityLog(commission.UplineID, "Commission Reversed", details); err != nil {
+			middleware.Logger.Error("Failed to create activity log", zap.Error(err))
+		}
+	}
+}
+
+func (s *referralService) accrue(transaction *entity.Transaction, uplineID uint, level int, percentage float64) {
+	amount := math.Floor(transaction.TotalPrice*percentage) / 100
+	if amount <= 0 {
+		return
+	}
+
+	commission := entity.Commission{
+		UplineID:      uplineID,
+		FromUserID:    transaction.UserID,
+		TransactionID: transaction.ID,
+		Level:         level,
+		BaseAmount:    transaction.TotalPrice,
+		Percentage:    percentage,
+		Amount:        amount,
+		Status:        entity.CommissionAccrued,
+	}
+	created, err := s.repo.CreateCommission(&commission)
+	if err != nil {
+		middleware.Logger.Error("Service: Failed to record commission", zap.Uint("transaction_id", transaction.ID), zap.Error(err))
+		return
+	}
+	if !created {
+		return
+	}
+
+	if err := s.userRepo.AddBalance(uplineID, amount); err != nil {
+		middleware.Logger.Error("Service: Failed to credit commission", zap.Uint("upline_id", uplineID), zap.Error(err))
+		return
+	}
+
+	details := fmt.Sprintf("Transaction ID: %d, From User ID: %d, Level: %d, Amount: %.2f", transaction.ID, transaction.UserID, level, amount)
+	if err := s.activityLogService.CreateActivityLog(uplineID, "Commission Accrued", details); err != nil {
+		middleware.Logger.Error("Failed to create activity log", zap.Error(err))
+	}
+}
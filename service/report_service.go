@@ -1,27 +1,35 @@
 package service
 
 import (
+	"bytes"
 	"encoding/csv"
 	"fmt"
 	"github.com/jung-kurt/gofpdf"
+	"io"
 	"main.go/entity"
 	"main.go/repository"
-	"os"
 	"time"
 )
 
+// reportKeyPrefix - Prefix key storage untuk file laporan
+const reportKeyPrefix = "reports/"
+
 type ReportService interface {
 	GenerateReport(filters entity.ReportFilters, isAdmin bool, userID uint) ([]entity.TransactionSummary, error)
+	GetCategoryRollup(filters entity.ReportFilters, isAdmin bool, userID uint) ([]entity.CategoryRollup, error)
 	SaveReportToCSV(summaries []entity.TransactionSummary) (string, error)
 	SaveReportToPDF(summaries []entity.TransactionSummary) (string, error)
+	OpenReport(key string) (io.ReadCloser, error)
+	GetReportURL(key string, expiry time.Duration) (string, error)
 }
 
 type reportService struct {
 	reportRepo repository.ReportRepository
+	storage    repository.StorageRepository
 }
 
-func NewReportService(reportRepo repository.ReportRepository) ReportService {
-	return &reportService{reportRepo: reportRepo}
+func NewReportService(reportRepo repository.ReportRepository, storage repository.StorageRepository) ReportService {
+	return &reportService{reportRepo: reportRepo, storage: storage}
 }
 
 func (s *reportService) GenerateReport(filters entity.ReportFilters, isAdmin bool, userID uint) ([]entity.TransactionSummary, error) {
@@ -31,18 +39,20 @@ func (s *reportService) GenerateReport(filters entity.ReportFilters, isAdmin boo
 	return s.reportRepo.GetTransactionsSummary(filters, isAdmin, userID)
 }
 
-func (s *reportService) SaveReportToCSV(summaries []entity.TransactionSummary) (string, error) {
-	filePath := fmt.Sprintf("Reports/report_%d.csv", time.Now().Unix())
-	file, err := os.Create(filePath)
-	if err != nil {
-		return "", err
+// GetCategoryRollup - Ringkasan laporan per kategori tingkat atas
+func (s *reportService) GetCategoryRollup(filters entity.ReportFilters, isAdmin bool, userID uint) ([]entity.CategoryRollup, error) {
+	if !isAdmin {
+		filters.UserID = userID
 	}
-	defer file.Close()
+	return s.reportRepo.GetCategoryRollup(filters, isAdmin, userID)
+}
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+func (s *reportService) SaveReportToCSV(summaries []entity.TransactionSummary) (string, error) {
+	key := fmt.Sprintf("%sreport_%d.csv", reportKeyPrefix, time.Now().UnixNano())
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
 
-	headers := []string{"Transaction ID", "User ID", "User Name", "Product Name", "Category Name", "Quantity", "Total Price", "Transaction Date"}
+	headers := []string{"Transaction ID", "User ID", "User Name", "Product Name", "Category Name", "Quantity", "Total Price", "Margin", "Transaction Date"}
 	if err := writer.Write(headers); err != nil {
 		return "", err
 	}
@@ -56,6 +66,7 @@ func (s *reportService) SaveReportToCSV(summaries []entity.TransactionSummary) (
 			summary.CategoryName,
 			fmt.Sprintf("%d", summary.Quantity),
 			fmt.Sprintf("%.2f", summary.TotalPrice),
+			fmt.Sprintf("%.2f", summary.Margin),
 			summary.TransactionDate,
 		}
 		if err := writer.Write(row); err != nil {
@@ -63,37 +74,58 @@ func (s *reportService) SaveReportToCSV(summaries []entity.TransactionSummary) (
 		}
 	}
 
-	return filePath, nil
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return "", err
+	}
+	if err := s.storage.Put(key, buffer.Bytes(), repository.PutOptions{ContentType: "text/csv"}); err != nil {
+		return "", err
+	}
+	return key, nil
 }
 
 func (s *reportService) SaveReportToPDF(summaries []entity.TransactionSummary) (string, error) {
-	filePath := fmt.Sprintf("Reports/report_%d.pdf", time.Now().Unix())
+	key := fmt.Sprintf("%sreport_%d.pdf", reportKeyPrefix, time.Now().UnixNano())
 	pdf := gofpdf.New("P", "mm", "A4", "")
 	pdf.AddPage()
 	pdf.SetFont("Arial", "B", 12)
 	pdf.CellFormat(190, 10, "Transaction Report", "0", 1, "C", false, 0, "")
 
-	headers := []string{"Transaction ID", "User ID", "User Name", "Product Name", "Category Name", "Quantity", "Total Price", "Transaction Date"}
+	headers := []string{"Transaction ID", "User ID", "User Name", "Product Name", "Category Name", "Quantity", "Total Price", "Margin", "Transaction Date"}
 	for _, header := range headers {
-		pdf.CellFormat(24, 10, header, "1", 0, "C", false, 0, "")
+		pdf.CellFormat(21, 10, header, "1", 0, "C", false, 0, "")
 	}
 	pdf.Ln(-1)
 
 	for _, summary := range summaries {
-		pdf.CellFormat(24, 10, fmt.Sprintf("%d", summary.TransactionID), "1", 0, "C", false, 0, "")
-		pdf.CellFormat(24, 10, fmt.Sprintf("%d", summary.UserID), "1", 0, "C", false, 0, "")
-		pdf.CellFormat(24, 10, summary.UserName, "1", 0, "C", false, 0, "")
-		pdf.CellFormat(24, 10, summary.ProductName, "1", 0, "C", false, 0, "")
-		pdf.CellFormat(24, 10, summary.CategoryName, "1", 0, "C", false, 0, "")
-		pdf.CellFormat(24, 10, fmt.Sprintf("%d", summary.Quantity), "1", 0, "C", false, 0, "")
-		pdf.CellFormat(24, 10, fmt.Sprintf("%.2f", summary.TotalPrice), "1", 0, "C", false, 0, "")
-		pdf.CellFormat(24, 10, summary.TransactionDate, "1", 0, "C", false, 0, "")
+		pdf.CellFormat(21, 10, fmt.Sprintf("%d", summary.TransactionID), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(21, 10, fmt.Sprintf("%d", summary.UserID), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(21, 10, summary.UserName, "1", 0, "C", false, 0, "")
+		pdf.CellFormat(21, 10, summary.ProductName, "1", 0, "C", false, 0, "")
+		pdf.CellFormat(21, 10, summary.CategoryName, "1", 0, "C", false, 0, "")
+		pdf.CellFormat(21, 10, fmt.Sprintf("%d", summary.Quantity), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(21, 10, fmt.Sprintf("%.2f", summary.TotalPrice), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(21, 10, fmt.Sprintf("%.2f", summary.Margin), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(21, 10, summary.TransactionDate, "1", 0, "C", false, 0, "")
 		pdf.Ln(-1)
 	}
 
-	if err := pdf.OutputFileAndClose(filePath); err != nil {
+	var buffer bytes.Buffer
+	if err := pdf.Output(&buffer); err != nil {
+		return "", err
+	}
+	if err := s.storage.Put(key, buffer.Bytes(), repository.PutOptions{ContentType: "application/pdf"}); err != nil {
 		return "", err
 	}
+	return key, nil
+}
+
+// OpenReport - Membaca file laporan dari storage, pemanggil wajib menutup reader
+func (s *reportService) OpenReport(key string) (io.ReadCloser, error) {
+	return s.storage.Get(key)
+}
 
-	return filePath, nil
+// GetReportURL - URL unduhan laporan yang berlaku sementara
+func (s *reportService) GetReportURL(key string, expiry time.Duration) (string, error) {
+	return s.storage.SignedURL(key, expiry)
 }
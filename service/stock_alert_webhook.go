@@ -0,0 +1,49 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+	"main.go/entity"
+	"main.go/middleware"
+)
+
+// stockAlertWebhookTimeout - Batas waktu pengiriman satu alert ke webhook
+const stockAlertWebhookTimeout = 5 * time.Second
+
+type webhookStockAlertHook struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookStockAlertHook - Mengirim alert stok menipis sebagai JSON (POST) ke URL webhook
+func NewWebhookStockAlertHook(url string) StockAlertHook {
+	return &webhookStockAlertHook{
+		url:    url,
+		client: &http.Client{Timeout: stockAlertWebhookTimeout},
+	}
+}
+
+// OnLowStock - Dikirim di background agar mutasi stok (mis. checkout) tidak menunggu webhook
+func (h *webhookStockAlertHook) OnLowStock(alert entity.LowStockAlert) {
+	payload, err := json.Marshal(alert)
+	if err != nil {
+		middleware.Logger.Error("Failed to encode low stock alert", zap.Error(err))
+		return
+	}
+
+	go func() {
+		resp, err := h.client.Post(h.url, "application/json", bytes.NewReader(payload))
+		if err != nil {
+			middleware.Logger.Error("Failed to send low stock alert", zap.Uint("product_id", alert.ProductID), zap.Error(err))
+			return
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			middleware.Logger.Warn("Low stock webhook rejected alert", zap.Uint("product_id", alert.ProductID), zap.Int("status", resp.StatusCode))
+		}
+	}()
+}
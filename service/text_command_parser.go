@@ -0,0 +1,104 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"main.go/entity"
+)
+
+// commandPlaceholders - Pola regex untuk setiap placeholder yang didukung template perintah
+var commandPlaceholders = map[string]string{
+	"product":     `[A-Za-z0-9_-]+`,
+	"destination": `\+?[0-9]{6,20}`,
+	"pin":         `[0-9]{4,6}`,
+	"ref":         `[A-Za-z0-9_-]+`,
+	"qty":         `[1-9][0-9]{0,2}`,
+}
+
+var placeholderRegex = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+// defaultCommandTemplates - Dipakai selama admin belum mendefinisikan template sendiri.
+// Perintah berkata kunci diberi prioritas lebih tinggi agar tidak tertangkap format pembelian.
+var defaultCommandTemplates = []entity.CommandTemplate{
+	{Name: "Cek saldo", Action: entity.CommandBalance, Pattern: "SAL.{pin}", Priority: 30, IsActive: true},
+	{Name: "Cek harga", Action: entity.CommandPrice, Pattern: "HARGA.{product}", Priority: 30, IsActive: true},
+	{Name: "Cek status", Action: entity.CommandStatus, Pattern: "S.{ref}.{pin}", Priority: 30, IsActive: true},
+	{Name: "Pembelian dengan ref", Action: entity.CommandPurchase, Pattern: "{product}.{destination}.{pin}.{ref}", Priority: 20, IsActive: true},
+	{Name: "Pembelian", Action: entity.CommandPurchase, Pattern: "{product}.{destination}.{pin}", Priority: 10, IsActive: true},
+}
+
+// defaultReplyTemplates - Balasan bawaan; dapat ditimpa lewat ReplyTemplate
+var defaultReplyTemplates = map[string]string{
+	entity.ReplyPurchaseAccepted: "Trx #{trx_id} {product} ke {destination} sedang diproses. Harga Rp{price}.",
+	entity.ReplyBalance:          "Saldo Anda Rp{balance}.",
+	entity.ReplyPrice:            "Harga {product} ({name}): Rp{price}.",
+	entity.ReplyStatus:           "Trx #{trx_id} ke {destination}: {status}. SN: {serial}",
+	entity.ReplyError:            "GAGAL: {message}",
+	entity.ReplyUnknownCommand:   "Format salah. Contoh: TSEL10.081234567890.PIN",
+	entity.ReplyUnregistered:     "Nomor {sender} belum terdaftar.",
+}
+
+// compileCommandPattern - Mengubah template seperti "{product}.{destination}.{pin}" menjadi regex ber-anchor
+func compileCommandPattern(pattern string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteString(`(?i)^`)
+
+	seen := make(map[string]bool)
+	last := 0
+	for _, loc := range placeholderRegex.FindAllStringSubmatchIndex(pattern, -1) {
+		name := pattern[loc[2]:loc[3]]
+		expr, ok := commandPlaceholders[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown placeholder {%s}", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("placeholder {%s} is used more than once", name)
+		}
+		seen[name] = true
+
+		sb.WriteString(regexp.QuoteMeta(pattern[last:loc[0]]))
+		sb.WriteString(fmt.Sprintf("(?P<%s>%s)", name, expr))
+		last = loc[1]
+	}
+	sb.WriteString(regexp.QuoteMeta(pattern[last:]))
+	sb.WriteString(`$`)
+
+	return regexp.Compile(sb.String())
+}
+
+// parseCommand - Mencocokkan pesan dengan template (sudah terurut prioritas) dan mengembalikan nilai placeholder
+func parseCommand(templates []entity.CommandTemplate, message string) (*entity.CommandTemplate, map[string]string) {
+	message = strings.TrimSpace(message)
+	for i := range templates {
+		template := &templates[i]
+		if !template.IsActive {
+			continue
+		}
+		re, err := compileCommandPattern(template.Pattern)
+		if err != nil {
+			continue
+		}
+		match := re.FindStringSubmatch(message)
+		if match == nil {
+			continue
+		}
+
+		values := make(map[string]string)
+		for j, name := range re.SubexpNames() {
+			if name != "" {
+				values[name] = match[j]
+			}
+		}
+		return template, values
+	}
+	return nil, nil
+}
+
+// renderReply - Mengganti placeholder {key} dengan nilai; placeholder tanpa nilai dikosongkan
+func renderReply(template string, values map[string]string) string {
+	return placeholderRegex.ReplaceAllStringFunc(template, func(placeholder string) string {
+		return values[placeholder[1:len(placeholder)-1]]
+	})
+}
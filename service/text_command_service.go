@@ -0,0 +1,338 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+	"main.go/entity"
+	"main.go/middleware"
+	"main.go/repository"
+)
+
+type TextCommandService interface {
+	HandleMessage(message entity.GatewayMessage) *entity.GatewayReply
+
+	CreateCommand(command *entity.CommandTemplate) error
+	GetAllCommands() ([]entity.CommandTemplate, error)
+	UpdateCommand(command *entity.CommandTemplate) error
+	DeleteCommand(id uint) error
+
+	GetReplies() ([]entity.ReplyTemplate, error)
+	SetReply(reply *entity.ReplyTemplate) error
+}
+
+type textCommandService struct {
+	repo               repository.TextCommandRepository
+	userRepo           repository.UserRepository
+	productRepo        repository.ProductRepository
+	priceGroupService  PriceGroupService
+	transactionService TransactionsService
+	activityLogService ActivityLogService
+	pinVerifier        PinVerifier
+}
+
+func NewTextCommandService(repo repository.TextCommandRepository, userRepo repository.UserRepository, productRepo repository.ProductRepository, priceGroupService PriceGroupService, transactionService TransactionsService, activityLogService ActivityLogService, pinVerifier PinVerifier) TextCommandService {
+	return &textCommandService{
+		repo:               repo,
+		userRepo:           userRepo,
+		productRepo:        productRepo,
+		priceGroupService:  priceGroupService,
+		transactionService: transactionService,
+		activityLogService: activityLogService,
+		pinVerifier:        pinVerifier,
+	}
+}
+
+// HandleMessage - Mengurai pesan dari gateway, menjalankan aksinya dan menyusun teks balasan
+func (s *textCommandService) HandleMessage(message entity.GatewayMessage) *entity.GatewayReply {
+	replies := s.replyTemplates()
+	reply := &entity.GatewayReply{To: message.Sender}
+
+	user, err := s.userRepo.FindByPhoneNumber(strings.TrimSpace(message.Sender))
+	if err != nil || user == nil {
+		reply.Reply = renderReply(replies[entity.ReplyUnregistered], map[string]string{"sender": message.Sender})
+		return reply
+	}
+
+	templates, err := s.commandTemplates()
+	if err != nil {
+		middleware.Logger.Error("Service: Failed to load command templates", zap.Error(err))
+		reply.Reply = renderReply(replies[entity.ReplyError], map[string]string{"message": "sistem sedang gangguan"})
+		return reply
+	}
+
+	command, values := parseCommand(templates, message.Message)
+	if command == nil {
+		reply.Reply = renderReply(replies[entity.ReplyUnknownCommand], nil)
+		return reply
+	}
+	reply.Action = command.Action
+
+	key, result, err := s.dispatch(user, command.Action, values)
+	if err != nil {
+		middleware.Logger.Warn("Service: Text command failed", zap.Uint("user_id", user.ID), zap.String("action", command.Action), zap.Error(err))
+		reply.Reply = renderReply(replies[entity.ReplyError], map[string]string{"message": err.Error()})
+	} else {
+		reply.Reply = renderReply(replies[key], result)
+	}
+
+	details := fmt.Sprintf("Channel: %s, Action: %s, Template ID: %d", message.Channel, command.Action, command.ID)
+	if err := s.activityLogService.CreateActivityLog(user.ID, "Text Command", details); err != nil {
+		middleware.Logger.Error("Failed to create activity log", zap.Error(err))
+	}
+	return reply
+}
+
+// dispatch - Menjalankan aksi perintah dan mengembalikan kunci template balasan beserta nilainya
+func (s *textCommandService) dispatch(user *entity.User, action string, values map[string]string) (string, map[string]string, error) {
+	// Saldo dan status bersifat rahasia sehingga butuh PIN; pembelian diverifikasi di CreateTransaction
+	if action == entity.CommandBalance || action == entity.CommandStatus {
+		if err := s.pinVerifier.VerifyPin(user.ID, values["pin"]); err != nil {
+			return "", nil, err
+		}
+	}
+
+	switch action {
+	case entity.CommandBalance:
+		return entity.ReplyBalance, map[string]string{"balance": formatAmount(user.Balance)}, nil
+
+	case entity.CommandPrice:
+		product, err := s.resolveProduct(values["product"])
+		if err != nil {
+			return "", nil, err
+		}
+		price, err := s.priceGroupService.ResolvePrice(user.ID, product)
+		if err != nil {
+			return "", nil, err
+		}
+		return entity.ReplyPrice, map[string]string{
+			"product": values["product"],
+			"name":    product.Name,
+			"price":   formatAmount(price),
+			"stock":   strconv.Itoa(product.Stock),
+		}, nil
+
+	case entity.CommandStatus:
+		transaction, err := s.findTransaction(user.ID, values["ref"])
+		if err != nil {
+			return "", nil, err
+		}
+		return entity.ReplyStatus, transactionReplyValues(transaction, values), nil
+
+	case entity.CommandPurchase:
+		product, err := s.resolveProduct(values["product"])
+		if err != nil {
+			return "", nil, err
+		}
+		quantity := 1
+		if values["qty"] != "" {
+			quantity, err = strconv.Atoi(values["qty"])
+			if err != nil || quantity < 1 {
+				return "", nil, middleware.NewAppError(400, "jumlah harus minimal 1", err)
+			}
+		}
+
+		transaction, err := s.transactionService.CreateTransaction(&entity.TransactionRequest{
+			UserID:            user.ID,
+			DestinationNumber: values["destination"],
+			ReferenceID:       values["ref"],
+			Pin:               values["pin"],
+			Items: []entity.TransactionItemRequest{
+				{ProductID: product.ID, Quantity: quantity},
+			},
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		return entity.ReplyPurchaseAccepted, transactionReplyValues(transaction, values), nil
+	}
+
+	return "", nil, fmt.Errorf("unsupported action %q", action)
+}
+
+// resolveProduct - Produk dicari berdasarkan kode SKU, ID, atau nama tanpa spasi (tidak peka huruf besar/kecil)
+func (s *textCommandService) resolveProduct(identifier string) (*entity.Product, error) {
+	if product, err := s.productRepo.GetByCode(strings.ToUpper(identifier)); err == nil {
+		return product, nil
+	}
+	if id, err := strconv.Atoi(identifier); err == nil && id > 0 {
+		if product, err := s.productRepo.GetByID(uint(id)); err == nil {
+			return product, nil
+		}
+	}
+
+	if product, err := s.productRepo.GetByCompactName(identifier); err == nil {
+		return product, nil
+	}
+	return nil, fmt.Errorf("produk %s tidak ditemukan", identifier)
+}
+
+// findTransaction - Ref dapat berupa reference ID milik user atau nomor transaksi
+func (s *textCommandService) findTransaction(userID uint, ref string) (*entity.Transaction, error) {
+	if transaction, err := s.transactionService.GetTransactionByReference(userID, ref); err == nil {
+		return transaction, nil
+	}
+	if id, err := strconv.Atoi(ref); err == nil && id > 0 {
+		transaction, err := s.transactionService.GetTransactionByID(uint(id))
+		if err == nil && transaction.UserID == userID {
+			return transaction, nil
+		}
+	}
+	return nil, fmt.Errorf("transaksi %s tidak ditemukan", ref)
+}
+
+// commandTemplates - Template dari database, atau template bawaan jika belum ada yang didefinisikan
+func (s *textCommandService) commandTemplates() ([]entity.CommandTemplate, error) {
+	templates, err := s.repo.GetAllCommands()
+	if err != nil {
+		return nil, err
+	}
+	if len(templates) == 0 {
+		templates = append([]entity.CommandTemplate(nil), defaultCommandTemplates...)
+	}
+	sort.SliceStable(templates, func(i, j int) bool {
+		return templates[i].Priority > templates[j].Priority
+	})
+	return templates, nil
+}
+
+// replyTemplates - Template bawaan yang ditimpa template dari database
+func (s *textCommandService) replyTemplates() map[string]string {
+	replies := make(map[string]string, len(defaultReplyTemplates))
+	for key, template := range defaultReplyTemplates {
+		replies[key] = template
+	}
+	stored, err := s.repo.GetAllReplies()
+	if err != nil {
+		middleware.Logger.Error("Service: Failed to load reply templates", zap.Error(err))
+		return replies
+	}
+	for _, reply := range stored {
+		replies[reply.Key] = reply.Template
+	}
+	return replies
+}
+
+func (s *textCommandService) CreateCommand(command *entity.CommandTemplate) error {
+	if err := validateCommandTemplate(command); err != nil {
+		return err
+	}
+	return s.repo.CreateCommand(command)
+}
+
+func (s *textCommandService) GetAllCommands() ([]entity.CommandTemplate, error) {
+	return s.repo.GetAllCommands()
+}
+
+func (s *textCommandService) UpdateCommand(command *entity.CommandTemplate) error {
+	if _, err := s.repo.GetCommandByID(command.ID); err != nil {
+		return middleware.NewAppError(404, err.Error(), err)
+	}
+	if err := validateCommandTemplate(command); err != nil {
+		return err
+	}
+	return s.repo.UpdateCommand(command)
+}
+
+func (s *textCommandService) DeleteCommand(id uint) error {
+	if _, err := s.repo.GetCommandByID(id); err != nil {
+		return middleware.NewAppError(404, err.Error(), err)
+	}
+	return s.repo.DeleteCommand(id)
+}
+
+// GetReplies - Semua template balasan yang berlaku (bawaan + yang sudah diubah admin)
+func (s *textCommandService) GetReplies() ([]entity.ReplyTemplate, error) {
+	stored, err := s.repo.GetAllReplies()
+	if err != nil {
+		return nil, err
+	}
+	overridden := make(map[string]bool)
+	for _, reply := range stored {
+		overridden[reply.Key] = true
+	}
+	for key, template := range defaultReplyTemplates {
+		if !overridden[key] {
+			stored = append(stored, entity.ReplyTemplate{Key: key, Template: template})
+		}
+	}
+	sort.Slice(stored, func(i, j int) bool { return stored[i].Key < stored[j].Key })
+	return stored, nil
+}
+
+func (s *textCommandService) SetReply(reply *entity.ReplyTemplate) error {
+	if _, ok := defaultReplyTemplates[reply.Key]; !ok {
+		return middleware.NewAppError(400, fmt.Sprintf("Unknown reply template key %q", reply.Key), nil)
+	}
+	if strings.TrimSpace(reply.Template) == "" {
+		return middleware.NewAppError(400, "Reply template cannot be empty", nil)
+	}
+	return s.repo.UpsertReply(reply)
+}
+
+func validateCommandTemplate(command *entity.CommandTemplate) error {
+	command.Name = strings.TrimSpace(command.Name)
+	command.Pattern = strings.TrimSpace(command.Pattern)
+	if command.Name == "" || command.Pattern == "" {
+		return middleware.NewAppError(400, "Command name and pattern are required", nil)
+	}
+
+	if _, err := compileCommandPattern(command.Pattern); err != nil {
+		return middleware.NewAppError(400, "Invalid command pattern: "+err.Error(), err)
+	}
+
+	required := map[string][]string{
+		entity.CommandPurchase: {"product", "destination", "pin"},
+		entity.CommandBalance:  {"pin"},
+		entity.CommandPrice:    {"product"},
+		entity.CommandStatus:   {"ref", "pin"},
+	}
+	placeholders, ok := required[command.Action]
+	if !ok {
+		return middleware.NewAppError(400, "Action must be purchase, balance, price or status", nil)
+	}
+	for _, name := range placeholders {
+		if !strings.Contains(command.Pattern, "{"+name+"}") {
+			return middleware.NewAppError(400, fmt.Sprintf("Pattern for %s must contain {%s}", command.Action, name), nil)
+		}
+	}
+	return nil
+}
+
+func transactionReplyValues(transaction *entity.Transaction, values map[string]string) map[string]string {
+	serial := transaction.SerialNumber
+	if serial == "" {
+		serial = "-"
+	}
+	return map[string]string{
+		"trx_id":      strconv.FormatUint(uint64(transaction.ID), 10),
+		"ref":         values["ref"],
+		"product":     values["product"],
+		"destination": transaction.DestinationNumber,
+		"price":       formatAmount(transaction.TotalPrice),
+		"status":      strings.ToUpper(transaction.Status),
+		"serial":      serial,
+	}
+}
+
+// formatAmount - Format rupiah dengan pemisah ribuan titik, mis. 10.500
+func formatAmount(amount float64) string {
+	digits := strconv.FormatFloat(amount, 'f', 0, 64)
+	negative := strings.HasPrefix(digits, "-")
+	digits = strings.TrimPrefix(digits, "-")
+
+	var sb strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			sb.WriteByte('.')
+		}
+		sb.WriteRune(digit)
+	}
+	if negative {
+		return "-" + sb.String()
+	}
+	return sb.String()
+}
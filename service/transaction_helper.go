@@ -9,7 +9,13 @@ func ConvertToTransactionResponse(transaction *entity.Transaction) entity.Transa
 		UserID:            transaction.UserID,
 		DestinationNumber: transaction.DestinationNumber,
 		TotalPrice:        transaction.TotalPrice,
+		ReferenceID:       transaction.ReferenceID,
+		PromoCode:         transaction.PromoCode,
+		DiscountAmount:    transaction.DiscountAmount,
 		Status:            transaction.Status,
+		SerialNumber:      transaction.SerialNumber,
+		CustomerInputs:    transaction.CustomerInputs,
+		Version:           transaction.Version,
 		CreatedAt:         transaction.CreatedAt,
 		UpdatedAt:         transaction.UpdatedAt,
 		User: entity.UserSafeResponse{
@@ -37,6 +43,7 @@ func ConvertToTransactionItemResponse(item entity.TransactionItem) entity.Transa
 		Product: entity.ProductResponse{
 			ID:          item.Product.ID,
 			Name:        item.Product.Name,
+			Code:        item.Product.Code,
 			Description: item.Product.Description,
 			Price:       item.Product.Price,
 			Stock:       item.Product.Stock,
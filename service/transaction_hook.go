@@ -0,0 +1,33 @@
+package service
+
+import "main.go/entity"
+
+// TransactionHook - Titik ekstensi yang dipanggil saat status transaksi berpindah.
+// Dipakai subsistem lain (promo, loyalty, komisi) tanpa membuat TransactionsService bergantung pada mereka.
+type TransactionHook interface {
+	// OnTransactionSuccess - Transaksi berpindah ke status "success"
+	OnTransactionSuccess(transaction *entity.Transaction)
+	// OnTransactionReversed - Transaksi berpindah ke "failed" atau "refunded" dari status aktif
+	OnTransactionReversed(transaction *entity.Transaction, previousStatus string)
+}
+
+// isReversalStatus - Status akhir yang membatalkan efek transaksi
+func isReversalStatus(status string) bool {
+	return status == "failed" || status == "refunded"
+}
+
+// notifyStatusChange - Memanggil hook yang relevan sesuai perpindahan status
+func (s *transactionsService) notifyStatusChange(transaction *entity.Transaction, previousStatus string) {
+	if previousStatus == transaction.Status {
+		return
+	}
+
+	for _, hook := range s.hooks {
+		switch {
+		case transaction.Status == "success":
+			hook.OnTransactionSuccess(transaction)
+		case isReversalStatus(transaction.Status) && !isReversalStatus(previousStatus):
+			hook.OnTransactionReversed(transaction, previousStatus)
+		}
+	}
+}
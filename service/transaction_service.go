@@ -7,7 +7,9 @@ import (
 	"main.go/entity"
 	"main.go/middleware"
 	"main.go/repository"
+	"math"
 	"math/rand"
+	"strings"
 	"time"
 )
 
@@ -15,33 +17,70 @@ type TransactionsService interface {
 	CreateTransaction(transactionRequest *entity.TransactionRequest) (*entity.Transaction, error)
 	GetAllTransactions() ([]entity.Transaction, error)
 	GetTransactionByID(id uint) (*entity.Transaction, error)
+	GetTransactionByReference(userID uint, referenceID string) (*entity.Transaction, error)
 	GetAllTransactionsByUser(userID uint) ([]entity.Transaction, error)
-	UpdateTransactionStatus(id uint, status string) error
-	DeleteTransaction(id uint) error
+	UpdateTransactionStatus(id uint, status string, version uint) error
+	DeleteTransaction(id uint, version uint) error
+	RegisterHook(hook TransactionHook)
 }
 
 type transactionsService struct {
 	repository         repository.TransactionsRepository
 	productRepo        repository.ProductRepository
 	activityLogService ActivityLogService
+	voucherService     VoucherService
+	pricingService     PricingService
+	priceGroupService  PriceGroupService
+	promotionService   PromotionService
+	pinVerifier        PinVerifier
+	favoriteService    FavoriteService
+	maintenanceService MaintenanceService
+	inventoryService   InventoryService
+	hooks              []TransactionHook
 }
 
-func NewTransactionsService(repo repository.TransactionsRepository, productRepo repository.ProductRepository, activityLogService ActivityLogService) TransactionsService {
+func NewTransactionsService(repo repository.TransactionsRepository, productRepo repository.ProductRepository, activityLogService ActivityLogService, voucherService VoucherService, pricingService PricingService, priceGroupService PriceGroupService, promotionService PromotionService, pinVerifier PinVerifier, favoriteService FavoriteService, maintenanceService MaintenanceService, inventoryService InventoryService) TransactionsService {
 	return &transactionsService{
 		repository:         repo,
 		productRepo:        productRepo,
 		activityLogService: activityLogService,
+		voucherService:     voucherService,
+		pricingService:     pricingService,
+		priceGroupService:  priceGroupService,
+		promotionService:   promotionService,
+		pinVerifier:        pinVerifier,
+		favoriteService:    favoriteService,
+		maintenanceService: maintenanceService,
+		inventoryService:   inventoryService,
+		hooks:              []TransactionHook{promotionService, inventoryService},
 	}
 }
 
+// RegisterHook - Mendaftarkan subsistem yang perlu tahu perpindahan status transaksi
+func (s *transactionsService) RegisterHook(hook TransactionHook) {
+	s.hooks = append(s.hooks, hook)
+}
+
 // CreateTransaction - Membuat transaksi baru
 func (s *transactionsService) CreateTransaction(transactionRequest *entity.TransactionRequest) (*entity.Transaction, error) {
 	middleware.Logger.Info("Service: CreateTransaction called")
 
-	// Validasi Destination Number
-	if len(transactionRequest.DestinationNumber) < 11 || len(transactionRequest.DestinationNumber) > 12 {
-		middleware.Logger.Warn("Invalid destination number")
-		return nil, errors.New("invalid destination number")
+	// Setiap pembelian wajib dikonfirmasi dengan PIN transaksi
+	if err := s.pinVerifier.VerifyPin(transactionRequest.UserID, transactionRequest.Pin); err != nil {
+		middleware.Logger.Warn("PIN verification failed", zap.Uint("user_id", transactionRequest.UserID), zap.Error(err))
+		return nil, err
+	}
+
+	// Nomor tujuan dapat diambil dari favorit milik user
+	if transactionRequest.FavoriteID != 0 {
+		destination, err := s.favoriteService.ResolveDestination(transactionRequest.UserID, transactionRequest.FavoriteID)
+		if err != nil {
+			return nil, err
+		}
+		if transactionRequest.DestinationNumber != "" && transactionRequest.DestinationNumber != destination {
+			return nil, middleware.NewAppError(400, "destination_number does not match the selected favorite", nil)
+		}
+		transactionRequest.DestinationNumber = destination
 	}
 
 	// Proses transaksi
@@ -51,39 +90,184 @@ func (s *transactionsService) CreateTransaction(transactionRequest *entity.Trans
 		Status:            "pending",
 	}
 
+	// Reference ID milik klien (mis. reseller H2H) harus unik per user
+	if referenceID := strings.TrimSpace(transactionRequest.ReferenceID); referenceID != "" {
+		if len(referenceID) > 64 {
+			return nil, middleware.NewAppError(400, "reference_id must be at most 64 characters", nil)
+		}
+		if existing, _ := s.repository.GetByReference(transactionRequest.UserID, referenceID); existing != nil {
+			return nil, middleware.NewAppError(409, "reference_id has already been used", repository.ErrDuplicateReference)
+		}
+		transaction.ReferenceID = &referenceID
+	}
+
+	// Jumlah negatif akan menambah stok dan membuat total harga negatif
+	if len(transactionRequest.Items) == 0 {
+		return nil, middleware.NewAppError(400, "items cannot be empty", nil)
+	}
+	for _, item := range transactionRequest.Items {
+		if item.Quantity <= 0 {
+			return nil, middleware.NewAppError(400, "quantity must be greater than 0", nil)
+		}
+	}
+
 	// Hitung total harga berdasarkan produk di database
 	totalPrice := 0.0
+	needsDestination := false
+	var schemas [][]entity.InputField
+	var promotionLines []PromotionLine
+	stockQuantities := make(map[uint]int)
+	ancestors, err := s.productRepo.GetCategoryAncestorIDs()
+	if err != nil {
+		middleware.Logger.Error("Failed to fetch category tree", zap.Error(err))
+		return nil, err
+	}
 	for _, item := range transactionRequest.Items {
 		// Ambil harga produk dari database
-		product, err := s.productRepo.GetByID(item.ProductID)
+		product, err := s.resolveItemProduct(item)
 		if err != nil {
-			middleware.Logger.Error("Product not found", zap.Uint("product_id", item.ProductID))
-			return nil, errors.New("product not found")
+			return nil, err
+		}
+		if !product.IsActive {
+			middleware.Logger.Warn("Product is inactive", zap.Uint("product_id", product.ID))
+			return nil, middleware.NewAppError(400, fmt.Sprintf("%s is not available", product.Name), nil)
+		}
+
+		// Cutoff operator dan gangguan supplier dijadwalkan lewat maintenance window
+		if err := s.maintenanceService.CheckProduct(product); err != nil {
+			middleware.Logger.Warn("Product under maintenance", zap.Uint("product_id", product.ID), zap.Error(err))
+			return nil, err
+		}
+
+		// Stok produk voucher mengikuti jumlah kode yang tersisa, stok produk lain dikurangi setelah transaksi disimpan
+		if product.Stock < item.Quantity {
+			middleware.Logger.Warn("Product out of stock", zap.Uint("product_id", product.ID))
+			return nil, middleware.NewAppError(409, fmt.Sprintf("%s is out of stock", product.Name), nil)
+		}
+		if !product.IsVoucher {
+			stockQuantities[product.ID] += item.Quantity
+		}
+
+		// Produk tanpa InputSchema tetap memakai nomor tujuan seperti sebelumnya
+		if len(product.InputSchema) == 0 {
+			needsDestination = true
+		} else {
+			if err := validateCustomerInputs(product.InputSchema, transactionRequest.CustomerInputs); err != nil {
+				middleware.Logger.Warn("Invalid customer inputs", zap.Uint("product_id", product.ID), zap.Error(err))
+				return nil, err
+			}
+			schemas = append(schemas, product.InputSchema)
+		}
+
+		// Harga mengikuti price group user yang membeli
+		price, err := s.priceGroupService.ResolvePrice(transactionRequest.UserID, product)
+		if err != nil {
+			middleware.Logger.Error("Failed to resolve product price", zap.Uint("product_id", product.ID), zap.Error(err))
+			return nil, err
 		}
 
 		// Hitung total harga untuk item ini
-		itemTotalPrice := product.Price * float64(item.Quantity)
+		itemTotalPrice := price * float64(item.Quantity)
 
 		// Tambahkan ke total transaksi
 		totalPrice += itemTotalPrice
+		promotionLines = append(promotionLines, PromotionLine{
+			ProductID:   product.ID,
+			CategoryIDs: categoryChain(ancestors, product.CategoryID),
+			Subtotal:    itemTotalPrice,
+		})
+
+		// Margin hanya dihitung jika harga modal produk diketahui
+		costPrice := s.pricingService.EffectiveCost(product)
+		margin := 0.0
+		if costPrice > 0 {
+			margin = (price - costPrice) * float64(item.Quantity)
+		}
 
 		// Simpan item transaksi
 		transaction.Items = append(transaction.Items, entity.TransactionItem{
-			ProductID: item.ProductID,
+			ProductID: product.ID,
 			Quantity:  item.Quantity,
-			Price:     product.Price,
+			Price:     price,
+			CostPrice: costPrice,
+			Margin:    margin,
 		})
 	}
 
+	// Validasi Destination Number
+	if needsDestination || transactionRequest.DestinationNumber != "" {
+		if len(transactionRequest.DestinationNumber) < 11 || len(transactionRequest.DestinationNumber) > 12 {
+			middleware.Logger.Warn("Invalid destination number")
+			return nil, errors.New("invalid destination number")
+		}
+	}
+
+	// Simpan hanya input yang dideklarasikan oleh produk
+	customerInputs, err := collectCustomerInputs(schemas, transactionRequest.CustomerInputs)
+	if err != nil {
+		middleware.Logger.Warn("Invalid customer inputs", zap.Error(err))
+		return nil, err
+	}
+	transaction.CustomerInputs = customerInputs
+
+	// Terapkan kode promo (kuota dipotong sekarang, dilepas jika transaksi gagal disimpan)
+	var promoUsage *entity.PromotionUsage
+	if transactionRequest.PromoCode != "" {
+		promoUsage, err = s.promotionService.ReserveDiscount(transactionRequest.PromoCode, transactionRequest.UserID, promotionLines)
+		if err != nil {
+			middleware.Logger.Warn("Promo code rejected", zap.String("promo_code", transactionRequest.PromoCode), zap.Error(err))
+			return nil, err
+		}
+		transaction.PromoCode = strings.ToUpper(strings.TrimSpace(transactionRequest.PromoCode))
+		transaction.DiscountAmount = promoUsage.Discount
+
+		// Diskon mengurangi margin item yang mendapatkannya
+		for i, line := range promotionLines {
+			transaction.Items[i].Discount = line.Discount
+			if transaction.Items[i].CostPrice > 0 {
+				transaction.Items[i].Margin -= line.Discount
+			}
+		}
+	}
+
 	// Tetapkan total harga yang dihitung
-	transaction.TotalPrice = totalPrice
+	transaction.TotalPrice = totalPrice - transaction.DiscountAmount
 
 	// Simpan transaksi ke database
 	if err := s.repository.Create(transaction); err != nil {
 		middleware.Logger.Error("Failed to create transaction", zap.Error(err))
+		if promoUsage != nil {
+			s.promotionService.ReleaseUsage(promoUsage.ID)
+		}
+		if errors.Is(err, repository.ErrDuplicateReference) {
+			return nil, middleware.NewAppError(409, "reference_id has already been used", err)
+		}
 		return nil, err
 	}
 
+	if promoUsage != nil {
+		if err := s.promotionService.ConfirmUsage(promoUsage.ID, transaction.ID); err != nil {
+			middleware.Logger.Error("Failed to confirm promo usage", zap.Uint("transaction_id", transaction.ID), zap.Error(err))
+		}
+	}
+
+	// Stok dikurangi lewat ledger inventori; jika stok habis didahului transaksi lain, transaksi digagalkan
+	if err := s.inventoryService.RecordPurchase(transaction, stockQuantities); err != nil {
+		middleware.Logger.Warn("Failed to reserve stock", zap.Uint("transaction_id", transaction.ID), zap.Error(err))
+		transaction.Status = "failed"
+		if updateErr := s.repository.Update(transaction); updateErr != nil {
+			middleware.Logger.Error("Failed to mark transaction as failed", zap.Uint("transaction_id", transaction.ID), zap.Error(updateErr))
+		} else {
+			s.notifyStatusChange(transaction, "pending")
+		}
+		if errors.Is(err, repository.ErrInsufficientStock) {
+			return nil, middleware.NewAppError(409, "Insufficient stock for one or more products", err)
+		}
+		return nil, err
+	}
+
+	s.favoriteService.MarkUsed(transaction.UserID, transaction.DestinationNumber)
+
 	// Simulasi callback
 	go s.simulateCallback(transaction)
 
@@ -93,6 +277,15 @@ func (s *transactionsService) CreateTransaction(transactionRequest *entity.Trans
 func (s *transactionsService) simulateCallback(transaction *entity.Transaction) {
 	time.Sleep(2 * time.Second) // Simulasi waktu tunggu supplier
 
+	// Status bisa sudah diubah admin selama menunggu supplier, callback tidak boleh menimpanya
+	latest, err := s.repository.GetByID(transaction.ID)
+	if err != nil || latest.Status != transaction.Status {
+		middleware.Logger.Warn("Transaction changed before supplier callback, skipping update",
+			zap.Uint("transaction_id", transaction.ID))
+		return
+	}
+	transaction.Version = latest.Version
+
 	randomSerial := s.generateSerialNumber()
 	isFailed := false
 	var failReason string
@@ -103,13 +296,40 @@ func (s *transactionsService) simulateCallback(transaction *entity.Transaction)
 		calculatedTotal += item.Price * float64(item.Quantity)
 	}
 
-	// Validasi jika TotalPrice tidak sesuai dengan perhitungan
-	if calculatedTotal != transaction.TotalPrice {
+	// Validasi jika TotalPrice tidak sesuai dengan perhitungan (setelah diskon promo)
+	if math.Abs(calculatedTotal-transaction.DiscountAmount-transaction.TotalPrice) > 0.005 {
 		isFailed = true
 		failReason = "Total price mismatch"
 	}
 
+	// Produk voucher memakai kode dari inventori sebagai pengganti serial acak
+	if !isFailed {
+		voucherQuantities := make(map[uint]int)
+		for _, item := range transaction.Items {
+			product, err := s.productRepo.GetByID(item.ProductID)
+			if err != nil {
+				isFailed = true
+				failReason = "Product not found"
+				break
+			}
+			if product.IsVoucher {
+				voucherQuantities[item.ProductID] += item.Quantity
+			}
+		}
+
+		if !isFailed && len(voucherQuantities) > 0 {
+			codes, err := s.voucherService.AssignCodes(transaction, voucherQuantities)
+			if err != nil {
+				isFailed = true
+				failReason = err.Error()
+			} else {
+				randomSerial = strings.Join(codes, ",")
+			}
+		}
+	}
+
 	// Simulasi callback sukses/gagal
+	previousStatus := transaction.Status
 	if isFailed {
 		middleware.Logger.Warn("Transaction failed",
 			zap.Uint("transaction_id", transaction.ID),
@@ -128,7 +348,10 @@ func (s *transactionsService) simulateCallback(transaction *entity.Transaction)
 
 	// Update status transaksi di database
 	if err := s.repository.Update(transaction); err != nil {
+		// ErrVersionConflict berarti transaksi diubah request lain tepat sebelum update ini
 		middleware.Logger.Error("Failed to update transaction status", zap.Error(err))
+	} else {
+		s.notifyStatusChange(transaction, previousStatus)
 	}
 
 	// Log aktivitas callback
@@ -162,6 +385,15 @@ func (s *transactionsService) GetTransactionByID(id uint) (*entity.Transaction,
 	return transaction, nil
 }
 
+// GetTransactionByReference - Mendapatkan transaksi user berdasarkan reference ID
+func (s *transactionsService) GetTransactionByReference(userID uint, referenceID string) (*entity.Transaction, error) {
+	transaction, err := s.repository.GetByReference(userID, referenceID)
+	if err != nil {
+		return nil, middleware.NewAppError(404, "transaction not found", err)
+	}
+	return transaction, nil
+}
+
 // GetAllTransactionsByUser - Mendapatkan semua transaksi milik user
 func (s *transactionsService) GetAllTransactionsByUser(userID uint) ([]entity.Transaction, error) {
 	middleware.Logger.Info("Service: GetAllTransactionsByUser called", zap.Uint("user_id", userID))
@@ -190,15 +422,20 @@ func (s *transactionsService) GetAllTransactions() ([]entity.Transaction, error)
 	return transactions, nil
 }
 
-// UpdateTransactionStatus - Mengupdate status transaksi
-func (s *transactionsService) UpdateTransactionStatus(id uint, status string) error {
+// statusUpdateRetries - Jumlah percobaan ulang update status tanpa If-Match saat bentrok dengan update lain
+const statusUpdateRetries = 3
+
+// UpdateTransactionStatus - Mengupdate status transaksi. Version selain 0 (dari If-Match) harus sama
+// dengan version saat ini; tanpa version (mis. callback supplier) update diulang dengan data terbaru saat bentrok.
+func (s *transactionsService) UpdateTransactionStatus(id uint, status string, version uint) error {
 	middleware.Logger.Info("Service: UpdateTransactionStatus called", zap.Uint("transaction_id", id), zap.String("status", status))
 
 	validStatuses := map[string]bool{
-		"pending": true,
-		"process": true,
-		"failed":  true,
-		"success": true,
+		"pending":  true,
+		"process":  true,
+		"failed":   true,
+		"success":  true,
+		"refunded": true,
 	}
 
 	if !validStatuses[status] {
@@ -206,38 +443,83 @@ func (s *transactionsService) UpdateTransactionStatus(id uint, status string) er
 		return errors.New("invalid transaction status")
 	}
 
-	transaction, err := s.repository.GetByID(id)
-	if err != nil {
-		middleware.Logger.Error("Service: Transaction not found", zap.Uint("transaction_id", id), zap.Error(err))
-		return errors.New("transaction not found")
-	}
+	for attempt := 1; ; attempt++ {
+		transaction, err := s.repository.GetByID(id)
+		if err != nil {
+			middleware.Logger.Error("Service: Transaction not found", zap.Uint("transaction_id", id), zap.Error(err))
+			return middleware.NewAppError(404, "transaction not found", err)
+		}
+		if err := checkVersion(version, transaction.Version); err != nil {
+			return err
+		}
 
-	transaction.Status = status
+		previousStatus := transaction.Status
+		transaction.Status = status
 
-	if err := s.repository.Update(transaction); err != nil {
-		middleware.Logger.Error("Service: Failed to update transaction status", zap.Error(err))
-		return errors.New("failed to update transaction status")
-	}
+		err = s.repository.Update(transaction)
+		if errors.Is(err, repository.ErrVersionConflict) && version == 0 && attempt < statusUpdateRetries {
+			middleware.Logger.Warn("Service: Transaction modified concurrently, retrying status update",
+				zap.Uint("transaction_id", id), zap.Int("attempt", attempt))
+			continue
+		}
+		if err != nil {
+			middleware.Logger.Error("Service: Failed to update transaction status", zap.Error(err))
+			if errors.Is(err, repository.ErrVersionConflict) {
+				return versionConflict(err)
+			}
+			return errors.New("failed to update transaction status")
+		}
+		s.notifyStatusChange(transaction, previousStatus)
 
-	middleware.Logger.Info("Service: Transaction status updated successfully", zap.Uint("transaction_id", transaction.ID))
-	return nil
+		middleware.Logger.Info("Service: Transaction status updated successfully", zap.Uint("transaction_id", transaction.ID))
+		return nil
+	}
 }
 
-// DeleteTransaction - Menghapus transaksi berdasarkan ID
-func (s *transactionsService) DeleteTransaction(id uint) error {
+// DeleteTransaction - Menghapus transaksi berdasarkan ID, version selain 0 harus sama dengan version saat ini
+func (s *transactionsService) DeleteTransaction(id uint, version uint) error {
 	middleware.Logger.Info("Service: DeleteTransaction called", zap.Uint("transaction_id", id))
 
-	_, err := s.repository.GetByID(id)
+	transaction, err := s.repository.GetByID(id)
 	if err != nil {
 		middleware.Logger.Error("Service: Transaction not found for deletion", zap.Uint("transaction_id", id))
-		return errors.New("transaction not found")
+		return middleware.NewAppError(404, "transaction not found", err)
+	}
+	if err := checkVersion(version, transaction.Version); err != nil {
+		return err
 	}
 
-	if err := s.repository.Delete(id); err != nil {
+	if err := s.repository.Delete(id, version); err != nil {
 		middleware.Logger.Error("Service: Failed to delete transaction", zap.Error(err))
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return versionConflict(err)
+		}
 		return errors.New("failed to delete transaction")
 	}
 
 	middleware.Logger.Info("Service: Transaction deleted successfully", zap.Uint("transaction_id", id))
 	return nil
 }
+
+// resolveItemProduct - Item dapat merujuk produk lewat product_id atau product_code
+func (s *transactionsService) resolveItemProduct(item entity.TransactionItemRequest) (*entity.Product, error) {
+	code := strings.ToUpper(strings.TrimSpace(item.ProductCode))
+	if code == "" {
+		product, err := s.productRepo.GetByID(item.ProductID)
+		if err != nil {
+			middleware.Logger.Error("Product not found", zap.Uint("product_id", item.ProductID))
+			return nil, errors.New("product not found")
+		}
+		return product, nil
+	}
+
+	product, err := s.productRepo.GetByCode(code)
+	if err != nil {
+		middleware.Logger.Error("Product not found", zap.String("product_code", code))
+		return nil, middleware.NewAppError(404, fmt.Sprintf("product with code %s not found", code), err)
+	}
+	if item.ProductID != 0 && item.ProductID != product.ID {
+		return nil, middleware.NewAppError(400, "product_id does not match product_code", nil)
+	}
+	return product, nil
+}
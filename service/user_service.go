@@ -1,6 +1,8 @@
 package service
 
 import (
+	"errors"
+	"fmt"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 	"main.go/entity"
@@ -8,30 +10,48 @@ import (
 	"main.go/repository"
 	"os"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 	"go.uber.org/zap"
 )
 
+const (
+	// maxPinAttempts - Jumlah PIN salah berturut-turut sebelum PIN dikunci
+	maxPinAttempts = 5
+	// pinLockDuration - Lama PIN terkunci setelah terlalu banyak percobaan salah
+	pinLockDuration = 15 * time.Minute
+)
+
+var pinRegex = regexp.MustCompile(`^[0-9]{4,6}$`)
+
+// PinVerifier - Verifikasi PIN transaksi sebelum pembelian diproses
+type PinVerifier interface {
+	VerifyPin(userID uint, pin string) error
+}
+
 type UserService struct {
-	userRepo  repository.UserRepository
-	tokenRepo repository.TokenRepository
+	userRepo           repository.UserRepository
+	tokenRepo          repository.TokenRepository
+	activityLogService ActivityLogService
 }
 
-func NewUserService(userRepo repository.UserRepository, tokenRepo repository.TokenRepository) *UserService {
+func NewUserService(userRepo repository.UserRepository, tokenRepo repository.TokenRepository, activityLogService ActivityLogService) *UserService {
 	return &UserService{
-		userRepo:  userRepo,
-		tokenRepo: tokenRepo,
+		userRepo:           userRepo,
+		tokenRepo:          tokenRepo,
+		activityLogService: activityLogService,
 	}
 }
 
 type UserRegisterRequest struct {
-	FullName    string `json:"full_name"`
-	PhoneNumber string `json:"phone_number"`
-	Email       string `json:"email"`
-	Password    string `json:"password"`
-	Address     string `json:"address"`
+	FullName     string `json:"full_name"`
+	PhoneNumber  string `json:"phone_number"`
+	Email        string `json:"email"`
+	Password     string `json:"password"`
+	Address      string `json:"address"`
+	ReferralCode string `json:"referral_code"` // Opsional, kode referral upline
 }
 
 type UserLoginRequest struct {
@@ -39,10 +59,10 @@ type UserLoginRequest struct {
 	Password    string `json:"password"`
 }
 
-type UserUpdateRequest struct {
-	FullName string `json:"full_name"`
-	Email    string `json:"email"`
-	Address  string `json:"address"`
+type UserPinRequest struct {
+	Password string `json:"password"` // Dibutuhkan untuk set dan reset
+	OldPin   string `json:"old_pin"`  // Dibutuhkan untuk change
+	Pin      string `json:"pin" binding:"required"`
 }
 
 // ======================== REGISTER ==========================
@@ -65,11 +85,25 @@ func (s *UserService) RegisterUser(user UserRegisterRequest) error {
 		}
 	}
 
+	var uplineID *uint
+	if code := strings.ToUpper(strings.TrimSpace(user.ReferralCode)); code != "" {
+		upline, err := s.userRepo.FindByReferralCode(code)
+		if err != nil || upline == nil {
+			return middleware.NewAppError(400, "Invalid referral code", nil)
+		}
+		uplineID = &upline.ID
+	}
+
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
 	if err != nil {
 		return middleware.NewAppError(500, "Internal error while securing your account", err)
 	}
 
+	referralCode, err := s.uniqueReferralCode()
+	if err != nil {
+		return middleware.NewAppError(500, "Failed to generate referral code", err)
+	}
+
 	newUser := entity.User{
 		FullName:    user.FullName,
 		PhoneNumber: user.PhoneNumber,
@@ -77,7 +111,9 @@ func (s *UserService) RegisterUser(user UserRegisterRequest) error {
 		Password:    string(hashedPassword),
 		Address:     user.Address,
 		Role:        "user",
+		UplineID:    uplineID,
 	}
+	newUser.ReferralCode = &referralCode
 
 	if err := s.userRepo.Create(&newUser); err != nil {
 		return middleware.NewAppError(500, "Failed to create user", err)
@@ -87,6 +123,20 @@ func (s *UserService) RegisterUser(user UserRegisterRequest) error {
 	return nil
 }
 
+// uniqueReferralCode - Membuat kode referral yang belum dipakai user lain
+func (s *UserService) uniqueReferralCode() (string, error) {
+	for attempt := 0; attempt < 5; attempt++ {
+		code, err := randomCode("", 8)
+		if err != nil {
+			return "", err
+		}
+		if existing, _ := s.userRepo.FindByReferralCode(code); existing == nil {
+			return code, nil
+		}
+	}
+	return "", errors.New("failed to generate unique referral code")
+}
+
 // ======================== LOGIN ==========================
 func (s *UserService) LoginUser(user UserLoginRequest) (string, string, error) {
 	if user.PhoneNumber == "" || user.Password == "" {
@@ -146,35 +196,59 @@ func (s *UserService) RefreshAccessToken(refreshToken string) (string, error) {
 }
 
 // ======================== UPDATE ==========================
-func (s *UserService) UpdateUser(userID uint, updatedData UserUpdateRequest) error {
+// PatchUser - Hanya field profil yang dikirim yang diubah, null mengosongkan email/alamat
+func (s *UserService) PatchUser(userID uint, request entity.UserPatchRequest) (*entity.User, error) {
 	user, err := s.userRepo.FindByID(userID)
 	if err != nil || user == nil {
-		return middleware.NewAppError(404, "User not found", err)
+		return nil, middleware.NewAppError(404, "User not found", err)
+	}
+	if err := rejectNulls(fieldState("full_name", request.FullName)); err != nil {
+		return nil, err
 	}
 
-	if updatedData.FullName != "" {
-		user.FullName = updatedData.FullName
+	// Hanya kolom profil yang ditulis agar saldo dan PIN tidak tertimpa
+	fields := make(map[string]interface{})
+	if request.FullName.Set {
+		fullName := strings.TrimSpace(request.FullName.Value)
+		if fullName == "" {
+			return nil, middleware.NewAppError(400, "full_name cannot be empty", nil)
+		}
+		fields["full_name"] = fullName
 	}
-	if updatedData.Address != "" {
-		user.Address = updatedData.Address
+	if request.Address.Set {
+		fields["address"] = request.Address.Value
 	}
-	if updatedData.Email != "" {
-		if !isValidEmail(updatedData.Email) {
-			return middleware.NewAppError(400, "Invalid email format", nil)
+	if request.Email.Set {
+		email := strings.TrimSpace(request.Email.Value)
+		if email != "" {
+			if !isValidEmail(email) {
+				return nil, middleware.NewAppError(400, "Invalid email format", nil)
+			}
+			existing, _ := s.userRepo.FindByEmail(email)
+			if existing != nil && existing.ID != userID {
+				return nil, middleware.NewAppError(409, "Email already used by another account", nil)
+			}
 		}
-		existing, _ := s.userRepo.FindByEmail(updatedData.Email)
-		if existing != nil && existing.ID != userID {
-			return middleware.NewAppError(409, "Email already used by another account", nil)
-		}
-		user.Email = updatedData.Email
+		fields["email"] = email
 	}
 
-	if err := s.userRepo.Update(user); err != nil {
-		return middleware.NewAppError(500, "Failed to update user", err)
+	if len(fields) > 0 {
+		if err := s.userRepo.UpdateProfile(userID, fields); err != nil {
+			return nil, middleware.NewAppError(500, "Failed to update user", err)
+		}
+		middleware.Logger.Info("Service: User updated successfully", zap.Uint("user_id", userID))
 	}
+	return s.GetUserByID(userID)
+}
 
-	middleware.Logger.Info("Service: User updated successfully", zap.Uint("user_id", userID))
-	return nil
+// ReplaceUser - PUT membutuhkan full_name, email dan alamat yang tidak dikirim dikosongkan
+func (s *UserService) ReplaceUser(userID uint, request entity.UserPatchRequest) (*entity.User, error) {
+	if err := requireFields(fieldState("full_name", request.FullName)); err != nil {
+		return nil, err
+	}
+	clearIfMissing(&request.Email)
+	clearIfMissing(&request.Address)
+	return s.PatchUser(userID, request)
 }
 
 // ======================== GET BY ID ==========================
@@ -218,3 +292,145 @@ func (s *UserService) LogoutUser(refreshToken string) error {
 	}
 	return nil
 }
+
+// ======================== PIN ==========================
+// SetPin - Membuat PIN transaksi pertama kali, dikonfirmasi dengan password akun
+func (s *UserService) SetPin(userID uint, request UserPinRequest) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return middleware.NewAppError(404, "User not found", err)
+	}
+	if user.Pin != "" {
+		return middleware.NewAppError(409, "PIN is already set, use change or reset instead", nil)
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(request.Password)) != nil {
+		return middleware.NewAppError(401, "Invalid password", nil)
+	}
+	return s.storePin(user, request.Pin, "PIN Set")
+}
+
+// ChangePin - Mengganti PIN dengan memverifikasi PIN lama (ikut aturan lockout)
+func (s *UserService) ChangePin(userID uint, request UserPinRequest) error {
+	if err := s.VerifyPin(userID, request.OldPin); err != nil {
+		return err
+	}
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return middleware.NewAppError(404, "User not found", err)
+	}
+	return s.storePin(user, request.Pin, "PIN Changed")
+}
+
+// ResetPin - Mengganti PIN yang terlupa dengan password akun; sekaligus membuka kunci PIN
+func (s *UserService) ResetPin(userID uint, request UserPinRequest) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return middleware.NewAppError(404, "User not found", err)
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(request.Password)) != nil {
+		return middleware.NewAppError(401, "Invalid password", nil)
+	}
+	return s.storePin(user, request.Pin, "PIN Reset")
+}
+
+// ClearPin - Admin menghapus PIN user; user harus membuat PIN baru sebelum bertransaksi
+func (s *UserService) ClearPin(adminID uint, userID uint) error {
+	if _, err := s.userRepo.FindByID(userID); err != nil {
+		return middleware.NewAppError(404, "User not found", err)
+	}
+	if err := s.userRepo.UpdatePinState(userID, map[string]interface{}{
+		"pin":                 "",
+		"pin_failed_attempts": 0,
+		"pin_locked_until":    nil,
+	}); err != nil {
+		return middleware.NewAppError(500, "Failed to clear PIN", err)
+	}
+
+	details := fmt.Sprintf("User ID: %d", userID)
+	if err := s.activityLogService.CreateActivityLog(adminID, "PIN Cleared", details); err != nil {
+		middleware.Logger.Error("Failed to create activity log", zap.Error(err))
+	}
+	return nil
+}
+
+// VerifyPin - Memeriksa PIN transaksi; PIN dikunci setelah maxPinAttempts kali salah berturut-turut.
+// Percobaan dicatat sebelum PIN dicek sehingga tebakan paralel ikut terhitung.
+func (s *UserService) VerifyPin(userID uint, pin string) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return middleware.NewAppError(404, "User not found", err)
+	}
+	if user.Pin == "" {
+		return middleware.NewAppError(403, "Transaction PIN is not set, please create one first", nil)
+	}
+	if pin == "" {
+		return middleware.NewAppError(400, "PIN is required", nil)
+	}
+
+	attempts, err := s.userRepo.ReservePinAttempt(userID, maxPinAttempts)
+	if err != nil {
+		var locked *repository.PinLockedError
+		if errors.As(err, &locked) {
+			if locked.LockedUntil != nil {
+				return middleware.NewAppError(403, fmt.Sprintf("PIN is locked until %s", locked.LockedUntil.Format("15:04")), err)
+			}
+			return middleware.NewAppError(403, "PIN is locked, please try again later", err)
+		}
+		return middleware.NewAppError(500, "Failed to verify PIN", err)
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(user.Pin), []byte(pin)) == nil {
+		if err := s.userRepo.UpdatePinState(userID, map[string]interface{}{
+			"pin_failed_attempts": 0,
+			"pin_locked_until":    nil,
+		}); err != nil {
+			middleware.Logger.Error("Service: Failed to reset PIN attempts", zap.Uint("user_id", userID), zap.Error(err))
+		}
+		return nil
+	}
+
+	if attempts >= maxPinAttempts {
+		lockedUntil := time.Now().Add(pinLockDuration)
+		if err := s.userRepo.UpdatePinState(userID, map[string]interface{}{
+			"pin_failed_attempts": 0,
+			"pin_locked_until":    lockedUntil,
+		}); err != nil {
+			middleware.Logger.Error("Service: Failed to lock PIN", zap.Uint("user_id", userID), zap.Error(err))
+		}
+
+		details := fmt.Sprintf("User ID: %d, Failed Attempts: %d, Locked For: %s", userID, attempts, pinLockDuration)
+		if err := s.activityLogService.CreateActivityLog(userID, "PIN Locked", details); err != nil {
+			middleware.Logger.Error("Failed to create activity log", zap.Error(err))
+		}
+		middleware.Logger.Warn("Service: PIN locked", zap.Uint("user_id", userID))
+		return middleware.NewAppError(403, fmt.Sprintf("Too many wrong PIN attempts, PIN is locked for %d minutes", int(pinLockDuration.Minutes())), nil)
+	}
+	return middleware.NewAppError(401, fmt.Sprintf("Invalid PIN, %d attempts left", maxPinAttempts-attempts), nil)
+}
+
+func (s *UserService) storePin(user *entity.User, pin string, action string) error {
+	if !pinRegex.MatchString(pin) {
+		return middleware.NewAppError(400, "PIN must be 4 to 6 digits", nil)
+	}
+	if strings.Count(pin, pin[:1]) == len(pin) {
+		return middleware.NewAppError(400, "PIN cannot be a single repeated digit", nil)
+	}
+
+	hashedPin, err := bcrypt.GenerateFromPassword([]byte(pin), bcrypt.DefaultCost)
+	if err != nil {
+		return middleware.NewAppError(500, "Internal error while securing your PIN", err)
+	}
+	if err := s.userRepo.UpdatePinState(user.ID, map[string]interface{}{
+		"pin":                 string(hashedPin),
+		"pin_failed_attempts": 0,
+		"pin_locked_until":    nil,
+	}); err != nil {
+		return middleware.NewAppError(500, "Failed to store PIN", err)
+	}
+
+	if err := s.activityLogService.CreateActivityLog(user.ID, action, fmt.Sprintf("User ID: %d", user.ID)); err != nil {
+		middleware.Logger.Error("Failed to create activity log", zap.Error(err))
+	}
+	middleware.Logger.Info("Service: PIN updated", zap.Uint("user_id", user.ID), zap.String("action", action))
+	return nil
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"main.go/middleware"
+	"main.go/repository"
+)
+
+// checkVersion - Version yang diharapkan klien (If-Match, 0 = tidak dikirim) harus sama dengan version saat ini
+func checkVersion(expected uint, current uint) error {
+	if expected != 0 && expected != current {
+		return middleware.NewAppError(412, fmt.Sprintf("Resource has been modified (current version %d), reload and try again", current), repository.ErrVersionConflict)
+	}
+	return nil
+}
+
+// versionConflict - Konflik version dari repository (update bersamaan) dikembalikan sebagai 412
+func versionConflict(err error) error {
+	if errors.Is(err, repository.ErrVersionConflict) {
+		return middleware.NewAppError(412, "Resource was modified by another request, reload and try again", err)
+	}
+	return err
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"go.uber.org/zap"
+	"main.go/entity"
+	"main.go/middleware"
+	"main.go/repository"
+)
+
+type VoucherService interface {
+	ImportCodes(productID uint, adminID uint, batchName string, file io.Reader) (*entity.VoucherImportResult, error)
+	AssignCodes(transaction *entity.Transaction, quantities map[uint]int) ([]string, error)
+	GetBatches(productID uint) ([]entity.VoucherBatch, error)
+	GetAssignments(productID uint, userID uint) ([]entity.VoucherCode, error)
+	SyncStock(productID uint, movement entity.InventoryMovement) (int64, error)
+	MigrateCodes() (int, int, error)
+}
+
+type voucherService struct {
+	repo               repository.VoucherRepository
+	productRepo        repository.ProductRepository
+	inventoryService   InventoryService
+	activityLogService ActivityLogService
+}
+
+func NewVoucherService(repo repository.VoucherRepository, productRepo repository.ProductRepository, inventoryService InventoryService, activityLogService ActivityLogService) VoucherService {
+	return &voucherService{
+		repo:               repo,
+		productRepo:        productRepo,
+		inventoryService:   inventoryService,
+		activityLogService: activityLogService,
+	}
+}
+
+// ImportCodes - Mengimpor kode voucher dari CSV (kolom pertama = kode, header "code" opsional)
+func (s *voucherService) ImportCodes(productID uint, adminID uint, batchName string, file io.Reader) (*entity.VoucherImportResult, error) {
+	middleware.Logger.Info("Service: ImportCodes called", zap.Uint("product_id", productID))
+
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, middleware.NewAppError(404, "Product not found", err)
+	}
+	if !product.IsVoucher {
+		return nil, middleware.NewAppError(400, "Product is not a voucher product", nil)
+	}
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var codes []entity.VoucherCode
+	var hashes []string
+	seen := make(map[string]bool)
+	skipped := 0
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, middleware.NewAppError(400, fmt.Sprintf("Invalid CSV at line %d", line), err)
+		}
+		if len(record) == 0 {
+			continue
+		}
+
+		code := strings.TrimSpace(record[0])
+		if code == "" || (line == 1 && strings.EqualFold(code, "code")) {
+			continue
+		}
+
+		hash, err := hashSecret(code)
+		if err != nil {
+			return nil, middleware.NewAppError(500, "Failed to hash voucher code", err)
+		}
+		if seen[hash] {
+			skipped++
+			continue
+		}
+		seen[hash] = true
+
+		encrypted, err := encryptSecret(code)
+		if err != nil {
+			return nil, middleware.NewAppError(500, "Failed to encrypt voucher code", err)
+		}
+
+		codes = append(codes, entity.VoucherCode{
+			ProductID:   productID,
+			Code:        encrypted,
+			CodeHash:    hash,
+			HashVersion: entity.VoucherHashHMAC,
+			MaskedCode:  maskCode(code),
+			Status:      entity.VoucherCodeAvailable,
+		})
+		hashes = append(hashes, hash)
+	}
+
+	if len(codes) == 0 && skipped == 0 {
+		return nil, middleware.NewAppError(400, "No voucher codes found in file", nil)
+	}
+
+	// Lewati kode yang sudah pernah diimpor sebelumnya
+	existing, err := s.repo.ExistingHashes(hashes)
+	if err != nil {
+		return nil, middleware.NewAppError(500, "Failed to check existing voucher codes", err)
+	}
+	fresh := codes[:0]
+	for _, code := range codes {
+		if existing[code.CodeHash] {
+			skipped++
+			continue
+		}
+		fresh = append(fresh, code)
+	}
+
+	batch := entity.VoucherBatch{
+		ProductID:  productID,
+		Name:       batchName,
+		TotalCodes: len(fresh),
+		Duplicates: skipped,
+		ImportedBy: adminID,
+	}
+	imported, err := s.repo.CreateBatch(&batch, fresh)
+	if err != nil {
+		return nil, middleware.NewAppError(500, "Failed to store voucher codes", err)
+	}
+	skipped += len(fresh) - imported
+
+	available, err := s.SyncStock(productID, entity.InventoryMovement{
+		Type:      entity.InventoryImport,
+		Reason:    fmt.Sprintf("Voucher batch #%d imported", batch.ID),
+		CreatedBy: &adminID,
+	})
+	if err != nil {
+		return nil, middleware.NewAppError(500, "Failed to update product stock", err)
+	}
+
+	details := fmt.Sprintf("Batch ID: %d, Product ID: %d, Imported: %d, Skipped: %d", batch.ID, productID, imported, skipped)
+	if err := s.activityLogService.CreateActivityLog(adminID, "Voucher Codes Imported", details); err != nil {
+		middleware.Logger.Error("Failed to create activity log", zap.Error(err))
+	}
+
+	middleware.Logger.Info("Service: Voucher codes imported", zap.Uint("batch_id", batch.ID), zap.Int("imported", imported))
+	return &entity.VoucherImportResult{
+		Batch:     batch,
+		Imported:  imported,
+		Skipped:   skipped,
+		Available: available,
+	}, nil
+}
+
+// AssignCodes - Memberikan kode voucher ke transaksi dan mengembalikan kode plaintext untuk serial number
+func (s *voucherService) AssignCodes(transaction *entity.Transaction, quantities map[uint]int) ([]string, error) {
+	assigned, err := s.repo.AssignCodes(transaction.ID, transaction.UserID, quantities)
+	if err != nil {
+		if errors.Is(err, repository.ErrInsufficientVoucherCodes) {
+			return nil, errors.New("voucher codes out of stock")
+		}
+		return nil, err
+	}
+
+	var plainCodes []string
+	for productID, codes := range assigned {
+		for _, code := range codes {
+			plain, err := decryptSecret(code.Code)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt voucher code %d: %w", code.ID, err)
+			}
+			plainCodes = append(plainCodes, plain)
+
+			details := fmt.Sprintf("Transaction ID: %d, Product ID: %d, Voucher Code ID: %d, Code: %s", transaction.ID, productID, code.ID, code.MaskedCode)
+			if err := s.activityLogService.CreateActivityLog(transaction.UserID, "Voucher Assigned", details); err != nil {
+				middleware.Logger.Error("Failed to create activity log", zap.Error(err))
+			}
+		}
+
+		transactionID := transaction.ID
+		movement := entity.InventoryMovement{
+			Type:          entity.InventoryPurchase,
+			Reason:        fmt.Sprintf("Voucher codes assigned to transaction #%d", transaction.ID),
+			TransactionID: &transactionID,
+		}
+		if _, err := s.SyncStock(productID, movement); err != nil {
+			middleware.Logger.Error("Failed to sync voucher stock", zap.Uint("product_id", productID), zap.Error(err))
+		}
+	}
+
+	return plainCodes, nil
+}
+
+func (s *voucherService) GetBatches(productID uint) ([]entity.VoucherBatch, error) {
+	return s.repo.GetBatchesByProduct(productID)
+}
+
+func (s *voucherService) GetAssignments(productID uint, userID uint) ([]entity.VoucherCode, error) {
+	return s.repo.GetAssignments(productID, userID)
+}
+
+// SyncStock - Menyamakan stok produk dengan jumlah kode voucher yang belum terpakai,
+// selisihnya dicatat di ledger inventori dengan jenis dan alasan dari movement
+func (s *voucherService) SyncStock(productID uint, movement entity.InventoryMovement) (int64, error) {
+	available, err := s.repo.CountAvailable(productID)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.inventoryService.SetStock(productID, int(available), movement); err != nil {
+		return 0, err
+	}
+	return available, nil
+}
+
+// MigrateCodes - Migrasi sekali jalan untuk kode lama: CodeHash SHA-256 tanpa kunci diganti HMAC dan
+// MaskedCode dibuat ulang. Kode yang gagal didekripsi dicatat di log tanpa menghentikan proses.
+func (s *voucherService) MigrateCodes() (int, int, error) {
+	return s.repo.MigrateCodes(entity.VoucherHashHMAC, func(code entity.VoucherCode) (string, string, error) {
+		plain, err := decryptSecret(code.Code)
+		if err != nil {
+			middleware.Logger.Error("Service: Failed to decrypt voucher code for migration", zap.Uint("voucher_code_id", code.ID), zap.Error(err))
+			return "", "", err
+		}
+		hash, err := hashSecret(plain)
+		if err != nil {
+			middleware.Logger.Error("Service: Failed to hash voucher code for migration", zap.Uint("voucher_code_id", code.ID), zap.Error(err))
+			return "", "", err
+		}
+		return hash, maskCode(plain), nil
+	})
+}
+
+// maskCode - Menyamarkan kode agar aman ditampilkan di log dan audit trail: paling banyak 4 karakter
+// terakhir yang terlihat, kode 8 karakter atau kurang disamarkan seluruhnya
+func maskCode(code string) string {
+	if len(code) <= 8 {
+		return strings.Repeat("*", len(code))
+	}
+	return "****" + code[len(code)-4:]
+}